@@ -24,11 +24,35 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/.well-known/jwks.json": {
+            "get": {
+                "description": "Get public keys that verify JWT access tokens.\nOnly available when the server issues JWT access tokens.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Get JSON Web Key Set",
+                "operationId": "GetJWKS",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.JWKSet"
+                        }
+                    }
+                }
+            }
+        },
         "/auth": {
             "get": {
                 "security": [
                     {
                         "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
                     }
                 ],
                 "tags": [
@@ -42,8 +66,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.UserDTO"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/models.APIError"
                         }
@@ -51,92 +75,175 @@ const docTemplate = `{
                 }
             }
         },
-        "/users": {
+        "/auth/identities": {
             "get": {
-                "description": "Get all registered users.",
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get accounts of identity providers that log in as authenticated user.",
                 "tags": [
-                    "users"
+                    "auth"
                 ],
-                "summary": "Get all users",
-                "operationId": "GetAllUsers",
+                "summary": "Get identities",
+                "operationId": "GetIdentities",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/server.UserDTO"
+                                "$ref": "#/definitions/server.UserIdentityDTO"
                             }
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/models.APIError"
                         }
                     }
                 }
-            }
-        },
-        "/users/{id}": {
-            "get": {
-                "description": "Get user with matching ID.",
+            },
+            "post": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Link the identity of the one-time code the client was redirected to\nafter linking with a redirect_uri, proving it started the link with the PKCE verifier.",
+                "consumes": [
+                    "application/json"
+                ],
                 "tags": [
-                    "users"
+                    "auth"
                 ],
-                "summary": "Get user",
-                "operationId": "GetUser",
+                "summary": "Complete link with code",
+                "operationId": "LinkIdentityWithCode",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Code of link",
+                        "name": "link",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.LinkIdentityCodeDTO"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/server.UserDTO"
+                            "$ref": "#/definitions/server.UserIdentityDTO"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/server.UserIdentityDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/models.APIError"
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/auth/identities/{id}": {
+            "delete": {
                 "security": [
                     {
                         "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
                     }
                 ],
-                "description": "Update matching user with provided data.",
+                "description": "Unlink matching account of identity provider from authenticated user.\nThe last identity of a user without password can't be unlinked.",
                 "tags": [
-                    "users"
+                    "auth"
                 ],
-                "summary": "Update user",
-                "operationId": "UpdateUser",
+                "summary": "Unlink identity",
+                "operationId": "DeleteIdentity",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
+                        "description": "Identity ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": ""
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "Log in to local account, returning the access and refresh tokens of a new session.\nRestores the account if it was deleted and not purged yet.\nFailed attempts are limited for each email and each client.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Log in with password",
+                "operationId": "PasswordLogin",
+                "parameters": [
                     {
-                        "description": "User",
-                        "name": "user",
+                        "description": "Credentials",
+                        "name": "credentials",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/server.UpdateUserDTO"
+                            "$ref": "#/definitions/server.PasswordLoginDTO"
                         }
                     }
                 ],
@@ -144,7 +251,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/server.UserDTO"
+                            "$ref": "#/definitions/server.tokenResponse"
                         }
                     },
                     "400": {
@@ -152,115 +259,4412 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.APIError"
                         }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.APIError": {
-            "type": "object",
-            "properties": {
-                "code": {
-                    "type": "integer",
-                    "example": 400
-                },
-                "message": {
-                    "type": "string",
-                    "example": "status bad request"
-                }
-            }
         },
-        "server.UpdateUserDTO": {
-            "type": "object",
-            "properties": {
-                "calories": {
-                    "type": "integer"
-                },
-                "carbs": {
-                    "type": "integer"
-                },
-                "day": {
-                    "type": "integer"
-                },
-                "email": {
-                    "type": "string"
-                },
-                "fats": {
-                    "type": "integer"
-                },
-                "firstname": {
-                    "type": "string"
-                },
-                "lastname": {
-                    "type": "string"
-                },
-                "proteins": {
-                    "type": "integer"
-                },
-                "recipesAdded": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
+        "/auth/logout": {
+            "post": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "End the session that authenticated the request.",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Log out",
+                "operationId": "Logout",
+                "responses": {
+                    "204": {
+                        "description": ""
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
                     }
-                },
-                "userProfileEdited": {
-                    "type": "boolean"
-                },
-                "username": {
-                    "type": "string"
                 }
             }
         },
-        "server.UserDTO": {
-            "type": "object",
-            "properties": {
-                "calories": {
-                    "type": "integer"
-                },
-                "carbs": {
-                    "type": "integer"
-                },
-                "day": {
-                    "type": "integer"
-                },
-                "email": {
-                    "type": "string"
-                },
-                "fats": {
-                    "type": "integer"
-                },
-                "firstname": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "lastname": {
-                    "type": "string"
-                },
-                "proteins": {
-                    "type": "integer"
-                },
-                "recipesAdded": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
+        "/auth/password-reset": {
+            "post": {
+                "description": "Email a password reset token to the local account with email, if there is one.\nRequests are limited for each email.\nNot available on servers that can't send emails.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Request password reset",
+                "operationId": "RequestPasswordReset",
+                "parameters": [
+                    {
+                        "description": "Password reset request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.PasswordResetRequestDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
                     }
-                },
-                "userProfileEdited": {
-                    "type": "boolean"
-                },
-                "username": {
-                    "type": "string"
                 }
             }
-        }
-    },
-    "securityDefinitions": {
-        "AccessToken": {
-            "type": "apiKey",
-            "name": "AccessToken",
+        },
+        "/auth/password-reset/confirm": {
+            "post": {
+                "description": "Set a new password with a password reset token,\nending every session of the user.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Reset password",
+                "operationId": "ResetPassword",
+                "parameters": [
+                    {
+                        "description": "Password reset",
+                        "name": "reset",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.PasswordResetDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/register": {
+            "post": {
+                "description": "Register a user that logs in with email and password at /auth/login.\nUsers are Readers until they verify their email with the token emailed to it.\nEmails already registered get the same response, their owner is emailed instead,\nso emails can't be discovered.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Register local account",
+                "operationId": "Register",
+                "parameters": [
+                    {
+                        "description": "Account",
+                        "name": "account",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.RegisterDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get sessions of authenticated user, most recent first.",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Get sessions",
+                "operationId": "GetSessions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/server.SessionDTO"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/sessions/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "End matching session of authenticated user,\nrevoking its access and refresh tokens.",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Delete session",
+                "operationId": "DeleteSession",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": ""
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/token": {
+            "post": {
+                "description": "Exchange a refresh token for a new access token and refresh token\nof the same session. Each refresh token can only be used once,\nreusing one revokes its whole session.\nClients redirected after logging in exchange their one-time code\nfor the tokens of a new session, with the redirect URI and PKCE verifier they logged in with.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Issue tokens",
+                "operationId": "Token",
+                "parameters": [
+                    {
+                        "enum": [
+                            "refresh_token",
+                            "authorization_code"
+                        ],
+                        "type": "string",
+                        "description": "Grant type",
+                        "name": "grant_type",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Refresh token",
+                        "name": "refresh_token",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization code",
+                        "name": "code",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Redirect URI the code was issued to",
+                        "name": "redirect_uri",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "PKCE code verifier",
+                        "name": "code_verifier",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.tokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/verify-email": {
+            "post": {
+                "description": "Verify the email of a user with the token emailed to it on registration.\nUsers whose email is given Administrator role become administrators.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Verify email",
+                "operationId": "VerifyEmail",
+                "parameters": [
+                    {
+                        "description": "Email verification",
+                        "name": "verification",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.EmailVerificationDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/{provider}/callback": {
+            "get": {
+                "description": "Complete OAuth2 flow of identity provider.\nLogins return the access and refresh tokens of a new session of the user,\nlabeled with query parameter device or the user agent.\nLogins started with a redirect_uri redirect to it with a one-time code instead.\nRegisters users logging in for the first time,\nor links them to the user with the same verified email.\nRestores deleted users that weren't purged yet.\nIdentity links return the linked identity,\nor redirect to their redirect_uri with a one-time code exchanged at /auth/identities.\nRequires the cookie set by the browser that started the login or link,\nexcept for links started with a redirect_uri.",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Complete login",
+                "operationId": "Callback",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Identity provider, such as google",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "State of login",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization code",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Label of device logging in",
+                        "name": "device",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.tokenResponse"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/server.UserIdentityDTO"
+                        }
+                    },
+                    "302": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/{provider}/link": {
+            "post": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Start linking an account of identity provider to authenticated user,\nreturning the URL to send the user to. Its callback returns the linked identity.\nSets a cookie binding the link to the browser, which must send the user to the URL.\nClients that can't share cookies with the browser pass redirect_uri and an S256 code_challenge instead,\nthey're redirected back to it with a one-time code in query parameter code, exchanged at /auth/identities.",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Link identity",
+                "operationId": "LinkIdentity",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Identity provider, such as google",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "URI of client redirected to with the code",
+                        "name": "redirect_uri",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "State of client, returned along with the code",
+                        "name": "state",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "PKCE code challenge of client, required along with redirect_uri",
+                        "name": "code_challenge",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "S256"
+                        ],
+                        "type": "string",
+                        "description": "PKCE code challenge method",
+                        "name": "code_challenge_method",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.AuthorizationURLDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/{provider}/login": {
+            "get": {
+                "description": "Redirect to identity provider to log in, the entryway of its OAuth2 flow.\nClients passing redirect_uri are redirected back to it once logged in\nwith a one-time code in query parameter code, exchanged at /auth/token.\nRedirect URIs must be under an allowed custom scheme or web origin.\nRedirect URIs that aren't https origins require an S256 code_challenge.\nSets a cookie binding the login to the browser, required by its callback.",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Log in",
+                "operationId": "Login",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Identity provider, such as google",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "URI of client redirected to with the code",
+                        "name": "redirect_uri",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "State of client, returned along with the code",
+                        "name": "state",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "PKCE code challenge of client",
+                        "name": "code_challenge",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "S256"
+                        ],
+                        "type": "string",
+                        "description": "PKCE code challenge method",
+                        "name": "code_challenge_method",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Label of device logging in",
+                        "name": "device",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "307": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/foods": {
+            "get": {
+                "description": "Get foods whose name or brand contain query, ignoring case.\nFoods whose name starts with query are listed first.",
+                "tags": [
+                    "foods"
+                ],
+                "summary": "Search foods",
+                "operationId": "SearchFoods",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search query",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Maximum number of foods to return",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of foods to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.FoodsPageDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Register a new food in the catalog.\nRequires Writer or Administrator role.\nNutrients besides macronutrients are keyed by the keys listed in /nutrients.",
+                "tags": [
+                    "foods"
+                ],
+                "summary": "Create food",
+                "operationId": "CreateFood",
+                "parameters": [
+                    {
+                        "description": "Food",
+                        "name": "food",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.CreateFoodDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/server.FoodDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/foods/{id}": {
+            "get": {
+                "description": "Get food with matching ID.",
+                "tags": [
+                    "foods"
+                ],
+                "summary": "Get food",
+                "operationId": "GetFood",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Food ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.FoodDTO"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Update matching food with provided data.\nRequires Administrator role.",
+                "tags": [
+                    "foods"
+                ],
+                "summary": "Update food",
+                "operationId": "UpdateFood",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Food ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Food",
+                        "name": "food",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.UpdateFoodDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.FoodDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Delete matching food from the catalog.\nRequires Administrator role.",
+                "tags": [
+                    "foods"
+                ],
+                "summary": "Delete food",
+                "operationId": "DeleteFood",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Food ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": ""
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/nutrients": {
+            "get": {
+                "description": "Get nutrients tracked besides calories and macronutrients,\nwith their recommended daily values.",
+                "tags": [
+                    "nutrients"
+                ],
+                "summary": "Get nutrients",
+                "operationId": "GetNutrients",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/server.NutrientDTO"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Start tracking a new nutrient.\nRequires Administrator role.",
+                "tags": [
+                    "nutrients"
+                ],
+                "summary": "Create nutrient",
+                "operationId": "CreateNutrient",
+                "parameters": [
+                    {
+                        "description": "Nutrient",
+                        "name": "nutrient",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.CreateNutrientDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/server.NutrientDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/nutrients/{key}": {
+            "put": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Update matching nutrient with provided data.\nRequires Administrator role.",
+                "tags": [
+                    "nutrients"
+                ],
+                "summary": "Update nutrient",
+                "operationId": "UpdateNutrient",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Nutrient key",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Nutrient",
+                        "name": "nutrient",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.UpdateNutrientDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.NutrientDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes": {
+            "get": {
+                "description": "Get all registered recipes.",
+                "tags": [
+                    "recipes"
+                ],
+                "summary": "Get all recipes",
+                "operationId": "GetAllRecipes",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/server.RecipeDTO"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Register a new recipe authored by authenticated user.\nRequires Writer or Administrator role.",
+                "tags": [
+                    "recipes"
+                ],
+                "summary": "Create recipe",
+                "operationId": "CreateRecipe",
+                "parameters": [
+                    {
+                        "description": "Recipe",
+                        "name": "recipe",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.CreateRecipeDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/server.RecipeDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/{id}": {
+            "get": {
+                "description": "Get recipe with matching ID.",
+                "tags": [
+                    "recipes"
+                ],
+                "summary": "Get recipe",
+                "operationId": "GetRecipe",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Recipe ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.RecipeDTO"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Update matching recipe with provided data.\nOnly the author of a recipe or an administrator can update it.",
+                "tags": [
+                    "recipes"
+                ],
+                "summary": "Update recipe",
+                "operationId": "UpdateRecipe",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Recipe ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Recipe",
+                        "name": "recipe",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.UpdateRecipeDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.RecipeDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Delete matching recipe.\nOnly the author of a recipe or an administrator can delete it.",
+                "tags": [
+                    "recipes"
+                ],
+                "summary": "Delete recipe",
+                "operationId": "DeleteRecipe",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Recipe ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": ""
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get a page of registered users with their private fields.\nPages continue from the cursor of the previous one, also linked in the Link header.\nRequires Administrator role.",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get all users",
+                "operationId": "GetAllUsers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Prefix of username, ignoring case",
+                        "name": "username",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Prefix of email, ignoring case",
+                        "name": "email",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "Administrator",
+                            "Writer",
+                            "Reader"
+                        ],
+                        "type": "string",
+                        "description": "Role",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date or RFC 3339 time users were registered after",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "id",
+                            "-id",
+                            "username",
+                            "-username",
+                            "email",
+                            "-email",
+                            "created_at",
+                            "-created_at"
+                        ],
+                        "type": "string",
+                        "default": "id",
+                        "description": "Sort key, prefixed with - for descending order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Maximum number of users to return",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.UsersPageDTO"
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "URL of next page"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get user with matching ID.\nThe user itself and administrators get every field as UserDTO,\nother users get the fields its privacy settings show as PublicUserDTO.",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get user",
+                "operationId": "GetUser",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of user retrieved before, responds 304 if it's still current",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.UserDTO"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Tag of current version of user"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Replace the data of matching user with provided data.\nChanged goals are recorded as goals effective since today.",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update user",
+                "operationId": "UpdateUser",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of user retrieved, responds 412 if it changed since",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "User",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.UpdateUserDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.UserDTO"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Tag of updated version of user"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Delete authenticated user, ending all its sessions.\nIts data is purged after a grace period, logging in before restores it.",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Delete user",
+                "operationId": "DeleteUser",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of user retrieved, responds 412 if it changed since",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Update the fields of matching user present in provided JSON Merge Patch (RFC 7396),\nfields set to null are reset to their default value.\nChanged goals are recorded as goals effective since today.",
+                "consumes": [
+                    "application/merge-patch+json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Patch user",
+                "operationId": "PatchUser",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of user retrieved, responds 412 if it changed since",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Fields of user to update",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.UpdateUserDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.UserDTO"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Tag of updated version of user"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/diary/{date}": {
+            "get": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get diary entries of user in date, along with\ntheir nutrient totals compared against user's goals in effect that date\nand against daily values of other nutrients.",
+                "tags": [
+                    "diary"
+                ],
+                "summary": "Get diary day",
+                "operationId": "GetDiaryDay",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date formatted as YYYY-MM-DD",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of day retrieved before, responds 304 if it's still current",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.DiaryDayDTO"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Tag of current content of day"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Log a food or recipe eaten by user in date.\nFoods are measured in grams (g) or servings, recipes in servings.",
+                "tags": [
+                    "diary"
+                ],
+                "summary": "Create diary entry",
+                "operationId": "CreateDiaryEntry",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date formatted as YYYY-MM-DD",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Diary entry",
+                        "name": "entry",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.CreateDiaryEntryDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/server.DiaryEntryDTO"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Tag of version of entry"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/diary/{date}/week": {
+            "get": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get nutrient totals of user in the seven days ending in date,\nalong with the daily average of logged days and the nutrients\nwhose average intake is deficient or exceeds their limit.\nOnly foods hold nutrients besides calories and macronutrients.",
+                "tags": [
+                    "diary"
+                ],
+                "summary": "Get diary week",
+                "operationId": "GetDiaryWeek",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last date formatted as YYYY-MM-DD",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.DiaryWeekDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/diary/{date}/{entryId}": {
+            "put": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Update matching diary entry with provided data.",
+                "tags": [
+                    "diary"
+                ],
+                "summary": "Update diary entry",
+                "operationId": "UpdateDiaryEntry",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date formatted as YYYY-MM-DD",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Diary entry ID",
+                        "name": "entryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of entry retrieved, responds 412 if it changed since",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Diary entry",
+                        "name": "entry",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.UpdateDiaryEntryDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.DiaryEntryDTO"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Tag of updated version of entry"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Delete matching diary entry.",
+                "tags": [
+                    "diary"
+                ],
+                "summary": "Delete diary entry",
+                "operationId": "DeleteDiaryEntry",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date formatted as YYYY-MM-DD",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Diary entry ID",
+                        "name": "entryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of entry retrieved, responds 412 if it changed since",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": ""
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/export": {
+            "get": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Download a ZIP archive with all the data of authenticated user:\nprofile, identities, goals, diary, recipes, measurements and water,\neach one as JSON and as CSV.",
+                "produces": [
+                    "application/zip"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Export user data",
+                "operationId": "ExportUser",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/goals": {
+            "get": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get daily goals of user in effect at date.",
+                "tags": [
+                    "goals"
+                ],
+                "summary": "Get goal",
+                "operationId": "GetGoal",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date formatted as YYYY-MM-DD, defaults to today",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.GoalDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Set daily goals of user effective since provided date,\nreplacing goals set for that same date.\nDiary days before that date keep being evaluated against previous goals.",
+                "tags": [
+                    "goals"
+                ],
+                "summary": "Create goal",
+                "operationId": "CreateGoal",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Goal",
+                        "name": "goal",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.CreateGoalDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/server.GoalDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/goals/calculate": {
+            "post": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Calculate daily goals from user's profile using Mifflin-St Jeor\nor Harris-Benedict formula and a macro split preset or custom split.\nPresets are balanced, low-carb, high-protein and keto.\nGoals are saved as user's goals effective since today if save is true.",
+                "tags": [
+                    "goals"
+                ],
+                "summary": "Calculate goals",
+                "operationId": "CalculateGoals",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Calculation options",
+                        "name": "options",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.CalculateGoalsDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.CalculatedGoalsDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/goals/history": {
+            "get": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get every goal of user ordered by effective date.",
+                "tags": [
+                    "goals"
+                ],
+                "summary": "Get goals history",
+                "operationId": "GetGoalsHistory",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/server.GoalDTO"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/measurements": {
+            "get": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get measurements of user ordered by time.",
+                "tags": [
+                    "measurements"
+                ],
+                "summary": "Get measurements",
+                "operationId": "GetMeasurements",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First date, formatted as YYYY-MM-DD",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last date, formatted as YYYY-MM-DD",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/server.MeasurementDTO"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Log body measurements of user.",
+                "tags": [
+                    "measurements"
+                ],
+                "summary": "Create measurement",
+                "operationId": "CreateMeasurement",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Measurement",
+                        "name": "measurement",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.CreateMeasurementDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/server.MeasurementDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/measurements/trend": {
+            "get": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get weights of user smoothed with an exponential moving average,\nalong with their weekly rate of change over the last two weeks\nand whether it agrees with user's weight goal.",
+                "tags": [
+                    "measurements"
+                ],
+                "summary": "Get weight trend",
+                "operationId": "GetWeightTrend",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First date, formatted as YYYY-MM-DD",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last date, formatted as YYYY-MM-DD",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "default": 0.1,
+                        "description": "Smoothing factor between 0 and 1",
+                        "name": "alpha",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.WeightTrendDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/measurements/{measurementId}": {
+            "get": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get measurement of user with matching ID.",
+                "tags": [
+                    "measurements"
+                ],
+                "summary": "Get measurement",
+                "operationId": "GetMeasurement",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Measurement ID",
+                        "name": "measurementId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.MeasurementDTO"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Update matching measurement with provided data.",
+                "tags": [
+                    "measurements"
+                ],
+                "summary": "Update measurement",
+                "operationId": "UpdateMeasurement",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Measurement ID",
+                        "name": "measurementId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Measurement",
+                        "name": "measurement",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.UpdateMeasurementDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.MeasurementDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Delete matching measurement.",
+                "tags": [
+                    "measurements"
+                ],
+                "summary": "Delete measurement",
+                "operationId": "DeleteMeasurement",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Measurement ID",
+                        "name": "measurementId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": ""
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/merge": {
+            "post": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Merge the user authenticated by provided access token into matching user,\nmoving its diary entries, measurements, water entries, goals, recipes and identities.\nGoals of dates both users have keep those of matching user.\nThe merged user is deleted along with its sessions.",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Merge user",
+                "operationId": "MergeUser",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Access token of the user merged",
+                        "name": "merge",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.MergeUserDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.UserDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/role": {
+            "put": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Change role of matching user to Administrator, Writer or Reader.\nRequires Administrator role, administrators cannot change their own role.",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update user role",
+                "operationId": "UpdateUserRole",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Role",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.UpdateUserRoleDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.UserDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/water/{date}": {
+            "get": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get water logged by user in date, along with\nits total compared against user's water goal in effect that date.",
+                "tags": [
+                    "water"
+                ],
+                "summary": "Get water day",
+                "operationId": "GetWaterDay",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date formatted as YYYY-MM-DD",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.WaterDayDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Log water drunk by user in date.",
+                "tags": [
+                    "water"
+                ],
+                "summary": "Create water entry",
+                "operationId": "CreateWaterEntry",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date formatted as YYYY-MM-DD",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Water entry",
+                        "name": "entry",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.CreateWaterEntryDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/server.WaterEntryDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/water/{date}/{entryId}": {
+            "delete": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Delete matching water entry of user in date.",
+                "tags": [
+                    "water"
+                ],
+                "summary": "Delete water entry",
+                "operationId": "DeleteWaterEntry",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date formatted as YYYY-MM-DD",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Water entry ID",
+                        "name": "entryId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": ""
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIError"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.APIError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "One of the ErrorCode constants",
+                    "type": "string",
+                    "example": "validation_failed"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "invalid update user"
+                },
+                "errors": {
+                    "description": "Errors of invalid fields of request",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FieldError"
+                    }
+                },
+                "instance": {
+                    "description": "Path of request",
+                    "type": "string",
+                    "example": "/v1/users/1"
+                },
+                "requestId": {
+                    "type": "string",
+                    "example": "5d1b4f0e9c2a7d3e"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 400
+                },
+                "title": {
+                    "description": "Text of status",
+                    "type": "string",
+                    "example": "Bad Request"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "about:blank"
+                }
+            }
+        },
+        "models.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string",
+                    "example": "email"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "must be a valid email"
+                }
+            }
+        },
+        "server.AuthorizationURLDTO": {
+            "type": "object",
+            "properties": {
+                "url": {
+                    "description": "URL users are sent to for granting access",
+                    "type": "string"
+                }
+            }
+        },
+        "server.CalculateGoalsDTO": {
+            "type": "object",
+            "properties": {
+                "formula": {
+                    "description": "Defaults to mifflin-st-jeor",
+                    "type": "string",
+                    "enum": [
+                        "mifflin-st-jeor",
+                        "harris-benedict"
+                    ]
+                },
+                "preset": {
+                    "description": "Name of macro split preset, defaults to balanced",
+                    "type": "string"
+                },
+                "save": {
+                    "description": "Save calculated goals as user's goals",
+                    "type": "boolean"
+                },
+                "split": {
+                    "description": "Custom macro split, overrides preset",
+                    "$ref": "#/definitions/server.MacroSplit"
+                }
+            }
+        },
+        "server.CalculatedGoalsDTO": {
+            "type": "object",
+            "properties": {
+                "bmr": {
+                    "description": "Basal metabolic rate",
+                    "type": "number"
+                },
+                "calories": {
+                    "type": "integer"
+                },
+                "carbs": {
+                    "type": "integer"
+                },
+                "fats": {
+                    "type": "integer"
+                },
+                "formula": {
+                    "type": "string"
+                },
+                "proteins": {
+                    "type": "integer"
+                },
+                "saved": {
+                    "type": "boolean"
+                },
+                "split": {
+                    "$ref": "#/definitions/server.MacroSplit"
+                },
+                "tdee": {
+                    "description": "Total daily energy expenditure",
+                    "type": "number"
+                }
+            }
+        },
+        "server.CreateDiaryEntryDTO": {
+            "type": "object",
+            "required": [
+                "meal",
+                "quantity",
+                "unit"
+            ],
+            "properties": {
+                "foodId": {
+                    "type": "integer"
+                },
+                "meal": {
+                    "type": "string",
+                    "enum": [
+                        "breakfast",
+                        "lunch",
+                        "dinner",
+                        "snack"
+                    ]
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "recipeId": {
+                    "type": "integer"
+                },
+                "unit": {
+                    "type": "string",
+                    "enum": [
+                        "g",
+                        "servings"
+                    ]
+                }
+            }
+        },
+        "server.CreateFoodDTO": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "brand": {
+                    "type": "string"
+                },
+                "calories": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "carbs": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "fats": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "name": {
+                    "type": "string"
+                },
+                "nutrients": {
+                    "description": "Per 100 g by nutrient key, in unit of nutrient",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                },
+                "proteins": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "servingSize": {
+                    "type": "number",
+                    "minimum": 0
+                }
+            }
+        },
+        "server.CreateGoalDTO": {
+            "type": "object",
+            "properties": {
+                "calories": {
+                    "type": "integer",
+                    "maximum": 20000
+                },
+                "carbs": {
+                    "description": "In grams",
+                    "type": "integer",
+                    "maximum": 2000
+                },
+                "effectiveDate": {
+                    "description": "Formatted as YYYY-MM-DD, defaults to today",
+                    "type": "string"
+                },
+                "fats": {
+                    "description": "In grams",
+                    "type": "integer",
+                    "maximum": 2000
+                },
+                "proteins": {
+                    "description": "In grams",
+                    "type": "integer",
+                    "maximum": 2000
+                },
+                "water": {
+                    "description": "In milliliters",
+                    "type": "integer",
+                    "maximum": 20000
+                }
+            }
+        },
+        "server.CreateMeasurementDTO": {
+            "type": "object",
+            "properties": {
+                "bodyFat": {
+                    "type": "number",
+                    "maximum": 100,
+                    "minimum": 0
+                },
+                "chest": {
+                    "type": "number"
+                },
+                "hips": {
+                    "type": "number"
+                },
+                "neck": {
+                    "type": "number"
+                },
+                "takenAt": {
+                    "description": "Defaults to now",
+                    "type": "string"
+                },
+                "waist": {
+                    "type": "number"
+                },
+                "weight": {
+                    "type": "number"
+                }
+            }
+        },
+        "server.CreateNutrientDTO": {
+            "type": "object",
+            "required": [
+                "key",
+                "name",
+                "unit"
+            ],
+            "properties": {
+                "dailyValue": {
+                    "type": "number"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "limit": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "unit": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.CreateRecipeDTO": {
+            "type": "object",
+            "required": [
+                "name",
+                "servings"
+            ],
+            "properties": {
+                "calories": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "carbs": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "fats": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "ingredients": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.RecipeIngredientDTO"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "proteins": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "servings": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "server.CreateWaterEntryDTO": {
+            "type": "object",
+            "required": [
+                "amount"
+            ],
+            "properties": {
+                "amount": {
+                    "description": "In milliliters",
+                    "type": "integer"
+                },
+                "loggedAt": {
+                    "description": "Defaults to now",
+                    "type": "string"
+                }
+            }
+        },
+        "server.DiaryDayDTO": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.DiaryEntryDTO"
+                    }
+                },
+                "goals": {
+                    "$ref": "#/definitions/server.NutrientsDTO"
+                },
+                "micronutrients": {
+                    "description": "Totals of other nutrients compared against their daily values",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.NutrientIntakeDTO"
+                    }
+                },
+                "remaining": {
+                    "description": "Negative when goals are exceeded",
+                    "$ref": "#/definitions/server.NutrientsDTO"
+                },
+                "totals": {
+                    "$ref": "#/definitions/server.NutrientsDTO"
+                }
+            }
+        },
+        "server.DiaryEntryDTO": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "foodId": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "meal": {
+                    "type": "string"
+                },
+                "micronutrients": {
+                    "description": "Other nutrients by nutrient key, only known for foods",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                },
+                "name": {
+                    "description": "Name of food or recipe",
+                    "type": "string"
+                },
+                "nutrients": {
+                    "$ref": "#/definitions/server.NutrientsDTO"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "recipeId": {
+                    "type": "integer"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Sent quoted in If-Match to update the entry only if it's unchanged",
+                    "type": "integer"
+                }
+            }
+        },
+        "server.DiarySummaryDayDTO": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "goals": {
+                    "$ref": "#/definitions/server.NutrientsDTO"
+                },
+                "logged": {
+                    "description": "Whether user logged any entry that date",
+                    "type": "boolean"
+                },
+                "totals": {
+                    "$ref": "#/definitions/server.NutrientsDTO"
+                }
+            }
+        },
+        "server.DiaryWeekDTO": {
+            "type": "object",
+            "properties": {
+                "average": {
+                    "description": "Daily average of logged days",
+                    "$ref": "#/definitions/server.NutrientsDTO"
+                },
+                "days": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.DiarySummaryDayDTO"
+                    }
+                },
+                "deficiencies": {
+                    "description": "Keys of nutrients below DeficiencyThreshold percent of daily value",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "excesses": {
+                    "description": "Keys of nutrients above their daily value upper limit",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "from": {
+                    "type": "string"
+                },
+                "loggedDays": {
+                    "type": "integer"
+                },
+                "micronutrients": {
+                    "description": "Daily average of other nutrients in logged days compared against their daily values",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.NutrientIntakeDTO"
+                    }
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.EmailVerificationDTO": {
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.FoodDTO": {
+            "type": "object",
+            "properties": {
+                "brand": {
+                    "type": "string"
+                },
+                "calories": {
+                    "description": "Per 100 g",
+                    "type": "number"
+                },
+                "carbs": {
+                    "description": "Per 100 g",
+                    "type": "number"
+                },
+                "fats": {
+                    "description": "Per 100 g",
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "nutrients": {
+                    "description": "Per 100 g by nutrient key, in unit of nutrient",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                },
+                "proteins": {
+                    "description": "Per 100 g",
+                    "type": "number"
+                },
+                "servingSize": {
+                    "description": "In grams",
+                    "type": "number"
+                }
+            }
+        },
+        "server.FoodsPageDTO": {
+            "type": "object",
+            "properties": {
+                "foods": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.FoodDTO"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "server.GoalDTO": {
+            "type": "object",
+            "properties": {
+                "calories": {
+                    "type": "integer"
+                },
+                "carbs": {
+                    "type": "integer"
+                },
+                "effectiveDate": {
+                    "type": "string"
+                },
+                "fats": {
+                    "type": "integer"
+                },
+                "proteins": {
+                    "type": "integer"
+                },
+                "water": {
+                    "description": "In milliliters",
+                    "type": "integer"
+                }
+            }
+        },
+        "server.JWK": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string"
+                },
+                "crv": {
+                    "description": "Curve of OKP keys",
+                    "type": "string"
+                },
+                "e": {
+                    "description": "Exponent of RSA keys",
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "kty": {
+                    "type": "string"
+                },
+                "n": {
+                    "description": "Modulus of RSA keys",
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string"
+                },
+                "x": {
+                    "description": "Public key of OKP keys, or x coordinate of EC keys",
+                    "type": "string"
+                },
+                "y": {
+                    "description": "Y coordinate of EC keys",
+                    "type": "string"
+                }
+            }
+        },
+        "server.JWKSet": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.JWK"
+                    }
+                }
+            }
+        },
+        "server.LinkIdentityCodeDTO": {
+            "type": "object",
+            "required": [
+                "code",
+                "codeVerifier",
+                "redirectUri"
+            ],
+            "properties": {
+                "code": {
+                    "description": "One-time code the client was redirected to with",
+                    "type": "string"
+                },
+                "codeVerifier": {
+                    "description": "PKCE code verifier of the code challenge of the link",
+                    "type": "string"
+                },
+                "redirectUri": {
+                    "description": "Redirect URI the link was started with",
+                    "type": "string"
+                }
+            }
+        },
+        "server.MacroSplit": {
+            "type": "object",
+            "properties": {
+                "carbs": {
+                    "type": "number"
+                },
+                "fats": {
+                    "type": "number"
+                },
+                "proteins": {
+                    "type": "number"
+                }
+            }
+        },
+        "server.MeasurementDTO": {
+            "type": "object",
+            "properties": {
+                "bodyFat": {
+                    "description": "Percentage",
+                    "type": "number"
+                },
+                "chest": {
+                    "description": "In centimeters",
+                    "type": "number"
+                },
+                "hips": {
+                    "description": "In centimeters",
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "neck": {
+                    "description": "In centimeters",
+                    "type": "number"
+                },
+                "takenAt": {
+                    "type": "string"
+                },
+                "waist": {
+                    "description": "In centimeters",
+                    "type": "number"
+                },
+                "weight": {
+                    "description": "In kilograms",
+                    "type": "number"
+                }
+            }
+        },
+        "server.MergeUserDTO": {
+            "type": "object",
+            "required": [
+                "accessToken"
+            ],
+            "properties": {
+                "accessToken": {
+                    "description": "Access token of the user merged",
+                    "type": "string"
+                }
+            }
+        },
+        "server.NutrientDTO": {
+            "type": "object",
+            "properties": {
+                "dailyValue": {
+                    "description": "Recommended daily intake, in unit",
+                    "type": "number"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "limit": {
+                    "description": "Whether dailyValue is an upper limit instead of a target",
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "unit": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.NutrientIntakeDTO": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "dailyValue": {
+                    "type": "number"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "limit": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "percentDailyValue": {
+                    "type": "number"
+                },
+                "unit": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.NutrientsDTO": {
+            "type": "object",
+            "properties": {
+                "calories": {
+                    "type": "number"
+                },
+                "carbs": {
+                    "type": "number"
+                },
+                "fats": {
+                    "type": "number"
+                },
+                "proteins": {
+                    "type": "number"
+                }
+            }
+        },
+        "server.PasswordLoginDTO": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.PasswordResetDTO": {
+            "type": "object",
+            "required": [
+                "password",
+                "token"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.PasswordResetRequestDTO": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.RecipeDTO": {
+            "type": "object",
+            "properties": {
+                "authorId": {
+                    "type": "integer"
+                },
+                "calories": {
+                    "description": "Per serving",
+                    "type": "number"
+                },
+                "carbs": {
+                    "description": "Per serving",
+                    "type": "number"
+                },
+                "fats": {
+                    "description": "Per serving",
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ingredients": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.RecipeIngredientDTO"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "proteins": {
+                    "description": "Per serving",
+                    "type": "number"
+                },
+                "servings": {
+                    "type": "integer"
+                }
+            }
+        },
+        "server.RecipeIngredientDTO": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.RegisterDTO": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "description": "Derived from the email if empty",
+                    "type": "string"
+                }
+            }
+        },
+        "server.SessionDTO": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "current": {
+                    "description": "Whether request was authenticated by this session",
+                    "type": "boolean"
+                },
+                "device": {
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lastUsedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.TrendPointDTO": {
+            "type": "object",
+            "properties": {
+                "takenAt": {
+                    "type": "string"
+                },
+                "trend": {
+                    "type": "number"
+                },
+                "weight": {
+                    "type": "number"
+                }
+            }
+        },
+        "server.UpdateDiaryEntryDTO": {
+            "type": "object",
+            "required": [
+                "meal",
+                "quantity",
+                "unit"
+            ],
+            "properties": {
+                "foodId": {
+                    "type": "integer"
+                },
+                "meal": {
+                    "type": "string",
+                    "enum": [
+                        "breakfast",
+                        "lunch",
+                        "dinner",
+                        "snack"
+                    ]
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "recipeId": {
+                    "type": "integer"
+                },
+                "unit": {
+                    "type": "string",
+                    "enum": [
+                        "g",
+                        "servings"
+                    ]
+                }
+            }
+        },
+        "server.UpdateFoodDTO": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "brand": {
+                    "type": "string"
+                },
+                "calories": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "carbs": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "fats": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "name": {
+                    "type": "string"
+                },
+                "nutrients": {
+                    "description": "Per 100 g by nutrient key, in unit of nutrient",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                },
+                "proteins": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "servingSize": {
+                    "type": "number",
+                    "minimum": 0
+                }
+            }
+        },
+        "server.UpdateMeasurementDTO": {
+            "type": "object",
+            "required": [
+                "takenAt"
+            ],
+            "properties": {
+                "bodyFat": {
+                    "type": "number",
+                    "maximum": 100,
+                    "minimum": 0
+                },
+                "chest": {
+                    "type": "number"
+                },
+                "hips": {
+                    "type": "number"
+                },
+                "neck": {
+                    "type": "number"
+                },
+                "takenAt": {
+                    "type": "string"
+                },
+                "waist": {
+                    "type": "number"
+                },
+                "weight": {
+                    "type": "number"
+                }
+            }
+        },
+        "server.UpdateNutrientDTO": {
+            "type": "object",
+            "required": [
+                "name",
+                "unit"
+            ],
+            "properties": {
+                "dailyValue": {
+                    "type": "number"
+                },
+                "limit": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "unit": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.UpdateRecipeDTO": {
+            "type": "object",
+            "required": [
+                "name",
+                "servings"
+            ],
+            "properties": {
+                "calories": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "carbs": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "fats": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "ingredients": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.RecipeIngredientDTO"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "proteins": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "servings": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "server.UpdateUserDTO": {
+            "type": "object",
+            "properties": {
+                "activityLevel": {
+                    "type": "string",
+                    "enum": [
+                        "sedentary",
+                        "light",
+                        "moderate",
+                        "active",
+                        "very_active"
+                    ]
+                },
+                "birthDate": {
+                    "type": "string"
+                },
+                "calories": {
+                    "type": "integer",
+                    "maximum": 20000
+                },
+                "carbs": {
+                    "description": "In grams",
+                    "type": "integer",
+                    "maximum": 2000
+                },
+                "email": {
+                    "type": "string",
+                    "maxLength": 254
+                },
+                "fats": {
+                    "description": "In grams",
+                    "type": "integer",
+                    "maximum": 2000
+                },
+                "firstname": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "height": {
+                    "description": "In centimeters",
+                    "type": "number",
+                    "maximum": 300,
+                    "minimum": 0
+                },
+                "hideGoals": {
+                    "description": "Kept if null",
+                    "type": "boolean"
+                },
+                "lastname": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "profileVisibility": {
+                    "description": "Kept if empty",
+                    "type": "string",
+                    "enum": [
+                        "public",
+                        "private"
+                    ]
+                },
+                "proteins": {
+                    "description": "In grams",
+                    "type": "integer",
+                    "maximum": 2000
+                },
+                "recipesAdded": {
+                    "description": "IDs of added recipes",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "sex": {
+                    "type": "string",
+                    "enum": [
+                        "male",
+                        "female"
+                    ]
+                },
+                "userProfileEdited": {
+                    "type": "boolean"
+                },
+                "username": {
+                    "description": "Between 3 and 50 characters when changed",
+                    "type": "string"
+                },
+                "water": {
+                    "description": "In milliliters",
+                    "type": "integer",
+                    "maximum": 20000
+                },
+                "weight": {
+                    "description": "In kilograms",
+                    "type": "number",
+                    "maximum": 700,
+                    "minimum": 0
+                },
+                "weightGoal": {
+                    "type": "string",
+                    "enum": [
+                        "lose",
+                        "maintain",
+                        "gain"
+                    ]
+                }
+            }
+        },
+        "server.UpdateUserRoleDTO": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "Administrator",
+                        "Writer",
+                        "Reader"
+                    ]
+                }
+            }
+        },
+        "server.UserDTO": {
+            "type": "object",
+            "properties": {
+                "activityLevel": {
+                    "type": "string"
+                },
+                "birthDate": {
+                    "type": "string"
+                },
+                "calories": {
+                    "type": "integer"
+                },
+                "carbs": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "emailVerified": {
+                    "type": "boolean"
+                },
+                "fats": {
+                    "type": "integer"
+                },
+                "firstname": {
+                    "type": "string"
+                },
+                "height": {
+                    "type": "number"
+                },
+                "hideGoals": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lastname": {
+                    "type": "string"
+                },
+                "profileVisibility": {
+                    "description": "public or private",
+                    "type": "string"
+                },
+                "proteins": {
+                    "type": "integer"
+                },
+                "recipesAdded": {
+                    "description": "IDs of added recipes",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "role": {
+                    "type": "string"
+                },
+                "sex": {
+                    "type": "string"
+                },
+                "userProfileEdited": {
+                    "type": "boolean"
+                },
+                "username": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Sent quoted in If-Match to update the user only if it's unchanged",
+                    "type": "integer"
+                },
+                "water": {
+                    "description": "In milliliters",
+                    "type": "integer"
+                },
+                "weight": {
+                    "type": "number"
+                },
+                "weightGoal": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.UserIdentityDTO": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "provider": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.UsersPageDTO": {
+            "type": "object",
+            "properties": {
+                "nextCursor": {
+                    "description": "Cursor of next page, empty on last page",
+                    "type": "string"
+                },
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.UserDTO"
+                    }
+                }
+            }
+        },
+        "server.WaterDayDTO": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.WaterEntryDTO"
+                    }
+                },
+                "goal": {
+                    "description": "In milliliters",
+                    "type": "integer"
+                },
+                "remaining": {
+                    "description": "In milliliters, negative if goal was exceeded",
+                    "type": "integer"
+                },
+                "total": {
+                    "description": "In milliliters",
+                    "type": "integer"
+                }
+            }
+        },
+        "server.WaterEntryDTO": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "In milliliters",
+                    "type": "integer"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "loggedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.WeightTrendDTO": {
+            "type": "object",
+            "properties": {
+                "alpha": {
+                    "type": "number"
+                },
+                "onTrack": {
+                    "description": "Whether weekly rate agrees with weight goal",
+                    "type": "boolean"
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.TrendPointDTO"
+                    }
+                },
+                "weeklyRate": {
+                    "description": "Kilograms per week",
+                    "type": "number"
+                },
+                "weightGoal": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.tokenResponse": {
+            "type": "object",
+            "properties": {
+                "AccessToken": {
+                    "description": "Deprecated alias of access_token",
+                    "type": "string"
+                },
+                "TokenType": {
+                    "description": "Deprecated alias of token_type",
+                    "type": "string"
+                },
+                "access_token": {
+                    "type": "string"
+                },
+                "expires_in": {
+                    "description": "Seconds until access token expires",
+                    "type": "integer"
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "token_type": {
+                    "type": "string"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "AccessToken": {
+            "type": "apiKey",
+            "name": "AccessToken",
+            "in": "header"
+        },
+        "Bearer": {
+            "type": "apiKey",
+            "name": "Authorization",
             "in": "header"
         },
         "OAuth2AccessCode": {
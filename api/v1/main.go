@@ -1,7 +1,11 @@
 package main
 
 import (
+	"context"
+	"log"
+	"net/smtp"
 	"os"
+	"strings"
 
 	_ "github.com/JonathanGzzBen/nutrity-api/api/v1/docs"
 	"github.com/JonathanGzzBen/nutrity-api/api/v1/repository"
@@ -31,6 +35,10 @@ import (
 // @in header
 // @name AccessToken
 //
+// @securityDefinitions.apikey Bearer
+// @in header
+// @name Authorization
+//
 // @securitydefinitions.oauth2.accessCode OAuth2AccessCode
 // @tokenUrl /v1/auth/google-callback
 // @authorizationUrl /v1/auth/google-login
@@ -57,24 +65,89 @@ func main() {
 	if err != nil {
 		panic("Could not connect to database")
 	}
-	serverConfig := server.ServerConfig{
-		GoogleConfig: &oauth2.Config{
-			ClientID:     os.Getenv("NUTRITY_GOOGLE_CLIENT_ID"),
-			ClientSecret: os.Getenv("NUTRITY_GOOGLE_CLIENT_SECRET"),
-			Endpoint:     endpoints.Google,
-			RedirectURL:  "http://127.0.0.1:8080/v1/auth/google-callback",
-			Scopes:       []string{"openid", "profile", "email"},
-		},
-		UsersRepo: repository.NewUsersGormRepository(db),
-	}
 	// hostname is used by multiple controllers
 	// to make requests to authentication controller
 	hostname := os.Getenv("NUTRITY_HOSTNAME")
 	if hostname == "" {
 		panic("Environment variable NUTRITY_HOSTNAME missing")
 	}
+	serverConfig := server.ServerConfig{
+		IdentityProviders: map[string]server.IdentityProvider{
+			"google": server.NewGoogleProvider(&oauth2.Config{
+				ClientID:     os.Getenv("NUTRITY_GOOGLE_CLIENT_ID"),
+				ClientSecret: os.Getenv("NUTRITY_GOOGLE_CLIENT_SECRET"),
+				Endpoint:     endpoints.Google,
+				RedirectURL:  hostname + "/v1/auth/google-callback",
+				Scopes:       []string{"openid", "profile", "email"},
+			}),
+		},
+		UsersRepo:              repository.NewUsersGormRepository(db),
+		RecipesRepo:            repository.NewRecipesGormRepository(db),
+		FoodsRepo:              repository.NewFoodsGormRepository(db),
+		DiaryRepo:              repository.NewDiaryGormRepository(db),
+		GoalsRepo:              repository.NewGoalsGormRepository(db),
+		MeasurementsRepo:       repository.NewMeasurementsGormRepository(db),
+		WaterRepo:              repository.NewWaterGormRepository(db),
+		NutrientsRepo:          repository.NewNutrientsGormRepository(db),
+		SessionsRepo:           repository.NewSessionsGormRepository(db),
+		LoginStatesRepo:        repository.NewLoginStatesGormRepository(db),
+		PasswordResetsRepo:     repository.NewPasswordResetsGormRepository(db),
+		EmailVerificationsRepo: repository.NewEmailVerificationsGormRepository(db),
+	}
 	serverConfig.Hostname = hostname
-	s := server.NewServer(serverConfig)
+	if clientID := os.Getenv("NUTRITY_GITHUB_CLIENT_ID"); clientID != "" {
+		serverConfig.IdentityProviders["github"] = server.NewGitHubProvider(
+			clientID,
+			os.Getenv("NUTRITY_GITHUB_CLIENT_SECRET"),
+			hostname+"/v1/auth/github/callback",
+		)
+	}
+	// Generic OpenID Connect provider, configured from its discovery document
+	if discoveryURL := os.Getenv("NUTRITY_OIDC_DISCOVERY_URL"); discoveryURL != "" {
+		name := os.Getenv("NUTRITY_OIDC_NAME")
+		if name == "" {
+			name = "oidc"
+		}
+		provider, err := server.NewOIDCProvider(
+			context.Background(),
+			discoveryURL,
+			os.Getenv("NUTRITY_OIDC_CLIENT_ID"),
+			os.Getenv("NUTRITY_OIDC_CLIENT_SECRET"),
+			hostname+"/v1/auth/"+name+"/callback",
+		)
+		if err != nil {
+			panic("Could not configure OpenID Connect provider: " + err.Error())
+		}
+		serverConfig.IdentityProviders[name] = provider
+	}
+	// Clients under these custom schemes or web origins can be redirected to after logging in
+	if redirectURIs := os.Getenv("NUTRITY_REDIRECT_URIS"); redirectURIs != "" {
+		serverConfig.RedirectURIs = strings.Split(redirectURIs, ",")
+	}
+	// Users with these emails are given Administrator role when they log in with an identity that verified them
+	if adminEmails := os.Getenv("NUTRITY_ADMIN_EMAILS"); adminEmails != "" {
+		serverConfig.AdminEmails = strings.Split(adminEmails, ",")
+	}
+	// Password reset and email verification emails are sent through SMTP server if one is provided,
+	// otherwise password resets are disabled
+	if smtpAddr := os.Getenv("NUTRITY_SMTP_ADDR"); smtpAddr != "" {
+		mailer := &server.SMTPMailer{Addr: smtpAddr, From: os.Getenv("NUTRITY_SMTP_FROM")}
+		if smtpUser := os.Getenv("NUTRITY_SMTP_USER"); smtpUser != "" {
+			mailer.Auth = smtp.PlainAuth("", smtpUser, os.Getenv("NUTRITY_SMTP_PASS"), strings.Split(smtpAddr, ":")[0])
+		}
+		serverConfig.Mailer = mailer
+	}
+	serverConfig.PasswordResetURL = os.Getenv("NUTRITY_PASSWORD_RESET_URL")
+	// Client IPs are read from X-Forwarded-For only for requests of these proxies
+	if trustedProxies := os.Getenv("NUTRITY_TRUSTED_PROXIES"); trustedProxies != "" {
+		serverConfig.TrustedProxies = strings.Split(trustedProxies, ",")
+	}
+	// Access tokens are signed JWTs if a directory of keys is provided
+	serverConfig.JWTKeysDir = os.Getenv("NUTRITY_JWT_KEYS_DIR")
+	s, err := server.NewServer(serverConfig)
+	if err != nil {
+		log.Fatalf("Could not start server: %v", err)
+	}
 
 	port := os.Getenv("NUTRITY_PORT")
 	if port == "" {
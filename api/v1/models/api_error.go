@@ -1,6 +1,55 @@
 package models
 
+// Codes of errors, stable for clients to handle them
+const (
+	ErrorCodeInvalidRequest       = "invalid_request"   // Malformed parameters or body
+	ErrorCodeValidationFailed     = "validation_failed" // Invalid fields of body, detailed in errors
+	ErrorCodeUnauthenticated      = "unauthenticated"   // Missing or invalid access token
+	ErrorCodeTokenExpired         = "token_expired"
+	ErrorCodeInvalidToken         = "invalid_token" // Invalid or expired single-use token, such as password reset tokens
+	ErrorCodeInvalidGrant         = "invalid_grant" // Invalid refresh token or authorization code
+	ErrorCodeInvalidCredentials   = "invalid_credentials"
+	ErrorCodeInvalidState         = "invalid_state" // Login state did not match or expired
+	ErrorCodeIdentityNotVerified  = "identity_not_verified"
+	ErrorCodeForbidden            = "forbidden"
+	ErrorCodeNotFound             = "not_found"
+	ErrorCodeUserNotFound         = "user_not_found"
+	ErrorCodeFoodNotFound         = "food_not_found"
+	ErrorCodeRecipeNotFound       = "recipe_not_found"
+	ErrorCodeNutrientNotFound     = "nutrient_not_found"
+	ErrorCodeDiaryEntryNotFound   = "diary_entry_not_found"
+	ErrorCodeWaterEntryNotFound   = "water_entry_not_found"
+	ErrorCodeMeasurementNotFound  = "measurement_not_found"
+	ErrorCodeSessionNotFound      = "session_not_found"
+	ErrorCodeIdentityNotFound     = "identity_not_found"
+	ErrorCodeProviderNotFound     = "provider_not_found"
+	ErrorCodeConflict             = "conflict"
+	ErrorCodeEmailRegistered      = "email_registered"
+	ErrorCodeUsernameRegistered   = "username_registered"
+	ErrorCodeIdentityLinked       = "identity_linked"
+	ErrorCodeLastIdentity         = "last_identity"
+	ErrorCodeNutrientExists       = "nutrient_exists"
+	ErrorCodePreconditionFailed   = "precondition_failed"
+	ErrorCodeUnsupportedMediaType = "unsupported_media_type"
+	ErrorCodeRateLimited          = "rate_limited"
+	ErrorCodeInternal             = "internal_error"
+)
+
+// APIError is an error response, formatted as problem details (RFC 7807)
+// with media type application/problem+json.
 type APIError struct {
-	Code    int    `json:"code" example:"400"`
-	Message string `json:"message" example:"status bad request"`
+	Type      string       `json:"type" example:"about:blank"`
+	Title     string       `json:"title" example:"Bad Request"` // Text of status
+	Status    int          `json:"status" example:"400"`
+	Code      string       `json:"code" example:"validation_failed"` // One of the ErrorCode constants
+	Detail    string       `json:"detail" example:"invalid update user"`
+	Instance  string       `json:"instance,omitempty" example:"/v1/users/1"` // Path of request
+	RequestID string       `json:"requestId,omitempty" example:"5d1b4f0e9c2a7d3e"`
+	Errors    []FieldError `json:"errors,omitempty"` // Errors of invalid fields of request
+}
+
+// FieldError is an invalid field of a request body.
+type FieldError struct {
+	Field   string `json:"field" example:"email"`
+	Message string `json:"message" example:"must be a valid email"`
 }
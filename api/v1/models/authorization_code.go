@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// AuthorizationCode is a one-time code clients redirected to
+// after logging in exchange for the tokens of a new session,
+// or after linking an identity exchange to link it.
+type AuthorizationCode struct {
+	CodeHash      string    `json:"-" gorm:"primaryKey"` // SHA-256 hash of code, codes aren't stored
+	UserID        uint      `json:"userId"`
+	RedirectURI   string    `json:"redirectUri"` // Client the code was issued to
+	CodeChallenge string    `json:"-"`           // S256 PKCE code challenge of the client, if it sent one
+	Provider      string    `json:"provider"`    // Provider of identity linked once exchanged, empty for logins
+	Subject       string    `json:"-"`
+	Email         string    `json:"-"`
+	Device        string    `json:"device"`
+	CreatedAt     time.Time `json:"createdAt"`
+	ExpiresAt     time.Time `json:"expiresAt"`
+}
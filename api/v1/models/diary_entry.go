@@ -0,0 +1,27 @@
+package models
+
+const (
+	MealBreakfast = "breakfast"
+	MealLunch     = "lunch"
+	MealDinner    = "dinner"
+	MealSnack     = "snack"
+)
+
+const (
+	UnitGrams    = "g"
+	UnitServings = "servings"
+)
+
+type DiaryEntry struct {
+	ID       uint    `json:"id,omitempty"`
+	UserID   uint    `json:"userId" gorm:"index:idx_diary_entries_user_date"`
+	Date     string  `json:"date" gorm:"index:idx_diary_entries_user_date"` // Formatted as YYYY-MM-DD
+	Meal     string  `json:"meal"`                                          // breakfast, lunch, dinner or snack
+	FoodID   *uint   `json:"foodId"`
+	Food     *Food   `json:"-" gorm:"constraint:OnDelete:SET NULL;"`
+	RecipeID *uint   `json:"recipeId"`
+	Recipe   *Recipe `json:"-" gorm:"constraint:OnDelete:SET NULL;"`
+	Quantity float64 `json:"quantity"`
+	Unit     string  `json:"unit"`                        // g or servings
+	Version  uint    `json:"-" gorm:"not null;default:1"` // Incremented by every update, tags entries in ETags
+}
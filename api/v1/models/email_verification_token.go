@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// EmailVerificationToken is a single-use token mailed to Email
+// that verifies it belongs to the user, of which only its hash is stored.
+type EmailVerificationToken struct {
+	ID        uint      `json:"id,omitempty"`
+	UserID    uint      `json:"userId" gorm:"index"`
+	Email     string    `json:"email"`
+	TokenHash string    `json:"-" gorm:"uniqueIndex"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
@@ -0,0 +1,15 @@
+package models
+
+type Food struct {
+	ID          uint    `json:"id,omitempty"`
+	Name        string  `json:"name" gorm:"index"`
+	Brand       string  `json:"brand"`
+	ServingSize float64 `json:"servingSize"` // In grams
+	// Nutrients per 100 g
+	Calories float64 `json:"calories"`
+	Carbs    float64 `json:"carbs"`
+	Fats     float64 `json:"fats"`
+	Proteins float64 `json:"proteins"`
+	// Other nutrients per 100 g
+	Nutrients []FoodNutrient `json:"nutrients" gorm:"constraint:OnDelete:CASCADE;"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// LoginState is an OAuth2 login in progress,
+// identified by the random state sent to the provider.
+type LoginState struct {
+	State        string `json:"-" gorm:"primaryKey"`
+	Provider     string `json:"provider"` // Name of identity provider the user was sent to
+	UserID       uint   `json:"userId"`   // User linking an identity, zero for logins
+	CodeVerifier string `json:"-"`        // PKCE verifier of the code challenge sent to the provider
+	Nonce        string `json:"-"`        // Nonce ID tokens of the provider must carry
+	// Client redirected to with an authorization code once logged in or linked,
+	// logins without one respond with the tokens and links with the identity.
+	RedirectURI   string    `json:"redirectUri"`
+	ClientState   string    `json:"-"` // State of the client, returned along with the authorization code
+	CodeChallenge string    `json:"-"` // S256 PKCE code challenge of the client, if it sent one
+	Device        string    `json:"device"`
+	CreatedAt     time.Time `json:"createdAt"`
+	ExpiresAt     time.Time `json:"expiresAt"`
+}
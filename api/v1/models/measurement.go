@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Measurement holds body measurements of a user taken at a point in time.
+// Measurements that were not taken are nil.
+type Measurement struct {
+	ID      uint      `json:"id,omitempty"`
+	UserID  uint      `json:"userId" gorm:"index"`
+	TakenAt time.Time `json:"takenAt" gorm:"index"`
+	Weight  *float64  `json:"weight"`  // In kilograms
+	BodyFat *float64  `json:"bodyFat"` // Percentage
+	Waist   *float64  `json:"waist"`   // In centimeters
+	Hips    *float64  `json:"hips"`    // In centimeters
+	Chest   *float64  `json:"chest"`   // In centimeters
+	Neck    *float64  `json:"neck"`    // In centimeters
+}
@@ -0,0 +1,18 @@
+package models
+
+// Nutrient is a nutrient tracked in foods and diary summaries,
+// besides calories and macronutrients.
+type Nutrient struct {
+	Key        string  `json:"key" gorm:"primaryKey"` // Identifier, e.g. vitamin_c
+	Name       string  `json:"name"`
+	Unit       string  `json:"unit"`       // g, mg or µg
+	DailyValue float64 `json:"dailyValue"` // Recommended daily intake, in Unit
+	Limit      bool    `json:"limit"`      // Whether DailyValue is an upper limit instead of a target
+}
+
+// FoodNutrient holds the amount of a nutrient in 100 g of a food.
+type FoodNutrient struct {
+	FoodID      uint    `json:"foodId" gorm:"primaryKey"`
+	NutrientKey string  `json:"nutrientKey" gorm:"primaryKey"`
+	Amount      float64 `json:"amount"` // In unit of nutrient
+}
@@ -0,0 +1,18 @@
+package models
+
+// InitialGoalDate is the effective date of the goals users had
+// before their goals history started, in effect since any date.
+const InitialGoalDate = "0001-01-01"
+
+// NutritionGoal holds the daily goals of a user
+// starting from EffectiveDate until the next goal of the user.
+type NutritionGoal struct {
+	ID            uint   `json:"id,omitempty"`
+	UserID        uint   `json:"userId" gorm:"uniqueIndex:idx_nutrition_goals_user_date"`
+	EffectiveDate string `json:"effectiveDate" gorm:"uniqueIndex:idx_nutrition_goals_user_date"` // Formatted as YYYY-MM-DD
+	Calories      uint   `json:"calories"`
+	Carbs         uint   `json:"carbs"`
+	Fats          uint   `json:"fats"`
+	Proteins      uint   `json:"proteins"`
+	Water         uint   `json:"water"` // In milliliters
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// PasswordResetToken is a single-use token that resets the password of a user,
+// of which only its hash is stored.
+type PasswordResetToken struct {
+	ID        uint      `json:"id,omitempty"`
+	UserID    uint      `json:"userId" gorm:"index"`
+	TokenHash string    `json:"-" gorm:"uniqueIndex"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
@@ -0,0 +1,21 @@
+package models
+
+type Recipe struct {
+	ID          uint               `json:"id,omitempty"`
+	Name        string             `json:"name"`
+	Servings    uint               `json:"servings"`
+	Ingredients []RecipeIngredient `json:"ingredients"`
+	// Nutrients per serving
+	Calories float64 `json:"calories"`
+	Carbs    float64 `json:"carbs"`
+	Fats     float64 `json:"fats"`
+	Proteins float64 `json:"proteins"`
+	AuthorID uint    `json:"authorId"`
+}
+
+type RecipeIngredient struct {
+	ID       uint   `json:"-"`
+	RecipeID uint   `json:"-"`
+	Name     string `json:"name"`
+	Quantity string `json:"quantity"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Session is a login of a user, authenticated by a short-lived access token
+// of which only its hash is stored, and renewed with refresh tokens.
+type Session struct {
+	ID              uint      `json:"id,omitempty"`
+	UserID          uint      `json:"userId" gorm:"index"`
+	TokenHash       string    `json:"-" gorm:"uniqueIndex"`
+	Device          string    `json:"device"` // Label of the device that logged in
+	CreatedAt       time.Time `json:"createdAt"`
+	ExpiresAt       time.Time `json:"expiresAt"`       // Time after which session can no longer be refreshed
+	AccessExpiresAt time.Time `json:"accessExpiresAt"` // Time after which access token is no longer valid
+	LastUsedAt      time.Time `json:"lastUsedAt"`
+}
+
+// RefreshToken is a single-use token that renews the tokens of a session,
+// of which only its hash is stored.
+//
+// Used refresh tokens are kept to detect their reuse.
+type RefreshToken struct {
+	ID        uint       `json:"id,omitempty"`
+	SessionID uint       `json:"sessionId" gorm:"index"`
+	TokenHash string     `json:"-" gorm:"uniqueIndex"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UsedAt    *time.Time `json:"usedAt"`
+}
@@ -1,20 +1,73 @@
 package models
 
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	RoleAdministrator = "Administrator"
+	RoleWriter        = "Writer"
+	RoleReader        = "Reader"
+)
+
+const (
+	ProfileVisibilityPublic  = "public"
+	ProfileVisibilityPrivate = "private"
+)
+
+const (
+	SexMale   = "male"
+	SexFemale = "female"
+)
+
+const (
+	ActivitySedentary  = "sedentary"
+	ActivityLight      = "light"
+	ActivityModerate   = "moderate"
+	ActivityActive     = "active"
+	ActivityVeryActive = "very_active"
+)
+
+const (
+	WeightGoalLose     = "lose"
+	WeightGoalMaintain = "maintain"
+	WeightGoalGain     = "gain"
+)
+
 type User struct {
 	// Auth
-	ID          uint   `json:"id,omitempty"`
-	GoogleSub   string `json:"-"`
-	AccessToken string `json:"-"`
+	ID           uint           `json:"id,omitempty"`
+	Role         string         `json:"role" gorm:"default:Reader"` // Administrator, Writer or Reader
+	Identities   []UserIdentity `json:"-"`                          // Accounts in identity providers that log in as user
+	PasswordHash string         `json:"-"`                          // Argon2id hash of password of local accounts
+	CreatedAt    time.Time      `json:"createdAt" gorm:"index"`
+	Version      uint           `json:"-" gorm:"not null;default:1"` // Incremented by every update, tags users in ETags
+	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`              // Deleted users are purged after a grace period
 	// Data
 	Username          string `json:"username"`
 	Email             string `json:"email"`
+	EmailVerified     bool   `json:"emailVerified"` // Whether the user proved owning email, through an identity provider or password reset
 	FirstName         string `json:"firstname"`
 	LastName          string `json:"lastname"`
 	UserProfileEdited bool   `json:"userProfileEdited"`
-	Calories          uint   `json:"calories"`
-	Carbs             uint   `json:"carbs"`
-	Day               uint   `json:"day"`
-	Fats              uint   `json:"fats"`
-	Proteins          uint   `json:"proteins"`
-	RecipesAdded      string `json:"recipesAdded"` // List of recipes divided by character '^'
+	// Privacy
+	ProfileVisibility string `json:"profileVisibility" gorm:"default:public"` // public or private, private profiles only show username to other users
+	HideGoals         bool   `json:"hideGoals"`                               // Whether goals are hidden from other users
+	// Profile
+	BirthDate     string  `json:"birthDate"` // Formatted as YYYY-MM-DD
+	Sex           string  `json:"sex"`       // male or female
+	Height        float64 `json:"height"`    // In centimeters
+	Weight        float64 `json:"weight"`    // In kilograms
+	ActivityLevel string  `json:"activityLevel"`
+	WeightGoal    string  `json:"weightGoal"` // lose, maintain or gain
+	// Goals currently in effect, see NutritionGoal for their history
+	Calories uint `json:"calories"`
+	Carbs    uint `json:"carbs"`
+	Fats     uint `json:"fats"`
+	Proteins uint `json:"proteins"`
+	Water    uint `json:"water"` // In milliliters
+	// Recipes the user added to their list
+	RecipesAdded []Recipe `json:"recipesAdded" gorm:"many2many:user_recipes_added;"`
 }
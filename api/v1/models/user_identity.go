@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// UserIdentity is an account of a user in an external identity provider,
+// which can log in as the user.
+type UserIdentity struct {
+	ID        uint      `json:"id,omitempty"`
+	UserID    uint      `json:"userId" gorm:"index"`
+	Provider  string    `json:"provider" gorm:"uniqueIndex:idx_user_identities_provider_subject"` // Name of identity provider, such as google
+	Subject   string    `json:"-" gorm:"uniqueIndex:idx_user_identities_provider_subject"`        // ID of account in identity provider
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// WaterEntry holds water drunk by a user.
+type WaterEntry struct {
+	ID       uint      `json:"id,omitempty"`
+	UserID   uint      `json:"userId" gorm:"index:idx_water_entries_user_date"`
+	Date     string    `json:"date" gorm:"index:idx_water_entries_user_date"` // Formatted as YYYY-MM-DD
+	LoggedAt time.Time `json:"loggedAt"`
+	Amount   uint      `json:"amount"` // In milliliters
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DiaryRepository interface {
+	GetDiaryEntries(userID uint, date string) ([]models.DiaryEntry, error)
+	GetDiaryEntriesBetween(userID uint, from string, to string) ([]models.DiaryEntry, error)
+	GetDiaryEntry(uint) (*models.DiaryEntry, error)
+	CreateDiaryEntry(*models.DiaryEntry) (*models.DiaryEntry, error)
+	UpdateDiaryEntry(*models.DiaryEntry) (*models.DiaryEntry, error)
+	DeleteDiaryEntry(uint) error
+}
+
+type DiaryGormRepository struct {
+	db *gorm.DB
+}
+
+func NewDiaryGormRepository(db *gorm.DB) *DiaryGormRepository {
+	db.AutoMigrate(&models.DiaryEntry{})
+	return &DiaryGormRepository{
+		db: db,
+	}
+}
+
+// preloaded returns a query that loads the food, with its nutrients,
+// or recipe of diary entries.
+func (r *DiaryGormRepository) preloaded() *gorm.DB {
+	return r.db.Preload("Food.Nutrients").Preload("Recipe")
+}
+
+func (r *DiaryGormRepository) GetDiaryEntries(userID uint, date string) ([]models.DiaryEntry, error) {
+	var entries []models.DiaryEntry
+	res := r.preloaded().Where("user_id = ? AND date = ?", userID, date).Order("id").Find(&entries)
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return entries, nil
+}
+
+// GetDiaryEntriesBetween returns the diary entries of user
+// from date from to date to, both inclusive, ordered by date.
+func (r *DiaryGormRepository) GetDiaryEntriesBetween(userID uint, from string, to string) ([]models.DiaryEntry, error) {
+	var entries []models.DiaryEntry
+	res := r.preloaded().Where("user_id = ? AND date >= ? AND date <= ?", userID, from, to).Order("date").Order("id").Find(&entries)
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return entries, nil
+}
+
+func (r *DiaryGormRepository) GetDiaryEntry(id uint) (*models.DiaryEntry, error) {
+	var entry *models.DiaryEntry
+	res := r.preloaded().First(&entry, id)
+	if res.Error == gorm.ErrRecordNotFound {
+		return nil, ErrNotFound
+	}
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return entry, nil
+}
+
+func (r *DiaryGormRepository) CreateDiaryEntry(e *models.DiaryEntry) (*models.DiaryEntry, error) {
+	res := r.db.Omit(clause.Associations).Create(e)
+	if res.Error != nil {
+		return nil, ErrCouldNotCreate
+	}
+	return r.GetDiaryEntry(e.ID)
+}
+
+// UpdateDiaryEntry saves e, incrementing its version, only if it's still
+// the version of the entry stored, otherwise it returns ErrVersionConflict.
+func (r *DiaryGormRepository) UpdateDiaryEntry(e *models.DiaryEntry) (*models.DiaryEntry, error) {
+	version := e.Version
+	e.Version = version + 1
+	res := r.db.Model(e).Where("version = ?", version).Select("*").Omit(clause.Associations).Updates(e)
+	if res.Error != nil {
+		e.Version = version
+		return nil, ErrCouldNotUpdate
+	}
+	if res.RowsAffected == 0 {
+		e.Version = version
+		return nil, ErrVersionConflict
+	}
+	return r.GetDiaryEntry(e.ID)
+}
+
+func (r *DiaryGormRepository) DeleteDiaryEntry(id uint) error {
+	res := r.db.Delete(&models.DiaryEntry{}, id)
+	if res.Error != nil {
+		return ErrCouldNotDelete
+	}
+	if res.RowsAffected != 1 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"gorm.io/gorm"
+)
+
+type EmailVerificationsRepository interface {
+	GetEmailVerificationTokenByHash(string) (*models.EmailVerificationToken, error)
+	CreateEmailVerificationToken(*models.EmailVerificationToken) (*models.EmailVerificationToken, error)
+	DeleteEmailVerificationTokens(userID uint) error
+	DeleteExpiredEmailVerificationTokens(now time.Time) error
+}
+
+type EmailVerificationsGormRepository struct {
+	db *gorm.DB
+}
+
+func NewEmailVerificationsGormRepository(db *gorm.DB) *EmailVerificationsGormRepository {
+	db.AutoMigrate(&models.EmailVerificationToken{})
+	return &EmailVerificationsGormRepository{
+		db: db,
+	}
+}
+
+func (r *EmailVerificationsGormRepository) GetEmailVerificationTokenByHash(hash string) (*models.EmailVerificationToken, error) {
+	var evt *models.EmailVerificationToken
+	res := r.db.Where("token_hash = ?", hash).First(&evt)
+	if res.Error == gorm.ErrRecordNotFound {
+		return nil, ErrNotFound
+	}
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return evt, nil
+}
+
+func (r *EmailVerificationsGormRepository) CreateEmailVerificationToken(evt *models.EmailVerificationToken) (*models.EmailVerificationToken, error) {
+	res := r.db.Create(evt)
+	if res.Error != nil {
+		return nil, ErrCouldNotCreate
+	}
+	return evt, nil
+}
+
+// DeleteEmailVerificationTokens deletes every email verification token of user.
+func (r *EmailVerificationsGormRepository) DeleteEmailVerificationTokens(userID uint) error {
+	res := r.db.Where("user_id = ?", userID).Delete(&models.EmailVerificationToken{})
+	if res.Error != nil {
+		return ErrCouldNotDelete
+	}
+	return nil
+}
+
+// DeleteExpiredEmailVerificationTokens deletes every email verification token expired at now.
+func (r *EmailVerificationsGormRepository) DeleteExpiredEmailVerificationTokens(now time.Time) error {
+	res := r.db.Where("expires_at <= ?", now).Delete(&models.EmailVerificationToken{})
+	if res.Error != nil {
+		return ErrCouldNotDelete
+	}
+	return nil
+}
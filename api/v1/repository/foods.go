@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"log"
+	"strings"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FoodsRepository interface {
+	SearchFoods(query string, offset, limit int) ([]models.Food, int64, error)
+	GetFood(uint) (*models.Food, error)
+	CreateFood(*models.Food) (*models.Food, error)
+	UpdateFood(*models.Food) (*models.Food, error)
+	DeleteFood(uint) error
+}
+
+type FoodsGormRepository struct {
+	db *gorm.DB
+}
+
+func NewFoodsGormRepository(db *gorm.DB) *FoodsGormRepository {
+	db.AutoMigrate(&models.Food{}, &models.FoodNutrient{})
+	if err := migrateFoodNutrientColumns(db); err != nil {
+		log.Printf("Could not migrate nutrients of foods: %v", err)
+	}
+	return &FoodsGormRepository{
+		db: db,
+	}
+}
+
+// migrateFoodNutrientColumns moves the legacy fiber, sugar and sodium columns
+// of foods table into FoodNutrient records.
+func migrateFoodNutrientColumns(db *gorm.DB) error {
+	columns := []string{"fiber", "sugar", "sodium"}
+	if !db.Migrator().HasColumn(&models.Food{}, columns[0]) {
+		return nil
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var rows []struct {
+			ID     uint
+			Fiber  float64
+			Sugar  float64
+			Sodium float64
+		}
+		if err := tx.Table("foods").Select("id", "fiber", "sugar", "sodium").Scan(&rows).Error; err != nil {
+			return err
+		}
+		for _, row := range rows {
+			amounts := map[string]float64{"fiber": row.Fiber, "sugar": row.Sugar, "sodium": row.Sodium}
+			for _, key := range columns {
+				if amounts[key] == 0 {
+					continue
+				}
+				fn := &models.FoodNutrient{FoodID: row.ID, NutrientKey: key, Amount: amounts[key]}
+				if err := tx.Create(fn).Error; err != nil {
+					return err
+				}
+			}
+		}
+		// Cleared in case the columns can't be dropped
+		return tx.Table("foods").Where("fiber <> 0 OR sugar <> 0 OR sodium <> 0").
+			Updates(map[string]interface{}{"fiber": 0, "sugar": 0, "sodium": 0}).Error
+	})
+	if err != nil {
+		return err
+	}
+	for _, column := range columns {
+		if err := db.Migrator().DropColumn(&models.Food{}, column); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// likeEscaper escapes wildcards of LIKE patterns, using '\' as escape character.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SearchFoods returns a page of the foods whose name or brand contain query,
+// ignoring case, along with the total number of matching foods.
+//
+// Foods whose name starts with query are listed first.
+func (r *FoodsGormRepository) SearchFoods(query string, offset, limit int) ([]models.Food, int64, error) {
+	pattern := likeEscaper.Replace(strings.ToLower(query))
+	matching := func() *gorm.DB {
+		return r.db.Model(&models.Food{}).
+			Where(`LOWER(name) LIKE ? ESCAPE '\' OR LOWER(brand) LIKE ? ESCAPE '\'`, "%"+pattern+"%", "%"+pattern+"%")
+	}
+
+	var total int64
+	if res := matching().Count(&total); res.Error != nil {
+		return nil, 0, ErrCouldNotRetrieve
+	}
+	var foods []models.Food
+	res := matching().
+		Preload("Nutrients").
+		Clauses(clause.OrderBy{Expression: clause.Expr{
+			SQL:                `CASE WHEN LOWER(name) LIKE ? ESCAPE '\' THEN 0 ELSE 1 END, name, id`,
+			Vars:               []interface{}{pattern + "%"},
+			WithoutParentheses: true,
+		}}).
+		Offset(offset).
+		Limit(limit).
+		Find(&foods)
+	if res.Error != nil {
+		return nil, 0, ErrCouldNotRetrieve
+	}
+	return foods, total, nil
+}
+
+func (r *FoodsGormRepository) GetFood(id uint) (*models.Food, error) {
+	var food *models.Food
+	res := r.db.Preload("Nutrients").First(&food, id)
+	if res.Error == gorm.ErrRecordNotFound {
+		return nil, ErrNotFound
+	}
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return food, nil
+}
+
+func (r *FoodsGormRepository) CreateFood(f *models.Food) (*models.Food, error) {
+	res := r.db.Create(f)
+	if res.Error != nil {
+		return nil, ErrCouldNotCreate
+	}
+	return f, nil
+}
+
+// UpdateFood saves f replacing its previous nutrients.
+func (r *FoodsGormRepository) UpdateFood(f *models.Food) (*models.Food, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("food_id = ?", f.ID).Delete(&models.FoodNutrient{}).Error; err != nil {
+			return err
+		}
+		for i := range f.Nutrients {
+			f.Nutrients[i].FoodID = f.ID
+		}
+		return tx.Save(f).Error
+	})
+	if err != nil {
+		return nil, ErrCouldNotUpdate
+	}
+	return f, nil
+}
+
+func (r *FoodsGormRepository) DeleteFood(id uint) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("food_id = ?", id).Delete(&models.FoodNutrient{}).Error; err != nil {
+			return err
+		}
+		res := tx.Delete(&models.Food{}, id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected != 1 {
+			return ErrNotFound
+		}
+		return nil
+	})
+	if err == ErrNotFound {
+		return ErrNotFound
+	}
+	if err != nil {
+		return ErrCouldNotDelete
+	}
+	return nil
+}
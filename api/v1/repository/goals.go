@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"log"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"gorm.io/gorm"
+)
+
+type GoalsRepository interface {
+	GetGoals(userID uint) ([]models.NutritionGoal, error)
+	GetGoalAt(userID uint, date string) (*models.NutritionGoal, error)
+	SaveGoal(*models.NutritionGoal) (*models.NutritionGoal, error)
+}
+
+type GoalsGormRepository struct {
+	db *gorm.DB
+}
+
+func NewGoalsGormRepository(db *gorm.DB) *GoalsGormRepository {
+	db.AutoMigrate(&models.NutritionGoal{})
+	if err := migrateInitialGoals(db); err != nil {
+		log.Printf("Could not migrate goals of users: %v", err)
+	}
+	return &GoalsGormRepository{
+		db: db,
+	}
+}
+
+// migrateInitialGoals records the goals stored in users table
+// of users without goals history as their initial goal,
+// effective since InitialGoalDate.
+//
+// Users whose migration failed are migrated the next time.
+func migrateInitialGoals(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&models.User{}) {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		var users []models.User
+		res := tx.Unscoped().Select("id", "calories", "carbs", "fats", "proteins", "water").
+			Where("calories <> 0 OR carbs <> 0 OR fats <> 0 OR proteins <> 0 OR water <> 0").
+			Where("id NOT IN (?)", tx.Model(&models.NutritionGoal{}).Select("user_id")).
+			Find(&users)
+		if res.Error != nil {
+			return res.Error
+		}
+		for _, u := range users {
+			g := &models.NutritionGoal{
+				UserID:        u.ID,
+				EffectiveDate: models.InitialGoalDate,
+				Calories:      u.Calories,
+				Carbs:         u.Carbs,
+				Fats:          u.Fats,
+				Proteins:      u.Proteins,
+				Water:         u.Water,
+			}
+			if err := tx.Create(g).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetGoals returns the goals history of user ordered by effective date.
+func (r *GoalsGormRepository) GetGoals(userID uint) ([]models.NutritionGoal, error) {
+	var goals []models.NutritionGoal
+	res := r.db.Where("user_id = ?", userID).Order("effective_date").Find(&goals)
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return goals, nil
+}
+
+// GetGoalAt returns the goal of user in effect at date.
+//
+// Dates before the first goal of user are evaluated against it.
+func (r *GoalsGormRepository) GetGoalAt(userID uint, date string) (*models.NutritionGoal, error) {
+	goal, err := goalAt(r.db, userID, date)
+	if err == ErrNotFound {
+		return nil, err
+	}
+	if err != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return goal, nil
+}
+
+// goalAt returns the goal of user in effect at date, or the first one
+// if date is before it, querying tx.
+func goalAt(tx *gorm.DB, userID uint, date string) (*models.NutritionGoal, error) {
+	var goal *models.NutritionGoal
+	res := tx.Where("user_id = ? AND effective_date <= ?", userID, date).Order("effective_date DESC").Limit(1).Find(&goal)
+	if res.Error == nil && res.RowsAffected == 0 {
+		res = tx.Where("user_id = ?", userID).Order("effective_date").Limit(1).Find(&goal)
+	}
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected != 1 {
+		return nil, ErrNotFound
+	}
+	return goal, nil
+}
+
+// SaveGoal creates g, replacing the goal of the same user
+// with the same effective date if there is one.
+func (r *GoalsGormRepository) SaveGoal(g *models.NutritionGoal) (*models.NutritionGoal, error) {
+	if err := saveGoal(r.db, g); err != nil {
+		return nil, ErrCouldNotCreate
+	}
+	return g, nil
+}
+
+// saveGoal creates g through tx, replacing the goal of the same user
+// with the same effective date if there is one.
+func saveGoal(tx *gorm.DB, g *models.NutritionGoal) error {
+	var existing models.NutritionGoal
+	if err := tx.Where("user_id = ? AND effective_date = ?", g.UserID, g.EffectiveDate).Limit(1).Find(&existing).Error; err != nil {
+		return err
+	}
+	g.ID = existing.ID
+	return tx.Save(g).Error
+}
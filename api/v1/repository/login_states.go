@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"gorm.io/gorm"
+)
+
+type LoginStatesRepository interface {
+	CreateLoginState(*models.LoginState) (*models.LoginState, error)
+	ConsumeLoginState(state string) (*models.LoginState, error)
+	DeleteExpiredLoginStates(now time.Time) error
+	CreateAuthorizationCode(*models.AuthorizationCode) (*models.AuthorizationCode, error)
+	ConsumeAuthorizationCode(codeHash string) (*models.AuthorizationCode, error)
+}
+
+type LoginStatesGormRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginStatesGormRepository(db *gorm.DB) *LoginStatesGormRepository {
+	db.AutoMigrate(&models.LoginState{}, &models.AuthorizationCode{})
+	return &LoginStatesGormRepository{
+		db: db,
+	}
+}
+
+func (r *LoginStatesGormRepository) CreateLoginState(ls *models.LoginState) (*models.LoginState, error) {
+	res := r.db.Create(ls)
+	if res.Error != nil {
+		return nil, ErrCouldNotCreate
+	}
+	return ls, nil
+}
+
+// ConsumeLoginState deletes login state with state, returning it.
+//
+// Returns ErrNotFound if it doesn't exist or was already consumed,
+// even concurrently, so each login state is used only once.
+func (r *LoginStatesGormRepository) ConsumeLoginState(state string) (*models.LoginState, error) {
+	var ls *models.LoginState
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("state = ?", state).First(&ls).Error; err != nil {
+			return err
+		}
+		res := tx.Where("state = ?", state).Delete(&models.LoginState{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected != 1 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+	if err == gorm.ErrRecordNotFound {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return ls, nil
+}
+
+// DeleteExpiredLoginStates deletes every login state
+// and authorization code expired at now.
+func (r *LoginStatesGormRepository) DeleteExpiredLoginStates(now time.Time) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expires_at <= ?", now).Delete(&models.LoginState{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("expires_at <= ?", now).Delete(&models.AuthorizationCode{}).Error
+	})
+	if err != nil {
+		return ErrCouldNotDelete
+	}
+	return nil
+}
+
+func (r *LoginStatesGormRepository) CreateAuthorizationCode(ac *models.AuthorizationCode) (*models.AuthorizationCode, error) {
+	res := r.db.Create(ac)
+	if res.Error != nil {
+		return nil, ErrCouldNotCreate
+	}
+	return ac, nil
+}
+
+// ConsumeAuthorizationCode deletes authorization code with codeHash, returning it.
+//
+// Returns ErrNotFound if it doesn't exist or was already consumed,
+// even concurrently, so each code is exchanged only once.
+func (r *LoginStatesGormRepository) ConsumeAuthorizationCode(codeHash string) (*models.AuthorizationCode, error) {
+	var ac *models.AuthorizationCode
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("code_hash = ?", codeHash).First(&ac).Error; err != nil {
+			return err
+		}
+		res := tx.Where("code_hash = ?", codeHash).Delete(&models.AuthorizationCode{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected != 1 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+	if err == gorm.ErrRecordNotFound {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return ac, nil
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"gorm.io/gorm"
+)
+
+type MeasurementsRepository interface {
+	GetMeasurements(userID uint, from time.Time, to time.Time) ([]models.Measurement, error)
+	GetMeasurement(uint) (*models.Measurement, error)
+	CreateMeasurement(*models.Measurement) (*models.Measurement, error)
+	UpdateMeasurement(*models.Measurement) (*models.Measurement, error)
+	DeleteMeasurement(uint) error
+}
+
+type MeasurementsGormRepository struct {
+	db *gorm.DB
+}
+
+func NewMeasurementsGormRepository(db *gorm.DB) *MeasurementsGormRepository {
+	db.AutoMigrate(&models.Measurement{})
+	return &MeasurementsGormRepository{
+		db: db,
+	}
+}
+
+// GetMeasurements returns the measurements of user taken between from and to,
+// ordered by time. Zero from or to leave that end of the range open.
+func (r *MeasurementsGormRepository) GetMeasurements(userID uint, from time.Time, to time.Time) ([]models.Measurement, error) {
+	var measurements []models.Measurement
+	tx := r.db.Where("user_id = ?", userID)
+	if !from.IsZero() {
+		tx = tx.Where("taken_at >= ?", from)
+	}
+	if !to.IsZero() {
+		tx = tx.Where("taken_at <= ?", to)
+	}
+	res := tx.Order("taken_at").Order("id").Find(&measurements)
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return measurements, nil
+}
+
+func (r *MeasurementsGormRepository) GetMeasurement(id uint) (*models.Measurement, error) {
+	var measurement *models.Measurement
+	res := r.db.First(&measurement, id)
+	if res.Error == gorm.ErrRecordNotFound {
+		return nil, ErrNotFound
+	}
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return measurement, nil
+}
+
+func (r *MeasurementsGormRepository) CreateMeasurement(m *models.Measurement) (*models.Measurement, error) {
+	res := r.db.Create(m)
+	if res.Error != nil {
+		return nil, ErrCouldNotCreate
+	}
+	return m, nil
+}
+
+func (r *MeasurementsGormRepository) UpdateMeasurement(m *models.Measurement) (*models.Measurement, error) {
+	res := r.db.Save(m)
+	if res.Error != nil {
+		return nil, ErrCouldNotUpdate
+	}
+	return m, nil
+}
+
+func (r *MeasurementsGormRepository) DeleteMeasurement(id uint) error {
+	res := r.db.Delete(&models.Measurement{}, id)
+	if res.Error != nil {
+		return ErrCouldNotDelete
+	}
+	if res.RowsAffected != 1 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository/diary.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	models "github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockDiaryRepository is a mock of DiaryRepository interface.
+type MockDiaryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockDiaryRepositoryMockRecorder
+}
+
+// MockDiaryRepositoryMockRecorder is the mock recorder for MockDiaryRepository.
+type MockDiaryRepositoryMockRecorder struct {
+	mock *MockDiaryRepository
+}
+
+// NewMockDiaryRepository creates a new mock instance.
+func NewMockDiaryRepository(ctrl *gomock.Controller) *MockDiaryRepository {
+	mock := &MockDiaryRepository{ctrl: ctrl}
+	mock.recorder = &MockDiaryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDiaryRepository) EXPECT() *MockDiaryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateDiaryEntry mocks base method.
+func (m *MockDiaryRepository) CreateDiaryEntry(arg0 *models.DiaryEntry) (*models.DiaryEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDiaryEntry", arg0)
+	ret0, _ := ret[0].(*models.DiaryEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateDiaryEntry indicates an expected call of CreateDiaryEntry.
+func (mr *MockDiaryRepositoryMockRecorder) CreateDiaryEntry(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDiaryEntry", reflect.TypeOf((*MockDiaryRepository)(nil).CreateDiaryEntry), arg0)
+}
+
+// DeleteDiaryEntry mocks base method.
+func (m *MockDiaryRepository) DeleteDiaryEntry(arg0 uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDiaryEntry", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDiaryEntry indicates an expected call of DeleteDiaryEntry.
+func (mr *MockDiaryRepositoryMockRecorder) DeleteDiaryEntry(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDiaryEntry", reflect.TypeOf((*MockDiaryRepository)(nil).DeleteDiaryEntry), arg0)
+}
+
+// GetDiaryEntries mocks base method.
+func (m *MockDiaryRepository) GetDiaryEntries(userID uint, date string) ([]models.DiaryEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDiaryEntries", userID, date)
+	ret0, _ := ret[0].([]models.DiaryEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDiaryEntries indicates an expected call of GetDiaryEntries.
+func (mr *MockDiaryRepositoryMockRecorder) GetDiaryEntries(userID, date interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDiaryEntries", reflect.TypeOf((*MockDiaryRepository)(nil).GetDiaryEntries), userID, date)
+}
+
+// GetDiaryEntriesBetween mocks base method.
+func (m *MockDiaryRepository) GetDiaryEntriesBetween(userID uint, from, to string) ([]models.DiaryEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDiaryEntriesBetween", userID, from, to)
+	ret0, _ := ret[0].([]models.DiaryEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDiaryEntriesBetween indicates an expected call of GetDiaryEntriesBetween.
+func (mr *MockDiaryRepositoryMockRecorder) GetDiaryEntriesBetween(userID, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDiaryEntriesBetween", reflect.TypeOf((*MockDiaryRepository)(nil).GetDiaryEntriesBetween), userID, from, to)
+}
+
+// GetDiaryEntry mocks base method.
+func (m *MockDiaryRepository) GetDiaryEntry(arg0 uint) (*models.DiaryEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDiaryEntry", arg0)
+	ret0, _ := ret[0].(*models.DiaryEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDiaryEntry indicates an expected call of GetDiaryEntry.
+func (mr *MockDiaryRepositoryMockRecorder) GetDiaryEntry(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDiaryEntry", reflect.TypeOf((*MockDiaryRepository)(nil).GetDiaryEntry), arg0)
+}
+
+// UpdateDiaryEntry mocks base method.
+func (m *MockDiaryRepository) UpdateDiaryEntry(arg0 *models.DiaryEntry) (*models.DiaryEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDiaryEntry", arg0)
+	ret0, _ := ret[0].(*models.DiaryEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateDiaryEntry indicates an expected call of UpdateDiaryEntry.
+func (mr *MockDiaryRepositoryMockRecorder) UpdateDiaryEntry(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDiaryEntry", reflect.TypeOf((*MockDiaryRepository)(nil).UpdateDiaryEntry), arg0)
+}
@@ -0,0 +1,94 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository/email_verifications.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+	time "time"
+
+	models "github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockEmailVerificationsRepository is a mock of EmailVerificationsRepository interface.
+type MockEmailVerificationsRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockEmailVerificationsRepositoryMockRecorder
+}
+
+// MockEmailVerificationsRepositoryMockRecorder is the mock recorder for MockEmailVerificationsRepository.
+type MockEmailVerificationsRepositoryMockRecorder struct {
+	mock *MockEmailVerificationsRepository
+}
+
+// NewMockEmailVerificationsRepository creates a new mock instance.
+func NewMockEmailVerificationsRepository(ctrl *gomock.Controller) *MockEmailVerificationsRepository {
+	mock := &MockEmailVerificationsRepository{ctrl: ctrl}
+	mock.recorder = &MockEmailVerificationsRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEmailVerificationsRepository) EXPECT() *MockEmailVerificationsRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateEmailVerificationToken mocks base method.
+func (m *MockEmailVerificationsRepository) CreateEmailVerificationToken(arg0 *models.EmailVerificationToken) (*models.EmailVerificationToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEmailVerificationToken", arg0)
+	ret0, _ := ret[0].(*models.EmailVerificationToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateEmailVerificationToken indicates an expected call of CreateEmailVerificationToken.
+func (mr *MockEmailVerificationsRepositoryMockRecorder) CreateEmailVerificationToken(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEmailVerificationToken", reflect.TypeOf((*MockEmailVerificationsRepository)(nil).CreateEmailVerificationToken), arg0)
+}
+
+// DeleteEmailVerificationTokens mocks base method.
+func (m *MockEmailVerificationsRepository) DeleteEmailVerificationTokens(userID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteEmailVerificationTokens", userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteEmailVerificationTokens indicates an expected call of DeleteEmailVerificationTokens.
+func (mr *MockEmailVerificationsRepositoryMockRecorder) DeleteEmailVerificationTokens(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEmailVerificationTokens", reflect.TypeOf((*MockEmailVerificationsRepository)(nil).DeleteEmailVerificationTokens), userID)
+}
+
+// DeleteExpiredEmailVerificationTokens mocks base method.
+func (m *MockEmailVerificationsRepository) DeleteExpiredEmailVerificationTokens(now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredEmailVerificationTokens", now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpiredEmailVerificationTokens indicates an expected call of DeleteExpiredEmailVerificationTokens.
+func (mr *MockEmailVerificationsRepositoryMockRecorder) DeleteExpiredEmailVerificationTokens(now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredEmailVerificationTokens", reflect.TypeOf((*MockEmailVerificationsRepository)(nil).DeleteExpiredEmailVerificationTokens), now)
+}
+
+// GetEmailVerificationTokenByHash mocks base method.
+func (m *MockEmailVerificationsRepository) GetEmailVerificationTokenByHash(arg0 string) (*models.EmailVerificationToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmailVerificationTokenByHash", arg0)
+	ret0, _ := ret[0].(*models.EmailVerificationToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmailVerificationTokenByHash indicates an expected call of GetEmailVerificationTokenByHash.
+func (mr *MockEmailVerificationsRepositoryMockRecorder) GetEmailVerificationTokenByHash(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmailVerificationTokenByHash", reflect.TypeOf((*MockEmailVerificationsRepository)(nil).GetEmailVerificationTokenByHash), arg0)
+}
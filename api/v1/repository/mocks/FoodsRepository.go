@@ -0,0 +1,110 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository/foods.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	models "github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockFoodsRepository is a mock of FoodsRepository interface.
+type MockFoodsRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockFoodsRepositoryMockRecorder
+}
+
+// MockFoodsRepositoryMockRecorder is the mock recorder for MockFoodsRepository.
+type MockFoodsRepositoryMockRecorder struct {
+	mock *MockFoodsRepository
+}
+
+// NewMockFoodsRepository creates a new mock instance.
+func NewMockFoodsRepository(ctrl *gomock.Controller) *MockFoodsRepository {
+	mock := &MockFoodsRepository{ctrl: ctrl}
+	mock.recorder = &MockFoodsRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFoodsRepository) EXPECT() *MockFoodsRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateFood mocks base method.
+func (m *MockFoodsRepository) CreateFood(arg0 *models.Food) (*models.Food, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFood", arg0)
+	ret0, _ := ret[0].(*models.Food)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFood indicates an expected call of CreateFood.
+func (mr *MockFoodsRepositoryMockRecorder) CreateFood(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFood", reflect.TypeOf((*MockFoodsRepository)(nil).CreateFood), arg0)
+}
+
+// DeleteFood mocks base method.
+func (m *MockFoodsRepository) DeleteFood(arg0 uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFood", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFood indicates an expected call of DeleteFood.
+func (mr *MockFoodsRepositoryMockRecorder) DeleteFood(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFood", reflect.TypeOf((*MockFoodsRepository)(nil).DeleteFood), arg0)
+}
+
+// GetFood mocks base method.
+func (m *MockFoodsRepository) GetFood(arg0 uint) (*models.Food, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFood", arg0)
+	ret0, _ := ret[0].(*models.Food)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFood indicates an expected call of GetFood.
+func (mr *MockFoodsRepositoryMockRecorder) GetFood(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFood", reflect.TypeOf((*MockFoodsRepository)(nil).GetFood), arg0)
+}
+
+// SearchFoods mocks base method.
+func (m *MockFoodsRepository) SearchFoods(query string, offset, limit int) ([]models.Food, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchFoods", query, offset, limit)
+	ret0, _ := ret[0].([]models.Food)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SearchFoods indicates an expected call of SearchFoods.
+func (mr *MockFoodsRepositoryMockRecorder) SearchFoods(query, offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchFoods", reflect.TypeOf((*MockFoodsRepository)(nil).SearchFoods), query, offset, limit)
+}
+
+// UpdateFood mocks base method.
+func (m *MockFoodsRepository) UpdateFood(arg0 *models.Food) (*models.Food, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFood", arg0)
+	ret0, _ := ret[0].(*models.Food)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateFood indicates an expected call of UpdateFood.
+func (mr *MockFoodsRepositoryMockRecorder) UpdateFood(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFood", reflect.TypeOf((*MockFoodsRepository)(nil).UpdateFood), arg0)
+}
@@ -0,0 +1,80 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository/goals.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	models "github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockGoalsRepository is a mock of GoalsRepository interface.
+type MockGoalsRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockGoalsRepositoryMockRecorder
+}
+
+// MockGoalsRepositoryMockRecorder is the mock recorder for MockGoalsRepository.
+type MockGoalsRepositoryMockRecorder struct {
+	mock *MockGoalsRepository
+}
+
+// NewMockGoalsRepository creates a new mock instance.
+func NewMockGoalsRepository(ctrl *gomock.Controller) *MockGoalsRepository {
+	mock := &MockGoalsRepository{ctrl: ctrl}
+	mock.recorder = &MockGoalsRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGoalsRepository) EXPECT() *MockGoalsRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetGoalAt mocks base method.
+func (m *MockGoalsRepository) GetGoalAt(userID uint, date string) (*models.NutritionGoal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGoalAt", userID, date)
+	ret0, _ := ret[0].(*models.NutritionGoal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGoalAt indicates an expected call of GetGoalAt.
+func (mr *MockGoalsRepositoryMockRecorder) GetGoalAt(userID, date interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGoalAt", reflect.TypeOf((*MockGoalsRepository)(nil).GetGoalAt), userID, date)
+}
+
+// GetGoals mocks base method.
+func (m *MockGoalsRepository) GetGoals(userID uint) ([]models.NutritionGoal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGoals", userID)
+	ret0, _ := ret[0].([]models.NutritionGoal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGoals indicates an expected call of GetGoals.
+func (mr *MockGoalsRepositoryMockRecorder) GetGoals(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGoals", reflect.TypeOf((*MockGoalsRepository)(nil).GetGoals), userID)
+}
+
+// SaveGoal mocks base method.
+func (m *MockGoalsRepository) SaveGoal(arg0 *models.NutritionGoal) (*models.NutritionGoal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveGoal", arg0)
+	ret0, _ := ret[0].(*models.NutritionGoal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveGoal indicates an expected call of SaveGoal.
+func (mr *MockGoalsRepositoryMockRecorder) SaveGoal(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveGoal", reflect.TypeOf((*MockGoalsRepository)(nil).SaveGoal), arg0)
+}
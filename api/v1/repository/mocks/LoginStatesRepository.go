@@ -0,0 +1,110 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository/login_states.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+	time "time"
+
+	models "github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockLoginStatesRepository is a mock of LoginStatesRepository interface.
+type MockLoginStatesRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginStatesRepositoryMockRecorder
+}
+
+// MockLoginStatesRepositoryMockRecorder is the mock recorder for MockLoginStatesRepository.
+type MockLoginStatesRepositoryMockRecorder struct {
+	mock *MockLoginStatesRepository
+}
+
+// NewMockLoginStatesRepository creates a new mock instance.
+func NewMockLoginStatesRepository(ctrl *gomock.Controller) *MockLoginStatesRepository {
+	mock := &MockLoginStatesRepository{ctrl: ctrl}
+	mock.recorder = &MockLoginStatesRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginStatesRepository) EXPECT() *MockLoginStatesRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ConsumeAuthorizationCode mocks base method.
+func (m *MockLoginStatesRepository) ConsumeAuthorizationCode(codeHash string) (*models.AuthorizationCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeAuthorizationCode", codeHash)
+	ret0, _ := ret[0].(*models.AuthorizationCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeAuthorizationCode indicates an expected call of ConsumeAuthorizationCode.
+func (mr *MockLoginStatesRepositoryMockRecorder) ConsumeAuthorizationCode(codeHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeAuthorizationCode", reflect.TypeOf((*MockLoginStatesRepository)(nil).ConsumeAuthorizationCode), codeHash)
+}
+
+// ConsumeLoginState mocks base method.
+func (m *MockLoginStatesRepository) ConsumeLoginState(state string) (*models.LoginState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeLoginState", state)
+	ret0, _ := ret[0].(*models.LoginState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeLoginState indicates an expected call of ConsumeLoginState.
+func (mr *MockLoginStatesRepositoryMockRecorder) ConsumeLoginState(state interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeLoginState", reflect.TypeOf((*MockLoginStatesRepository)(nil).ConsumeLoginState), state)
+}
+
+// CreateAuthorizationCode mocks base method.
+func (m *MockLoginStatesRepository) CreateAuthorizationCode(arg0 *models.AuthorizationCode) (*models.AuthorizationCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuthorizationCode", arg0)
+	ret0, _ := ret[0].(*models.AuthorizationCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAuthorizationCode indicates an expected call of CreateAuthorizationCode.
+func (mr *MockLoginStatesRepositoryMockRecorder) CreateAuthorizationCode(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuthorizationCode", reflect.TypeOf((*MockLoginStatesRepository)(nil).CreateAuthorizationCode), arg0)
+}
+
+// CreateLoginState mocks base method.
+func (m *MockLoginStatesRepository) CreateLoginState(arg0 *models.LoginState) (*models.LoginState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLoginState", arg0)
+	ret0, _ := ret[0].(*models.LoginState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateLoginState indicates an expected call of CreateLoginState.
+func (mr *MockLoginStatesRepositoryMockRecorder) CreateLoginState(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLoginState", reflect.TypeOf((*MockLoginStatesRepository)(nil).CreateLoginState), arg0)
+}
+
+// DeleteExpiredLoginStates mocks base method.
+func (m *MockLoginStatesRepository) DeleteExpiredLoginStates(now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredLoginStates", now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpiredLoginStates indicates an expected call of DeleteExpiredLoginStates.
+func (mr *MockLoginStatesRepositoryMockRecorder) DeleteExpiredLoginStates(now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredLoginStates", reflect.TypeOf((*MockLoginStatesRepository)(nil).DeleteExpiredLoginStates), now)
+}
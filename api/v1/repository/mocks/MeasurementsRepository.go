@@ -0,0 +1,110 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository/measurements.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+	time "time"
+
+	models "github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockMeasurementsRepository is a mock of MeasurementsRepository interface.
+type MockMeasurementsRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockMeasurementsRepositoryMockRecorder
+}
+
+// MockMeasurementsRepositoryMockRecorder is the mock recorder for MockMeasurementsRepository.
+type MockMeasurementsRepositoryMockRecorder struct {
+	mock *MockMeasurementsRepository
+}
+
+// NewMockMeasurementsRepository creates a new mock instance.
+func NewMockMeasurementsRepository(ctrl *gomock.Controller) *MockMeasurementsRepository {
+	mock := &MockMeasurementsRepository{ctrl: ctrl}
+	mock.recorder = &MockMeasurementsRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMeasurementsRepository) EXPECT() *MockMeasurementsRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateMeasurement mocks base method.
+func (m *MockMeasurementsRepository) CreateMeasurement(arg0 *models.Measurement) (*models.Measurement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMeasurement", arg0)
+	ret0, _ := ret[0].(*models.Measurement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMeasurement indicates an expected call of CreateMeasurement.
+func (mr *MockMeasurementsRepositoryMockRecorder) CreateMeasurement(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMeasurement", reflect.TypeOf((*MockMeasurementsRepository)(nil).CreateMeasurement), arg0)
+}
+
+// DeleteMeasurement mocks base method.
+func (m *MockMeasurementsRepository) DeleteMeasurement(arg0 uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMeasurement", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteMeasurement indicates an expected call of DeleteMeasurement.
+func (mr *MockMeasurementsRepositoryMockRecorder) DeleteMeasurement(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMeasurement", reflect.TypeOf((*MockMeasurementsRepository)(nil).DeleteMeasurement), arg0)
+}
+
+// GetMeasurement mocks base method.
+func (m *MockMeasurementsRepository) GetMeasurement(arg0 uint) (*models.Measurement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMeasurement", arg0)
+	ret0, _ := ret[0].(*models.Measurement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMeasurement indicates an expected call of GetMeasurement.
+func (mr *MockMeasurementsRepositoryMockRecorder) GetMeasurement(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMeasurement", reflect.TypeOf((*MockMeasurementsRepository)(nil).GetMeasurement), arg0)
+}
+
+// GetMeasurements mocks base method.
+func (m *MockMeasurementsRepository) GetMeasurements(userID uint, from, to time.Time) ([]models.Measurement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMeasurements", userID, from, to)
+	ret0, _ := ret[0].([]models.Measurement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMeasurements indicates an expected call of GetMeasurements.
+func (mr *MockMeasurementsRepositoryMockRecorder) GetMeasurements(userID, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMeasurements", reflect.TypeOf((*MockMeasurementsRepository)(nil).GetMeasurements), userID, from, to)
+}
+
+// UpdateMeasurement mocks base method.
+func (m *MockMeasurementsRepository) UpdateMeasurement(arg0 *models.Measurement) (*models.Measurement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMeasurement", arg0)
+	ret0, _ := ret[0].(*models.Measurement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateMeasurement indicates an expected call of UpdateMeasurement.
+func (mr *MockMeasurementsRepositoryMockRecorder) UpdateMeasurement(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMeasurement", reflect.TypeOf((*MockMeasurementsRepository)(nil).UpdateMeasurement), arg0)
+}
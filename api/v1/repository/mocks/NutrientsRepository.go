@@ -0,0 +1,95 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository/nutrients.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	models "github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockNutrientsRepository is a mock of NutrientsRepository interface.
+type MockNutrientsRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockNutrientsRepositoryMockRecorder
+}
+
+// MockNutrientsRepositoryMockRecorder is the mock recorder for MockNutrientsRepository.
+type MockNutrientsRepositoryMockRecorder struct {
+	mock *MockNutrientsRepository
+}
+
+// NewMockNutrientsRepository creates a new mock instance.
+func NewMockNutrientsRepository(ctrl *gomock.Controller) *MockNutrientsRepository {
+	mock := &MockNutrientsRepository{ctrl: ctrl}
+	mock.recorder = &MockNutrientsRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNutrientsRepository) EXPECT() *MockNutrientsRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateNutrient mocks base method.
+func (m *MockNutrientsRepository) CreateNutrient(arg0 *models.Nutrient) (*models.Nutrient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateNutrient", arg0)
+	ret0, _ := ret[0].(*models.Nutrient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateNutrient indicates an expected call of CreateNutrient.
+func (mr *MockNutrientsRepositoryMockRecorder) CreateNutrient(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNutrient", reflect.TypeOf((*MockNutrientsRepository)(nil).CreateNutrient), arg0)
+}
+
+// GetNutrient mocks base method.
+func (m *MockNutrientsRepository) GetNutrient(key string) (*models.Nutrient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNutrient", key)
+	ret0, _ := ret[0].(*models.Nutrient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNutrient indicates an expected call of GetNutrient.
+func (mr *MockNutrientsRepositoryMockRecorder) GetNutrient(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNutrient", reflect.TypeOf((*MockNutrientsRepository)(nil).GetNutrient), key)
+}
+
+// GetNutrients mocks base method.
+func (m *MockNutrientsRepository) GetNutrients() ([]models.Nutrient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNutrients")
+	ret0, _ := ret[0].([]models.Nutrient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNutrients indicates an expected call of GetNutrients.
+func (mr *MockNutrientsRepositoryMockRecorder) GetNutrients() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNutrients", reflect.TypeOf((*MockNutrientsRepository)(nil).GetNutrients))
+}
+
+// UpdateNutrient mocks base method.
+func (m *MockNutrientsRepository) UpdateNutrient(arg0 *models.Nutrient) (*models.Nutrient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateNutrient", arg0)
+	ret0, _ := ret[0].(*models.Nutrient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateNutrient indicates an expected call of UpdateNutrient.
+func (mr *MockNutrientsRepositoryMockRecorder) UpdateNutrient(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNutrient", reflect.TypeOf((*MockNutrientsRepository)(nil).UpdateNutrient), arg0)
+}
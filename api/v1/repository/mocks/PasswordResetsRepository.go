@@ -0,0 +1,94 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository/password_resets.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+	time "time"
+
+	models "github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPasswordResetsRepository is a mock of PasswordResetsRepository interface.
+type MockPasswordResetsRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasswordResetsRepositoryMockRecorder
+}
+
+// MockPasswordResetsRepositoryMockRecorder is the mock recorder for MockPasswordResetsRepository.
+type MockPasswordResetsRepositoryMockRecorder struct {
+	mock *MockPasswordResetsRepository
+}
+
+// NewMockPasswordResetsRepository creates a new mock instance.
+func NewMockPasswordResetsRepository(ctrl *gomock.Controller) *MockPasswordResetsRepository {
+	mock := &MockPasswordResetsRepository{ctrl: ctrl}
+	mock.recorder = &MockPasswordResetsRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPasswordResetsRepository) EXPECT() *MockPasswordResetsRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreatePasswordResetToken mocks base method.
+func (m *MockPasswordResetsRepository) CreatePasswordResetToken(arg0 *models.PasswordResetToken) (*models.PasswordResetToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePasswordResetToken", arg0)
+	ret0, _ := ret[0].(*models.PasswordResetToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePasswordResetToken indicates an expected call of CreatePasswordResetToken.
+func (mr *MockPasswordResetsRepositoryMockRecorder) CreatePasswordResetToken(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePasswordResetToken", reflect.TypeOf((*MockPasswordResetsRepository)(nil).CreatePasswordResetToken), arg0)
+}
+
+// DeleteExpiredPasswordResetTokens mocks base method.
+func (m *MockPasswordResetsRepository) DeleteExpiredPasswordResetTokens(now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredPasswordResetTokens", now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpiredPasswordResetTokens indicates an expected call of DeleteExpiredPasswordResetTokens.
+func (mr *MockPasswordResetsRepositoryMockRecorder) DeleteExpiredPasswordResetTokens(now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredPasswordResetTokens", reflect.TypeOf((*MockPasswordResetsRepository)(nil).DeleteExpiredPasswordResetTokens), now)
+}
+
+// DeletePasswordResetTokens mocks base method.
+func (m *MockPasswordResetsRepository) DeletePasswordResetTokens(userID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePasswordResetTokens", userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePasswordResetTokens indicates an expected call of DeletePasswordResetTokens.
+func (mr *MockPasswordResetsRepositoryMockRecorder) DeletePasswordResetTokens(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePasswordResetTokens", reflect.TypeOf((*MockPasswordResetsRepository)(nil).DeletePasswordResetTokens), userID)
+}
+
+// GetPasswordResetTokenByHash mocks base method.
+func (m *MockPasswordResetsRepository) GetPasswordResetTokenByHash(arg0 string) (*models.PasswordResetToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPasswordResetTokenByHash", arg0)
+	ret0, _ := ret[0].(*models.PasswordResetToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPasswordResetTokenByHash indicates an expected call of GetPasswordResetTokenByHash.
+func (mr *MockPasswordResetsRepositoryMockRecorder) GetPasswordResetTokenByHash(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPasswordResetTokenByHash", reflect.TypeOf((*MockPasswordResetsRepository)(nil).GetPasswordResetTokenByHash), arg0)
+}
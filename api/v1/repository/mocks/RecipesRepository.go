@@ -0,0 +1,124 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository/recipes.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	models "github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockRecipesRepository is a mock of RecipesRepository interface.
+type MockRecipesRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRecipesRepositoryMockRecorder
+}
+
+// MockRecipesRepositoryMockRecorder is the mock recorder for MockRecipesRepository.
+type MockRecipesRepositoryMockRecorder struct {
+	mock *MockRecipesRepository
+}
+
+// NewMockRecipesRepository creates a new mock instance.
+func NewMockRecipesRepository(ctrl *gomock.Controller) *MockRecipesRepository {
+	mock := &MockRecipesRepository{ctrl: ctrl}
+	mock.recorder = &MockRecipesRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRecipesRepository) EXPECT() *MockRecipesRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateRecipe mocks base method.
+func (m *MockRecipesRepository) CreateRecipe(arg0 *models.Recipe) (*models.Recipe, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRecipe", arg0)
+	ret0, _ := ret[0].(*models.Recipe)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRecipe indicates an expected call of CreateRecipe.
+func (mr *MockRecipesRepositoryMockRecorder) CreateRecipe(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRecipe", reflect.TypeOf((*MockRecipesRepository)(nil).CreateRecipe), arg0)
+}
+
+// DeleteRecipe mocks base method.
+func (m *MockRecipesRepository) DeleteRecipe(arg0 uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRecipe", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRecipe indicates an expected call of DeleteRecipe.
+func (mr *MockRecipesRepositoryMockRecorder) DeleteRecipe(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRecipe", reflect.TypeOf((*MockRecipesRepository)(nil).DeleteRecipe), arg0)
+}
+
+// GetAllRecipes mocks base method.
+func (m *MockRecipesRepository) GetAllRecipes() ([]models.Recipe, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllRecipes")
+	ret0, _ := ret[0].([]models.Recipe)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllRecipes indicates an expected call of GetAllRecipes.
+func (mr *MockRecipesRepositoryMockRecorder) GetAllRecipes() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllRecipes", reflect.TypeOf((*MockRecipesRepository)(nil).GetAllRecipes))
+}
+
+// GetRecipe mocks base method.
+func (m *MockRecipesRepository) GetRecipe(arg0 uint) (*models.Recipe, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecipe", arg0)
+	ret0, _ := ret[0].(*models.Recipe)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecipe indicates an expected call of GetRecipe.
+func (mr *MockRecipesRepositoryMockRecorder) GetRecipe(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecipe", reflect.TypeOf((*MockRecipesRepository)(nil).GetRecipe), arg0)
+}
+
+// GetUserRecipes mocks base method.
+func (m *MockRecipesRepository) GetUserRecipes(userID uint, addedIDs []uint) ([]models.Recipe, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserRecipes", userID, addedIDs)
+	ret0, _ := ret[0].([]models.Recipe)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserRecipes indicates an expected call of GetUserRecipes.
+func (mr *MockRecipesRepositoryMockRecorder) GetUserRecipes(userID, addedIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserRecipes", reflect.TypeOf((*MockRecipesRepository)(nil).GetUserRecipes), userID, addedIDs)
+}
+
+// UpdateRecipe mocks base method.
+func (m *MockRecipesRepository) UpdateRecipe(arg0 *models.Recipe) (*models.Recipe, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRecipe", arg0)
+	ret0, _ := ret[0].(*models.Recipe)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateRecipe indicates an expected call of UpdateRecipe.
+func (mr *MockRecipesRepositoryMockRecorder) UpdateRecipe(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRecipe", reflect.TypeOf((*MockRecipesRepository)(nil).UpdateRecipe), arg0)
+}
@@ -0,0 +1,197 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository/sessions.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+	time "time"
+
+	models "github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockSessionsRepository is a mock of SessionsRepository interface.
+type MockSessionsRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSessionsRepositoryMockRecorder
+}
+
+// MockSessionsRepositoryMockRecorder is the mock recorder for MockSessionsRepository.
+type MockSessionsRepositoryMockRecorder struct {
+	mock *MockSessionsRepository
+}
+
+// NewMockSessionsRepository creates a new mock instance.
+func NewMockSessionsRepository(ctrl *gomock.Controller) *MockSessionsRepository {
+	mock := &MockSessionsRepository{ctrl: ctrl}
+	mock.recorder = &MockSessionsRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSessionsRepository) EXPECT() *MockSessionsRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateRefreshToken mocks base method.
+func (m *MockSessionsRepository) CreateRefreshToken(arg0 *models.RefreshToken) (*models.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRefreshToken", arg0)
+	ret0, _ := ret[0].(*models.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRefreshToken indicates an expected call of CreateRefreshToken.
+func (mr *MockSessionsRepositoryMockRecorder) CreateRefreshToken(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefreshToken", reflect.TypeOf((*MockSessionsRepository)(nil).CreateRefreshToken), arg0)
+}
+
+// CreateSession mocks base method.
+func (m *MockSessionsRepository) CreateSession(arg0 *models.Session) (*models.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSession", arg0)
+	ret0, _ := ret[0].(*models.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSession indicates an expected call of CreateSession.
+func (mr *MockSessionsRepositoryMockRecorder) CreateSession(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockSessionsRepository)(nil).CreateSession), arg0)
+}
+
+// DeleteExpiredSessions mocks base method.
+func (m *MockSessionsRepository) DeleteExpiredSessions(now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredSessions", now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpiredSessions indicates an expected call of DeleteExpiredSessions.
+func (mr *MockSessionsRepositoryMockRecorder) DeleteExpiredSessions(now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredSessions", reflect.TypeOf((*MockSessionsRepository)(nil).DeleteExpiredSessions), now)
+}
+
+// DeleteSession mocks base method.
+func (m *MockSessionsRepository) DeleteSession(arg0 uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSession", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSession indicates an expected call of DeleteSession.
+func (mr *MockSessionsRepositoryMockRecorder) DeleteSession(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSession", reflect.TypeOf((*MockSessionsRepository)(nil).DeleteSession), arg0)
+}
+
+// DeleteUserSessions mocks base method.
+func (m *MockSessionsRepository) DeleteUserSessions(userID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserSessions", userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserSessions indicates an expected call of DeleteUserSessions.
+func (mr *MockSessionsRepositoryMockRecorder) DeleteUserSessions(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserSessions", reflect.TypeOf((*MockSessionsRepository)(nil).DeleteUserSessions), userID)
+}
+
+// GetRefreshTokenByHash mocks base method.
+func (m *MockSessionsRepository) GetRefreshTokenByHash(arg0 string) (*models.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefreshTokenByHash", arg0)
+	ret0, _ := ret[0].(*models.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefreshTokenByHash indicates an expected call of GetRefreshTokenByHash.
+func (mr *MockSessionsRepositoryMockRecorder) GetRefreshTokenByHash(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefreshTokenByHash", reflect.TypeOf((*MockSessionsRepository)(nil).GetRefreshTokenByHash), arg0)
+}
+
+// GetSession mocks base method.
+func (m *MockSessionsRepository) GetSession(arg0 uint) (*models.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSession", arg0)
+	ret0, _ := ret[0].(*models.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSession indicates an expected call of GetSession.
+func (mr *MockSessionsRepositoryMockRecorder) GetSession(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockSessionsRepository)(nil).GetSession), arg0)
+}
+
+// GetSessionByTokenHash mocks base method.
+func (m *MockSessionsRepository) GetSessionByTokenHash(arg0 string) (*models.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessionByTokenHash", arg0)
+	ret0, _ := ret[0].(*models.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessionByTokenHash indicates an expected call of GetSessionByTokenHash.
+func (mr *MockSessionsRepositoryMockRecorder) GetSessionByTokenHash(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionByTokenHash", reflect.TypeOf((*MockSessionsRepository)(nil).GetSessionByTokenHash), arg0)
+}
+
+// GetSessions mocks base method.
+func (m *MockSessionsRepository) GetSessions(userID uint) ([]models.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessions", userID)
+	ret0, _ := ret[0].([]models.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessions indicates an expected call of GetSessions.
+func (mr *MockSessionsRepositoryMockRecorder) GetSessions(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessions", reflect.TypeOf((*MockSessionsRepository)(nil).GetSessions), userID)
+}
+
+// RotateRefreshToken mocks base method.
+func (m *MockSessionsRepository) RotateRefreshToken(used, next *models.RefreshToken, s *models.Session) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateRefreshToken", used, next, s)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RotateRefreshToken indicates an expected call of RotateRefreshToken.
+func (mr *MockSessionsRepositoryMockRecorder) RotateRefreshToken(used, next, s interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateRefreshToken", reflect.TypeOf((*MockSessionsRepository)(nil).RotateRefreshToken), used, next, s)
+}
+
+// UpdateSession mocks base method.
+func (m *MockSessionsRepository) UpdateSession(arg0 *models.Session) (*models.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSession", arg0)
+	ret0, _ := ret[0].(*models.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateSession indicates an expected call of UpdateSession.
+func (mr *MockSessionsRepositoryMockRecorder) UpdateSession(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSession", reflect.TypeOf((*MockSessionsRepository)(nil).UpdateSession), arg0)
+}
@@ -6,8 +6,10 @@ package mocks
 
 import (
 	reflect "reflect"
+	time "time"
 
 	models "github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	repository "github.com/JonathanGzzBen/nutrity-api/api/v1/repository"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -49,19 +51,62 @@ func (mr *MockUsersRepositoryMockRecorder) CreateUser(arg0 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUsersRepository)(nil).CreateUser), arg0)
 }
 
-// GetAllUsers mocks base method.
-func (m *MockUsersRepository) GetAllUsers() ([]models.User, error) {
+// CreateUserIdentity mocks base method.
+func (m *MockUsersRepository) CreateUserIdentity(arg0 *models.UserIdentity) (*models.UserIdentity, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllUsers")
+	ret := m.ctrl.Call(m, "CreateUserIdentity", arg0)
+	ret0, _ := ret[0].(*models.UserIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUserIdentity indicates an expected call of CreateUserIdentity.
+func (mr *MockUsersRepositoryMockRecorder) CreateUserIdentity(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserIdentity", reflect.TypeOf((*MockUsersRepository)(nil).CreateUserIdentity), arg0)
+}
+
+// DeleteUser mocks base method.
+func (m *MockUsersRepository) DeleteUser(arg0 uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockUsersRepositoryMockRecorder) DeleteUser(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockUsersRepository)(nil).DeleteUser), arg0)
+}
+
+// DeleteUserIdentity mocks base method.
+func (m *MockUsersRepository) DeleteUserIdentity(arg0 uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserIdentity", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserIdentity indicates an expected call of DeleteUserIdentity.
+func (mr *MockUsersRepositoryMockRecorder) DeleteUserIdentity(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserIdentity", reflect.TypeOf((*MockUsersRepository)(nil).DeleteUserIdentity), arg0)
+}
+
+// FindUsers mocks base method.
+func (m *MockUsersRepository) FindUsers(arg0 repository.UsersQuery) ([]models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUsers", arg0)
 	ret0, _ := ret[0].([]models.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAllUsers indicates an expected call of GetAllUsers.
-func (mr *MockUsersRepositoryMockRecorder) GetAllUsers() *gomock.Call {
+// FindUsers indicates an expected call of FindUsers.
+func (mr *MockUsersRepositoryMockRecorder) FindUsers(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllUsers", reflect.TypeOf((*MockUsersRepository)(nil).GetAllUsers))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUsers", reflect.TypeOf((*MockUsersRepository)(nil).FindUsers), arg0)
 }
 
 // GetUser mocks base method.
@@ -79,34 +124,151 @@ func (mr *MockUsersRepositoryMockRecorder) GetUser(arg0 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockUsersRepository)(nil).GetUser), arg0)
 }
 
-// GetUserByAccessToken mocks base method.
-func (m *MockUsersRepository) GetUserByAccessToken(arg0 string) (*models.User, error) {
+// GetUserByEmail mocks base method.
+func (m *MockUsersRepository) GetUserByEmail(arg0 string) (*models.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserByAccessToken", arg0)
+	ret := m.ctrl.Call(m, "GetUserByEmail", arg0)
 	ret0, _ := ret[0].(*models.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUserByAccessToken indicates an expected call of GetUserByAccessToken.
-func (mr *MockUsersRepositoryMockRecorder) GetUserByAccessToken(arg0 interface{}) *gomock.Call {
+// GetUserByEmail indicates an expected call of GetUserByEmail.
+func (mr *MockUsersRepositoryMockRecorder) GetUserByEmail(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByAccessToken", reflect.TypeOf((*MockUsersRepository)(nil).GetUserByAccessToken), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmail", reflect.TypeOf((*MockUsersRepository)(nil).GetUserByEmail), arg0)
 }
 
-// GetUserByGoogleSub mocks base method.
-func (m *MockUsersRepository) GetUserByGoogleSub(arg0 string) (*models.User, error) {
+// GetUserByIdentity mocks base method.
+func (m *MockUsersRepository) GetUserByIdentity(provider, subject string) (*models.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserByGoogleSub", arg0)
+	ret := m.ctrl.Call(m, "GetUserByIdentity", provider, subject)
 	ret0, _ := ret[0].(*models.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUserByGoogleSub indicates an expected call of GetUserByGoogleSub.
-func (mr *MockUsersRepositoryMockRecorder) GetUserByGoogleSub(arg0 interface{}) *gomock.Call {
+// GetUserByIdentity indicates an expected call of GetUserByIdentity.
+func (mr *MockUsersRepositoryMockRecorder) GetUserByIdentity(provider, subject interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByIdentity", reflect.TypeOf((*MockUsersRepository)(nil).GetUserByIdentity), provider, subject)
+}
+
+// GetUserByUsername mocks base method.
+func (m *MockUsersRepository) GetUserByUsername(arg0 string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByUsername", arg0)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByUsername indicates an expected call of GetUserByUsername.
+func (mr *MockUsersRepositoryMockRecorder) GetUserByUsername(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUsername", reflect.TypeOf((*MockUsersRepository)(nil).GetUserByUsername), arg0)
+}
+
+// GetUserIdentities mocks base method.
+func (m *MockUsersRepository) GetUserIdentities(userID uint) ([]models.UserIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserIdentities", userID)
+	ret0, _ := ret[0].([]models.UserIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserIdentities indicates an expected call of GetUserIdentities.
+func (mr *MockUsersRepositoryMockRecorder) GetUserIdentities(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserIdentities", reflect.TypeOf((*MockUsersRepository)(nil).GetUserIdentities), userID)
+}
+
+// GetUserIdentity mocks base method.
+func (m *MockUsersRepository) GetUserIdentity(arg0 uint) (*models.UserIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserIdentity", arg0)
+	ret0, _ := ret[0].(*models.UserIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserIdentity indicates an expected call of GetUserIdentity.
+func (mr *MockUsersRepositoryMockRecorder) GetUserIdentity(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByGoogleSub", reflect.TypeOf((*MockUsersRepository)(nil).GetUserByGoogleSub), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserIdentity", reflect.TypeOf((*MockUsersRepository)(nil).GetUserIdentity), arg0)
+}
+
+// GetUserIdentityBySubject mocks base method.
+func (m *MockUsersRepository) GetUserIdentityBySubject(provider, subject string) (*models.UserIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserIdentityBySubject", provider, subject)
+	ret0, _ := ret[0].(*models.UserIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserIdentityBySubject indicates an expected call of GetUserIdentityBySubject.
+func (mr *MockUsersRepositoryMockRecorder) GetUserIdentityBySubject(provider, subject interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserIdentityBySubject", reflect.TypeOf((*MockUsersRepository)(nil).GetUserIdentityBySubject), provider, subject)
+}
+
+// GetUsersDeletedBefore mocks base method.
+func (m *MockUsersRepository) GetUsersDeletedBefore(arg0 time.Time) ([]models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsersDeletedBefore", arg0)
+	ret0, _ := ret[0].([]models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsersDeletedBefore indicates an expected call of GetUsersDeletedBefore.
+func (mr *MockUsersRepositoryMockRecorder) GetUsersDeletedBefore(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersDeletedBefore", reflect.TypeOf((*MockUsersRepository)(nil).GetUsersDeletedBefore), arg0)
+}
+
+// MergeUsers mocks base method.
+func (m *MockUsersRepository) MergeUsers(intoID, fromID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeUsers", intoID, fromID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MergeUsers indicates an expected call of MergeUsers.
+func (mr *MockUsersRepositoryMockRecorder) MergeUsers(intoID, fromID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeUsers", reflect.TypeOf((*MockUsersRepository)(nil).MergeUsers), intoID, fromID)
+}
+
+// PurgeUser mocks base method.
+func (m *MockUsersRepository) PurgeUser(arg0 uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeUser", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeUser indicates an expected call of PurgeUser.
+func (mr *MockUsersRepositoryMockRecorder) PurgeUser(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeUser", reflect.TypeOf((*MockUsersRepository)(nil).PurgeUser), arg0)
+}
+
+// RestoreUser mocks base method.
+func (m *MockUsersRepository) RestoreUser(arg0 uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreUser", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreUser indicates an expected call of RestoreUser.
+func (mr *MockUsersRepositoryMockRecorder) RestoreUser(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreUser", reflect.TypeOf((*MockUsersRepository)(nil).RestoreUser), arg0)
 }
 
 // UpdateUser mocks base method.
@@ -123,3 +285,18 @@ func (mr *MockUsersRepositoryMockRecorder) UpdateUser(arg0 interface{}) *gomock.
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockUsersRepository)(nil).UpdateUser), arg0)
 }
+
+// UpdateUserGoals mocks base method.
+func (m *MockUsersRepository) UpdateUserGoals(u *models.User, goals []models.NutritionGoal, date string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserGoals", u, goals, date)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserGoals indicates an expected call of UpdateUserGoals.
+func (mr *MockUsersRepositoryMockRecorder) UpdateUserGoals(u, goals, date interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserGoals", reflect.TypeOf((*MockUsersRepository)(nil).UpdateUserGoals), u, goals, date)
+}
@@ -0,0 +1,109 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository/water.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	models "github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockWaterRepository is a mock of WaterRepository interface.
+type MockWaterRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockWaterRepositoryMockRecorder
+}
+
+// MockWaterRepositoryMockRecorder is the mock recorder for MockWaterRepository.
+type MockWaterRepositoryMockRecorder struct {
+	mock *MockWaterRepository
+}
+
+// NewMockWaterRepository creates a new mock instance.
+func NewMockWaterRepository(ctrl *gomock.Controller) *MockWaterRepository {
+	mock := &MockWaterRepository{ctrl: ctrl}
+	mock.recorder = &MockWaterRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWaterRepository) EXPECT() *MockWaterRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateWaterEntry mocks base method.
+func (m *MockWaterRepository) CreateWaterEntry(arg0 *models.WaterEntry) (*models.WaterEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWaterEntry", arg0)
+	ret0, _ := ret[0].(*models.WaterEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWaterEntry indicates an expected call of CreateWaterEntry.
+func (mr *MockWaterRepositoryMockRecorder) CreateWaterEntry(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWaterEntry", reflect.TypeOf((*MockWaterRepository)(nil).CreateWaterEntry), arg0)
+}
+
+// DeleteWaterEntry mocks base method.
+func (m *MockWaterRepository) DeleteWaterEntry(arg0 uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWaterEntry", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWaterEntry indicates an expected call of DeleteWaterEntry.
+func (mr *MockWaterRepositoryMockRecorder) DeleteWaterEntry(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWaterEntry", reflect.TypeOf((*MockWaterRepository)(nil).DeleteWaterEntry), arg0)
+}
+
+// GetWaterEntries mocks base method.
+func (m *MockWaterRepository) GetWaterEntries(userID uint, date string) ([]models.WaterEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWaterEntries", userID, date)
+	ret0, _ := ret[0].([]models.WaterEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWaterEntries indicates an expected call of GetWaterEntries.
+func (mr *MockWaterRepositoryMockRecorder) GetWaterEntries(userID, date interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWaterEntries", reflect.TypeOf((*MockWaterRepository)(nil).GetWaterEntries), userID, date)
+}
+
+// GetWaterEntriesBetween mocks base method.
+func (m *MockWaterRepository) GetWaterEntriesBetween(userID uint, from, to string) ([]models.WaterEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWaterEntriesBetween", userID, from, to)
+	ret0, _ := ret[0].([]models.WaterEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWaterEntriesBetween indicates an expected call of GetWaterEntriesBetween.
+func (mr *MockWaterRepositoryMockRecorder) GetWaterEntriesBetween(userID, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWaterEntriesBetween", reflect.TypeOf((*MockWaterRepository)(nil).GetWaterEntriesBetween), userID, from, to)
+}
+
+// GetWaterEntry mocks base method.
+func (m *MockWaterRepository) GetWaterEntry(arg0 uint) (*models.WaterEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWaterEntry", arg0)
+	ret0, _ := ret[0].(*models.WaterEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWaterEntry indicates an expected call of GetWaterEntry.
+func (mr *MockWaterRepositoryMockRecorder) GetWaterEntry(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWaterEntry", reflect.TypeOf((*MockWaterRepository)(nil).GetWaterEntry), arg0)
+}
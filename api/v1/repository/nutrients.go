@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"gorm.io/gorm"
+)
+
+// DefaultNutrients are the nutrients tracked when the nutrients table is created,
+// with daily values recommended for adults.
+var DefaultNutrients = []models.Nutrient{
+	{Key: "fiber", Name: "Fiber", Unit: "g", DailyValue: 28},
+	{Key: "sugar", Name: "Sugar", Unit: "g", DailyValue: 50, Limit: true},
+	{Key: "sodium", Name: "Sodium", Unit: "mg", DailyValue: 2300, Limit: true},
+	{Key: "potassium", Name: "Potassium", Unit: "mg", DailyValue: 4700},
+	{Key: "calcium", Name: "Calcium", Unit: "mg", DailyValue: 1300},
+	{Key: "iron", Name: "Iron", Unit: "mg", DailyValue: 18},
+	{Key: "magnesium", Name: "Magnesium", Unit: "mg", DailyValue: 420},
+	{Key: "vitamin_a", Name: "Vitamin A", Unit: "µg", DailyValue: 900},
+	{Key: "vitamin_c", Name: "Vitamin C", Unit: "mg", DailyValue: 90},
+	{Key: "vitamin_d", Name: "Vitamin D", Unit: "µg", DailyValue: 20},
+}
+
+type NutrientsRepository interface {
+	GetNutrients() ([]models.Nutrient, error)
+	GetNutrient(key string) (*models.Nutrient, error)
+	CreateNutrient(*models.Nutrient) (*models.Nutrient, error)
+	UpdateNutrient(*models.Nutrient) (*models.Nutrient, error)
+}
+
+type NutrientsGormRepository struct {
+	db *gorm.DB
+}
+
+func NewNutrientsGormRepository(db *gorm.DB) *NutrientsGormRepository {
+	seed := !db.Migrator().HasTable(&models.Nutrient{})
+	db.AutoMigrate(&models.Nutrient{})
+	if seed {
+		db.Create(&DefaultNutrients)
+	}
+	return &NutrientsGormRepository{
+		db: db,
+	}
+}
+
+func (r *NutrientsGormRepository) GetNutrients() ([]models.Nutrient, error) {
+	var nutrients []models.Nutrient
+	res := r.db.Order("key").Find(&nutrients)
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return nutrients, nil
+}
+
+func (r *NutrientsGormRepository) GetNutrient(key string) (*models.Nutrient, error) {
+	var nutrient *models.Nutrient
+	res := r.db.Where("key = ?", key).First(&nutrient)
+	if res.Error == gorm.ErrRecordNotFound {
+		return nil, ErrNotFound
+	}
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return nutrient, nil
+}
+
+func (r *NutrientsGormRepository) CreateNutrient(n *models.Nutrient) (*models.Nutrient, error) {
+	res := r.db.Create(n)
+	if res.Error != nil {
+		return nil, ErrCouldNotCreate
+	}
+	return n, nil
+}
+
+func (r *NutrientsGormRepository) UpdateNutrient(n *models.Nutrient) (*models.Nutrient, error) {
+	res := r.db.Save(n)
+	if res.Error != nil {
+		return nil, ErrCouldNotUpdate
+	}
+	return n, nil
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"gorm.io/gorm"
+)
+
+type PasswordResetsRepository interface {
+	GetPasswordResetTokenByHash(string) (*models.PasswordResetToken, error)
+	CreatePasswordResetToken(*models.PasswordResetToken) (*models.PasswordResetToken, error)
+	DeletePasswordResetTokens(userID uint) error
+	DeleteExpiredPasswordResetTokens(now time.Time) error
+}
+
+type PasswordResetsGormRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordResetsGormRepository(db *gorm.DB) *PasswordResetsGormRepository {
+	db.AutoMigrate(&models.PasswordResetToken{})
+	return &PasswordResetsGormRepository{
+		db: db,
+	}
+}
+
+func (r *PasswordResetsGormRepository) GetPasswordResetTokenByHash(hash string) (*models.PasswordResetToken, error) {
+	var prt *models.PasswordResetToken
+	res := r.db.Where("token_hash = ?", hash).First(&prt)
+	if res.Error == gorm.ErrRecordNotFound {
+		return nil, ErrNotFound
+	}
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return prt, nil
+}
+
+func (r *PasswordResetsGormRepository) CreatePasswordResetToken(prt *models.PasswordResetToken) (*models.PasswordResetToken, error) {
+	res := r.db.Create(prt)
+	if res.Error != nil {
+		return nil, ErrCouldNotCreate
+	}
+	return prt, nil
+}
+
+// DeletePasswordResetTokens deletes every password reset token of user.
+func (r *PasswordResetsGormRepository) DeletePasswordResetTokens(userID uint) error {
+	res := r.db.Where("user_id = ?", userID).Delete(&models.PasswordResetToken{})
+	if res.Error != nil {
+		return ErrCouldNotDelete
+	}
+	return nil
+}
+
+// DeleteExpiredPasswordResetTokens deletes every password reset token expired at now.
+func (r *PasswordResetsGormRepository) DeleteExpiredPasswordResetTokens(now time.Time) error {
+	res := r.db.Where("expires_at <= ?", now).Delete(&models.PasswordResetToken{})
+	if res.Error != nil {
+		return ErrCouldNotDelete
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"gorm.io/gorm"
+)
+
+type RecipesRepository interface {
+	GetAllRecipes() ([]models.Recipe, error)
+	GetRecipe(uint) (*models.Recipe, error)
+	GetUserRecipes(userID uint, addedIDs []uint) ([]models.Recipe, error)
+	CreateRecipe(*models.Recipe) (*models.Recipe, error)
+	UpdateRecipe(*models.Recipe) (*models.Recipe, error)
+	DeleteRecipe(uint) error
+}
+
+type RecipesGormRepository struct {
+	db *gorm.DB
+}
+
+func NewRecipesGormRepository(db *gorm.DB) *RecipesGormRepository {
+	db.AutoMigrate(&models.Recipe{}, &models.RecipeIngredient{})
+	return &RecipesGormRepository{
+		db: db,
+	}
+}
+
+func (r *RecipesGormRepository) GetAllRecipes() ([]models.Recipe, error) {
+	var recipes []models.Recipe
+	res := r.db.Preload("Ingredients").Find(&recipes)
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return recipes, nil
+}
+
+func (r *RecipesGormRepository) GetRecipe(id uint) (*models.Recipe, error) {
+	var recipe *models.Recipe
+	res := r.db.Preload("Ingredients").First(&recipe, id)
+	if res.Error == gorm.ErrRecordNotFound {
+		return nil, ErrNotFound
+	}
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return recipe, nil
+}
+
+// GetUserRecipes returns the recipes authored by user of userID
+// along with the ones of addedIDs, ordered by ID.
+func (r *RecipesGormRepository) GetUserRecipes(userID uint, addedIDs []uint) ([]models.Recipe, error) {
+	var recipes []models.Recipe
+	q := r.db.Preload("Ingredients").Where("author_id = ?", userID)
+	if len(addedIDs) > 0 {
+		q = q.Or("id IN ?", addedIDs)
+	}
+	res := q.Order("id").Find(&recipes)
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return recipes, nil
+}
+
+func (r *RecipesGormRepository) CreateRecipe(recipe *models.Recipe) (*models.Recipe, error) {
+	res := r.db.Create(recipe)
+	if res.Error != nil {
+		return nil, ErrCouldNotCreate
+	}
+	return recipe, nil
+}
+
+// UpdateRecipe saves recipe replacing its previous ingredients.
+func (r *RecipesGormRepository) UpdateRecipe(recipe *models.Recipe) (*models.Recipe, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("recipe_id = ?", recipe.ID).Delete(&models.RecipeIngredient{}).Error; err != nil {
+			return err
+		}
+		for i := range recipe.Ingredients {
+			recipe.Ingredients[i].ID = 0
+		}
+		return tx.Save(recipe).Error
+	})
+	if err != nil {
+		return nil, ErrCouldNotUpdate
+	}
+	return recipe, nil
+}
+
+// DeleteRecipe deletes recipe with id, removing it from the recipes added by users,
+// whose versions are incremented.
+func (r *RecipesGormRepository) DeleteRecipe(id uint) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("recipe_id = ?", id).Delete(&models.RecipeIngredient{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&models.User{}).
+			Where("id IN (?)", tx.Table("user_recipes_added").Select("user_id").Where("recipe_id = ?", id)).
+			Update("version", gorm.Expr("version + 1")).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM user_recipes_added WHERE recipe_id = ?", id).Error; err != nil {
+			return err
+		}
+		res := tx.Delete(&models.Recipe{}, id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected != 1 {
+			return ErrNotFound
+		}
+		return nil
+	})
+	if err == ErrNotFound {
+		return ErrNotFound
+	}
+	if err != nil {
+		return ErrCouldNotDelete
+	}
+	return nil
+}
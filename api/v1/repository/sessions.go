@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"gorm.io/gorm"
+)
+
+// ErrAlreadyUsed is returned when rotating a refresh token that was already used.
+var ErrAlreadyUsed = errors.New("token already used")
+
+// MigratedSessionLifetime is the lifetime of sessions
+// migrated from the legacy access_token column of users table.
+var MigratedSessionLifetime = 30 * 24 * time.Hour
+
+// HashToken returns the hash under which access and refresh tokens are stored.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+type SessionsRepository interface {
+	GetSessions(userID uint) ([]models.Session, error)
+	GetSession(uint) (*models.Session, error)
+	GetSessionByTokenHash(string) (*models.Session, error)
+	CreateSession(*models.Session) (*models.Session, error)
+	UpdateSession(*models.Session) (*models.Session, error)
+	DeleteSession(uint) error
+	DeleteUserSessions(userID uint) error
+	DeleteExpiredSessions(now time.Time) error
+	GetRefreshTokenByHash(string) (*models.RefreshToken, error)
+	CreateRefreshToken(*models.RefreshToken) (*models.RefreshToken, error)
+	RotateRefreshToken(used *models.RefreshToken, next *models.RefreshToken, s *models.Session) error
+}
+
+type SessionsGormRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionsGormRepository(db *gorm.DB) *SessionsGormRepository {
+	db.AutoMigrate(&models.Session{}, &models.RefreshToken{})
+	if err := migrateAccessTokens(db); err != nil {
+		log.Printf("Could not migrate access tokens: %v", err)
+	}
+	// Access tokens of sessions created before they were short-lived
+	// stay valid as long as their session.
+	db.Model(&models.Session{}).Where("access_expires_at IS NULL").Update("access_expires_at", gorm.Expr("expires_at"))
+	return &SessionsGormRepository{
+		db: db,
+	}
+}
+
+// migrateAccessTokens converts the legacy access_token column of users table,
+// which stored a plaintext token per user, into sessions.
+func migrateAccessTokens(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&models.User{}, "access_token") {
+		return nil
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var rows []struct {
+			ID          uint
+			AccessToken string
+		}
+		if err := tx.Table("users").Select("id", "access_token").Where("access_token <> ''").Scan(&rows).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		for _, row := range rows {
+			s := &models.Session{
+				UserID:          row.ID,
+				TokenHash:       HashToken(row.AccessToken),
+				Device:          "Migrated session",
+				CreatedAt:       now,
+				ExpiresAt:       now.Add(MigratedSessionLifetime),
+				AccessExpiresAt: now.Add(MigratedSessionLifetime),
+				LastUsedAt:      now,
+			}
+			if err := tx.Create(s).Error; err != nil {
+				return err
+			}
+		}
+		// Cleared in case the column can't be dropped
+		return tx.Table("users").Where("access_token <> ''").Update("access_token", "").Error
+	})
+	if err != nil {
+		return err
+	}
+	return db.Migrator().DropColumn(&models.User{}, "access_token")
+}
+
+// GetSessions returns the sessions of user, most recent first.
+func (r *SessionsGormRepository) GetSessions(userID uint) ([]models.Session, error) {
+	var sessions []models.Session
+	res := r.db.Where("user_id = ?", userID).Order("created_at DESC").Order("id DESC").Find(&sessions)
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return sessions, nil
+}
+
+func (r *SessionsGormRepository) GetSession(id uint) (*models.Session, error) {
+	var session *models.Session
+	res := r.db.First(&session, id)
+	if res.Error == gorm.ErrRecordNotFound {
+		return nil, ErrNotFound
+	}
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return session, nil
+}
+
+func (r *SessionsGormRepository) GetSessionByTokenHash(hash string) (*models.Session, error) {
+	var session *models.Session
+	res := r.db.Where("token_hash = ?", hash).First(&session)
+	if res.Error == gorm.ErrRecordNotFound {
+		return nil, ErrNotFound
+	}
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return session, nil
+}
+
+func (r *SessionsGormRepository) CreateSession(s *models.Session) (*models.Session, error) {
+	res := r.db.Create(s)
+	if res.Error != nil {
+		return nil, ErrCouldNotCreate
+	}
+	return s, nil
+}
+
+func (r *SessionsGormRepository) UpdateSession(s *models.Session) (*models.Session, error) {
+	res := r.db.Save(s)
+	if res.Error != nil {
+		return nil, ErrCouldNotUpdate
+	}
+	return s, nil
+}
+
+// DeleteSession deletes session with id along with its refresh tokens.
+func (r *SessionsGormRepository) DeleteSession(id uint) error {
+	var deleted int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("session_id = ?", id).Delete(&models.RefreshToken{}).Error; err != nil {
+			return err
+		}
+		res := tx.Delete(&models.Session{}, id)
+		deleted = res.RowsAffected
+		return res.Error
+	})
+	if err != nil {
+		return ErrCouldNotDelete
+	}
+	if deleted != 1 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteUserSessions deletes every session of user along with their refresh tokens.
+func (r *SessionsGormRepository) DeleteUserSessions(userID uint) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		sessions := tx.Model(&models.Session{}).Select("id").Where("user_id = ?", userID)
+		if err := tx.Where("session_id IN (?)", sessions).Delete(&models.RefreshToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.Session{}).Error
+	})
+	if err != nil {
+		return ErrCouldNotDelete
+	}
+	return nil
+}
+
+// DeleteExpiredSessions deletes every session expired at now
+// along with their refresh tokens.
+func (r *SessionsGormRepository) DeleteExpiredSessions(now time.Time) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		expired := tx.Model(&models.Session{}).Select("id").Where("expires_at <= ?", now)
+		if err := tx.Where("session_id IN (?)", expired).Delete(&models.RefreshToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("expires_at <= ?", now).Delete(&models.Session{}).Error
+	})
+	if err != nil {
+		return ErrCouldNotDelete
+	}
+	return nil
+}
+
+func (r *SessionsGormRepository) GetRefreshTokenByHash(hash string) (*models.RefreshToken, error) {
+	var rt *models.RefreshToken
+	res := r.db.Where("token_hash = ?", hash).First(&rt)
+	if res.Error == gorm.ErrRecordNotFound {
+		return nil, ErrNotFound
+	}
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return rt, nil
+}
+
+func (r *SessionsGormRepository) CreateRefreshToken(rt *models.RefreshToken) (*models.RefreshToken, error) {
+	res := r.db.Create(rt)
+	if res.Error != nil {
+		return nil, ErrCouldNotCreate
+	}
+	return rt, nil
+}
+
+// RotateRefreshToken marks used as used, creates next and updates s
+// in a single transaction.
+//
+// Returns ErrAlreadyUsed if used was already used, even concurrently.
+func (r *SessionsGormRepository) RotateRefreshToken(used *models.RefreshToken, next *models.RefreshToken, s *models.Session) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		res := tx.Model(&models.RefreshToken{}).Where("id = ? AND used_at IS NULL", used.ID).Update("used_at", now)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected != 1 {
+			return ErrAlreadyUsed
+		}
+		used.UsedAt = &now
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+		return tx.Save(s).Error
+	})
+	if err == ErrAlreadyUsed {
+		return err
+	}
+	if err != nil {
+		return ErrCouldNotUpdate
+	}
+	return nil
+}
@@ -2,6 +2,9 @@ package repository
 
 import (
 	"errors"
+	"log"
+	"strings"
+	"time"
 
 	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
 	"gorm.io/gorm"
@@ -13,15 +16,53 @@ var (
 	ErrCouldNotCreate   = errors.New("could not insert record")
 	ErrCouldNotUpdate   = errors.New("could not update record")
 	ErrCouldNotDelete   = errors.New("could not delete record")
+	// ErrVersionConflict is returned updating records changed since they were retrieved.
+	ErrVersionConflict = errors.New("record was changed since it was retrieved")
 )
 
 type UsersRepository interface {
-	GetAllUsers() ([]models.User, error)
+	FindUsers(UsersQuery) ([]models.User, error)
 	GetUser(uint) (*models.User, error)
-	GetUserByGoogleSub(string) (*models.User, error)
-	GetUserByAccessToken(string) (*models.User, error)
+	GetUserByIdentity(provider string, subject string) (*models.User, error)
+	GetUserByEmail(string) (*models.User, error)
+	GetUserByUsername(string) (*models.User, error)
 	CreateUser(*models.User) (*models.User, error)
 	UpdateUser(*models.User) (*models.User, error)
+	UpdateUserGoals(u *models.User, goals []models.NutritionGoal, date string) (*models.User, error)
+	GetUserIdentities(userID uint) ([]models.UserIdentity, error)
+	GetUserIdentity(uint) (*models.UserIdentity, error)
+	GetUserIdentityBySubject(provider string, subject string) (*models.UserIdentity, error)
+	CreateUserIdentity(*models.UserIdentity) (*models.UserIdentity, error)
+	DeleteUserIdentity(uint) error
+	MergeUsers(intoID uint, fromID uint) error
+	DeleteUser(uint) error
+	RestoreUser(uint) error
+	GetUsersDeletedBefore(time.Time) ([]models.User, error)
+	PurgeUser(uint) error
+}
+
+// Keys users can be sorted by
+const (
+	UsersSortID        = "id"
+	UsersSortUsername  = "username"
+	UsersSortEmail     = "email"
+	UsersSortCreatedAt = "created_at"
+)
+
+// UsersQuery filters users and sorts them by SortBy, then by ID.
+//
+// Pages start after the user with AfterID, whose value of SortBy is AfterValue,
+// or from the first user if AfterID is zero.
+type UsersQuery struct {
+	UsernamePrefix string    // Ignoring case
+	EmailPrefix    string    // Ignoring case
+	Role           string    // Any role if empty
+	CreatedAfter   time.Time // Any time if zero
+	SortBy         string    // One of the sort keys, ID if empty
+	Descending     bool
+	AfterID        uint
+	AfterValue     interface{} // String, or time if sorted by creation time
+	Limit          int
 }
 
 type UsersGormRepository struct {
@@ -29,15 +70,139 @@ type UsersGormRepository struct {
 }
 
 func NewUsersGormRepository(db *gorm.DB) *UsersGormRepository {
-	db.AutoMigrate(&models.User{})
+	migrateCreatedAt := db.Migrator().HasTable(&models.User{}) && !db.Migrator().HasColumn(&models.User{}, "created_at")
+	db.AutoMigrate(&models.Recipe{}, &models.RecipeIngredient{}, &models.User{}, &models.UserIdentity{})
+	if migrateCreatedAt {
+		// Users registered before creation times were recorded get the time of the migration
+		db.Model(&models.User{}).Unscoped().Where("created_at IS NULL").Update("created_at", time.Now())
+	}
+	if err := migrateRecipesAdded(db); err != nil {
+		log.Printf("Could not migrate recipes added by users: %v", err)
+	}
+	if err := migrateGoogleSubs(db); err != nil {
+		log.Printf("Could not migrate Google subjects of users: %v", err)
+	}
+	if db.Migrator().HasColumn(&models.User{}, "day") {
+		db.Migrator().DropColumn(&models.User{}, "day")
+	}
 	return &UsersGormRepository{
 		db: db,
 	}
 }
 
-func (r *UsersGormRepository) GetAllUsers() ([]models.User, error) {
+// migrateRecipesAdded converts the legacy recipes_added column,
+// which stored recipe names divided by character '^',
+// into Recipe records added to each user.
+func migrateRecipesAdded(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&models.User{}, "recipes_added") {
+		return nil
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var rows []struct {
+			ID           uint
+			RecipesAdded string
+		}
+		if err := tx.Table("users").Select("id", "recipes_added").Where("recipes_added <> ''").Scan(&rows).Error; err != nil {
+			return err
+		}
+		for _, row := range rows {
+			var recipes []models.Recipe
+			for _, name := range strings.Split(row.RecipesAdded, "^") {
+				if name == "" {
+					continue
+				}
+				recipes = append(recipes, models.Recipe{Name: name, Servings: 1, AuthorID: row.ID})
+			}
+			if len(recipes) == 0 {
+				continue
+			}
+			if err := tx.Model(&models.User{ID: row.ID}).Association("RecipesAdded").Append(recipes); err != nil {
+				return err
+			}
+		}
+		// Cleared in case the column can't be dropped
+		return tx.Table("users").Where("recipes_added <> ''").Update("recipes_added", "").Error
+	})
+	if err != nil {
+		return err
+	}
+	return db.Migrator().DropColumn(&models.User{}, "recipes_added")
+}
+
+// migrateGoogleSubs converts the legacy google_sub column of users table
+// into Google identities of each user.
+func migrateGoogleSubs(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&models.User{}, "google_sub") {
+		return nil
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var rows []struct {
+			ID        uint
+			GoogleSub string
+			Email     string
+		}
+		if err := tx.Table("users").Select("id", "google_sub", "email").Where("google_sub <> ''").Scan(&rows).Error; err != nil {
+			return err
+		}
+		for _, row := range rows {
+			identity := &models.UserIdentity{
+				UserID:   row.ID,
+				Provider: "google",
+				Subject:  row.GoogleSub,
+				Email:    row.Email,
+			}
+			if err := tx.Create(identity).Error; err != nil {
+				return err
+			}
+		}
+		// Cleared in case the column can't be dropped
+		return tx.Table("users").Where("google_sub <> ''").Update("google_sub", "").Error
+	})
+	if err != nil {
+		return err
+	}
+	return db.Migrator().DropColumn(&models.User{}, "google_sub")
+}
+
+// FindUsers returns the page of users matching q.
+func (r *UsersGormRepository) FindUsers(q UsersQuery) ([]models.User, error) {
+	sortBy := q.SortBy
+	switch sortBy {
+	case "":
+		sortBy = UsersSortID
+	case UsersSortID, UsersSortUsername, UsersSortEmail, UsersSortCreatedAt:
+	default:
+		return nil, ErrCouldNotRetrieve
+	}
+	tx := r.db.Preload("RecipesAdded")
+	if q.UsernamePrefix != "" {
+		tx = tx.Where(`LOWER(username) LIKE ? ESCAPE '\'`, likeEscaper.Replace(strings.ToLower(q.UsernamePrefix))+"%")
+	}
+	if q.EmailPrefix != "" {
+		tx = tx.Where(`LOWER(email) LIKE ? ESCAPE '\'`, likeEscaper.Replace(strings.ToLower(q.EmailPrefix))+"%")
+	}
+	if q.Role != "" {
+		tx = tx.Where("role = ?", q.Role)
+	}
+	if !q.CreatedAfter.IsZero() {
+		tx = tx.Where("created_at > ?", q.CreatedAfter)
+	}
+	op, direction := ">", "ASC"
+	if q.Descending {
+		op, direction = "<", "DESC"
+	}
+	if q.AfterID != 0 {
+		if sortBy == UsersSortID {
+			tx = tx.Where("id "+op+" ?", q.AfterID)
+		} else {
+			tx = tx.Where("("+sortBy+" "+op+" ? OR ("+sortBy+" = ? AND id "+op+" ?))", q.AfterValue, q.AfterValue, q.AfterID)
+		}
+	}
+	if sortBy != UsersSortID {
+		tx = tx.Order(sortBy + " " + direction)
+	}
 	var users []models.User
-	res := r.db.Find(&users)
+	res := tx.Order("id " + direction).Limit(q.Limit).Find(&users)
 	if res.Error != nil {
 		return nil, ErrCouldNotRetrieve
 	}
@@ -46,7 +211,7 @@ func (r *UsersGormRepository) GetAllUsers() ([]models.User, error) {
 
 func (r *UsersGormRepository) GetUser(id uint) (*models.User, error) {
 	var user *models.User
-	res := r.db.Find(&user, id)
+	res := r.db.Preload("RecipesAdded").Find(&user, id)
 	if res.Error == gorm.ErrRecordNotFound || res.RowsAffected != 1 {
 		return nil, ErrNotFound
 	}
@@ -56,9 +221,28 @@ func (r *UsersGormRepository) GetUser(id uint) (*models.User, error) {
 	return user, nil
 }
 
-func (r *UsersGormRepository) GetUserByGoogleSub(sub string) (*models.User, error) {
+// GetUserByIdentity returns the user with the identity
+// of provider with subject, even if it's deleted.
+func (r *UsersGormRepository) GetUserByIdentity(provider string, subject string) (*models.User, error) {
+	var user *models.User
+	res := r.db.Unscoped().Preload("RecipesAdded").
+		Joins("JOIN user_identities ON user_identities.user_id = users.id").
+		Where("user_identities.provider = ? AND user_identities.subject = ?", provider, subject).
+		First(&user)
+	if res.Error == gorm.ErrRecordNotFound {
+		return nil, ErrNotFound
+	}
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return user, nil
+}
+
+// GetUserByEmail returns the user with email, ignoring case,
+// even if it's deleted.
+func (r *UsersGormRepository) GetUserByEmail(email string) (*models.User, error) {
 	var user *models.User
-	res := r.db.Where("google_sub = ?", sub).First(&user)
+	res := r.db.Unscoped().Preload("RecipesAdded").Where("LOWER(email) = LOWER(?)", email).Order("id").First(&user)
 	if res.Error == gorm.ErrRecordNotFound {
 		return nil, ErrNotFound
 	}
@@ -68,9 +252,9 @@ func (r *UsersGormRepository) GetUserByGoogleSub(sub string) (*models.User, erro
 	return user, nil
 }
 
-func (r *UsersGormRepository) GetUserByAccessToken(at string) (*models.User, error) {
+func (r *UsersGormRepository) GetUserByUsername(username string) (*models.User, error) {
 	var user *models.User
-	res := r.db.Where("access_token = ?", at).First(&user)
+	res := r.db.Unscoped().Where("LOWER(username) = LOWER(?)", username).Order("id").First(&user)
 	if res.Error == gorm.ErrRecordNotFound {
 		return nil, ErrNotFound
 	}
@@ -88,10 +272,244 @@ func (r *UsersGormRepository) CreateUser(u *models.User) (*models.User, error) {
 	return u, nil
 }
 
+// UpdateUser saves u, incrementing its version, only if it's still
+// the version of the user stored, otherwise it returns ErrVersionConflict.
 func (r *UsersGormRepository) UpdateUser(u *models.User) (*models.User, error) {
-	res := r.db.Save(u)
-	if res.Error != nil {
+	return r.UpdateUserGoals(u, nil, "")
+}
+
+// UpdateUserGoals saves goals in the goals history of u and updates u
+// like UpdateUser, in one transaction. If date is not empty, the current goals
+// of u are set to those of its history in effect at date.
+func (r *UsersGormRepository) UpdateUserGoals(u *models.User, goals []models.NutritionGoal, date string) (*models.User, error) {
+	updated := *u
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for i := range goals {
+			goals[i].UserID = u.ID
+			if err := saveGoal(tx, &goals[i]); err != nil {
+				return err
+			}
+		}
+		if date != "" {
+			g, err := goalAt(tx, u.ID, date)
+			if err != nil && err != ErrNotFound {
+				return err
+			}
+			if err == nil {
+				updated.Calories = g.Calories
+				updated.Carbs = g.Carbs
+				updated.Fats = g.Fats
+				updated.Proteins = g.Proteins
+				updated.Water = g.Water
+			}
+		}
+		return updateUser(tx, &updated)
+	})
+	if err == ErrVersionConflict {
+		return nil, err
+	}
+	if err != nil {
 		return nil, ErrCouldNotUpdate
 	}
+	*u = updated
 	return u, nil
 }
+
+// updateUser saves u through tx replacing the recipes it had added,
+// incrementing its version only if it's still the version of the user stored.
+func updateUser(tx *gorm.DB, u *models.User) error {
+	version := u.Version
+	u.Version = version + 1
+	res := tx.Model(u).Where("version = ?", version).Select("*").Omit("RecipesAdded", "Identities", "CreatedAt").Updates(u)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+	if len(u.RecipesAdded) == 0 {
+		return tx.Model(u).Association("RecipesAdded").Clear()
+	}
+	return tx.Model(u).Association("RecipesAdded").Replace(u.RecipesAdded)
+}
+
+func (r *UsersGormRepository) GetUserIdentities(userID uint) ([]models.UserIdentity, error) {
+	var identities []models.UserIdentity
+	res := r.db.Where("user_id = ?", userID).Order("created_at").Order("id").Find(&identities)
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return identities, nil
+}
+
+func (r *UsersGormRepository) GetUserIdentity(id uint) (*models.UserIdentity, error) {
+	var identity *models.UserIdentity
+	res := r.db.First(&identity, id)
+	if res.Error == gorm.ErrRecordNotFound {
+		return nil, ErrNotFound
+	}
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return identity, nil
+}
+
+func (r *UsersGormRepository) GetUserIdentityBySubject(provider string, subject string) (*models.UserIdentity, error) {
+	var identity *models.UserIdentity
+	res := r.db.Where("provider = ? AND subject = ?", provider, subject).First(&identity)
+	if res.Error == gorm.ErrRecordNotFound {
+		return nil, ErrNotFound
+	}
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return identity, nil
+}
+
+func (r *UsersGormRepository) CreateUserIdentity(identity *models.UserIdentity) (*models.UserIdentity, error) {
+	res := r.db.Create(identity)
+	if res.Error != nil {
+		return nil, ErrCouldNotCreate
+	}
+	return identity, nil
+}
+
+func (r *UsersGormRepository) DeleteUserIdentity(id uint) error {
+	res := r.db.Delete(&models.UserIdentity{}, id)
+	if res.Error != nil {
+		return ErrCouldNotDelete
+	}
+	if res.RowsAffected != 1 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// MergeUsers moves the diary entries, measurements, water entries, goals,
+// recipes and identities of user with fromID to user with intoID,
+// then deletes user with fromID along with its sessions.
+// The version of user with intoID is incremented, its recipes added changed.
+//
+// Goals of dates both users have keep those of user with intoID.
+func (r *UsersGormRepository) MergeUsers(intoID uint, fromID uint) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&models.User{}, fromID).Error; err != nil {
+			return err
+		}
+		for _, m := range []interface{}{&models.DiaryEntry{}, &models.Measurement{}, &models.WaterEntry{}, &models.UserIdentity{}} {
+			if err := tx.Model(m).Where("user_id = ?", fromID).Update("user_id", intoID).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("user_id = ? AND effective_date IN (?)", fromID,
+			tx.Model(&models.NutritionGoal{}).Select("effective_date").Where("user_id = ?", intoID)).
+			Delete(&models.NutritionGoal{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.NutritionGoal{}).Where("user_id = ?", fromID).Update("user_id", intoID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Recipe{}).Where("author_id = ?", fromID).Update("author_id", intoID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("INSERT INTO user_recipes_added (user_id, recipe_id) "+
+			"SELECT ?, recipe_id FROM user_recipes_added WHERE user_id = ? "+
+			"AND recipe_id NOT IN (SELECT recipe_id FROM user_recipes_added WHERE user_id = ?)",
+			intoID, fromID, intoID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM user_recipes_added WHERE user_id = ?", fromID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.User{}).Where("id = ?", intoID).Update("version", gorm.Expr("version + 1")).Error; err != nil {
+			return err
+		}
+		if err := deleteUserAuth(tx, fromID); err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&models.User{}, fromID).Error
+	})
+	if err == gorm.ErrRecordNotFound {
+		return ErrNotFound
+	}
+	if err != nil {
+		return ErrCouldNotUpdate
+	}
+	return nil
+}
+
+// deleteUserAuth deletes the sessions of user with userID
+// along with every token and login in progress of the user.
+func deleteUserAuth(tx *gorm.DB, userID uint) error {
+	if err := tx.Where("session_id IN (?)", tx.Model(&models.Session{}).Select("id").Where("user_id = ?", userID)).
+		Delete(&models.RefreshToken{}).Error; err != nil {
+		return err
+	}
+	for _, m := range []interface{}{&models.Session{}, &models.PasswordResetToken{}, &models.EmailVerificationToken{}, &models.LoginState{}, &models.AuthorizationCode{}} {
+		if err := tx.Where("user_id = ?", userID).Delete(m).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteUser soft deletes user with id, which can be restored until it's purged.
+func (r *UsersGormRepository) DeleteUser(id uint) error {
+	res := r.db.Delete(&models.User{}, id)
+	if res.Error != nil {
+		return ErrCouldNotDelete
+	}
+	if res.RowsAffected != 1 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// RestoreUser cancels the deletion of user with id, incrementing its version.
+func (r *UsersGormRepository) RestoreUser(id uint) error {
+	res := r.db.Unscoped().Model(&models.User{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"deleted_at": nil, "version": gorm.Expr("version + 1")})
+	if res.Error != nil {
+		return ErrCouldNotUpdate
+	}
+	if res.RowsAffected != 1 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetUsersDeletedBefore returns the users deleted before t.
+func (r *UsersGormRepository) GetUsersDeletedBefore(t time.Time) ([]models.User, error) {
+	var users []models.User
+	res := r.db.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", t).Find(&users)
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return users, nil
+}
+
+// PurgeUser permanently deletes user with id along with all its data.
+// Recipes it authored are kept without author, since other users could have added them.
+func (r *UsersGormRepository) PurgeUser(id uint) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for _, m := range []interface{}{&models.DiaryEntry{}, &models.Measurement{}, &models.WaterEntry{}, &models.NutritionGoal{}, &models.UserIdentity{}} {
+			if err := tx.Where("user_id = ?", id).Delete(m).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&models.Recipe{}).Where("author_id = ?", id).Update("author_id", 0).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM user_recipes_added WHERE user_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := deleteUserAuth(tx, id); err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&models.User{}, id).Error
+	})
+	if err != nil {
+		return ErrCouldNotDelete
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"gorm.io/gorm"
+)
+
+type WaterRepository interface {
+	GetWaterEntries(userID uint, date string) ([]models.WaterEntry, error)
+	GetWaterEntriesBetween(userID uint, from string, to string) ([]models.WaterEntry, error)
+	GetWaterEntry(uint) (*models.WaterEntry, error)
+	CreateWaterEntry(*models.WaterEntry) (*models.WaterEntry, error)
+	DeleteWaterEntry(uint) error
+}
+
+type WaterGormRepository struct {
+	db *gorm.DB
+}
+
+func NewWaterGormRepository(db *gorm.DB) *WaterGormRepository {
+	db.AutoMigrate(&models.WaterEntry{})
+	return &WaterGormRepository{
+		db: db,
+	}
+}
+
+func (r *WaterGormRepository) GetWaterEntries(userID uint, date string) ([]models.WaterEntry, error) {
+	var entries []models.WaterEntry
+	res := r.db.Where("user_id = ? AND date = ?", userID, date).Order("logged_at").Order("id").Find(&entries)
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return entries, nil
+}
+
+func (r *WaterGormRepository) GetWaterEntriesBetween(userID uint, from string, to string) ([]models.WaterEntry, error) {
+	var entries []models.WaterEntry
+	res := r.db.Where("user_id = ? AND date >= ? AND date <= ?", userID, from, to).Order("date").Order("logged_at").Order("id").Find(&entries)
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return entries, nil
+}
+
+func (r *WaterGormRepository) GetWaterEntry(id uint) (*models.WaterEntry, error) {
+	var entry *models.WaterEntry
+	res := r.db.First(&entry, id)
+	if res.Error == gorm.ErrRecordNotFound {
+		return nil, ErrNotFound
+	}
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return entry, nil
+}
+
+func (r *WaterGormRepository) CreateWaterEntry(e *models.WaterEntry) (*models.WaterEntry, error) {
+	res := r.db.Create(e)
+	if res.Error != nil {
+		return nil, ErrCouldNotCreate
+	}
+	return e, nil
+}
+
+func (r *WaterGormRepository) DeleteWaterEntry(id uint) error {
+	res := r.db.Delete(&models.WaterEntry{}, id)
+	if res.Error != nil {
+		return ErrCouldNotDelete
+	}
+	if res.RowsAffected != 1 {
+		return ErrNotFound
+	}
+	return nil
+}
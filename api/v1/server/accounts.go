@@ -0,0 +1,93 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	// AccountDeletionGracePeriod is the time deleted users have
+	// to log in and restore their account before it's purged.
+	AccountDeletionGracePeriod = 30 * 24 * time.Hour
+	// PurgeInterval is the time between purges of deleted users.
+	PurgeInterval = time.Hour
+)
+
+// DeleteUser is the handler for DELETE requests to /users/:id
+// 	@ID DeleteUser
+// 	@Summary Delete user
+// 	@Description Delete authenticated user, ending all its sessions.
+// 	@Description Its data is purged after a grace period, logging in before restores it.
+// 	@Tags users
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Param id path int true "User ID"
+// 	@Param If-Match header string false "ETag of user retrieved, responds 412 if it changed since"
+// 	@Success 204
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 401 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 412 {object} models.APIError
+// 	@Router /users/{id} [delete]
+func (s *Server) DeleteUser(c *gin.Context) {
+	au, ok := s.authenticatedOwner(c)
+	if !ok {
+		return
+	}
+	if preconditionFailed(c, versionETag(au.Version)) {
+		return
+	}
+	if err := s.UsersRepo.DeleteUser(au.ID); err != nil {
+		respondError(c, fmt.Errorf("could not delete user: %w", err))
+		return
+	}
+	if err := s.SessionsRepo.DeleteUserSessions(au.ID); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// restoreUser cancels the deletion of u if it's deleted,
+// since users logging in during the grace period keep their account.
+func (s *Server) restoreUser(u *models.User) error {
+	if !u.DeletedAt.Valid {
+		return nil
+	}
+	if err := s.UsersRepo.RestoreUser(u.ID); err != nil {
+		return err
+	}
+	u.DeletedAt.Valid = false
+	return nil
+}
+
+// PurgeDeletedUsers permanently deletes the data of users
+// deleted longer than AccountDeletionGracePeriod before now.
+func (s *Server) PurgeDeletedUsers(now time.Time) error {
+	users, err := s.UsersRepo.GetUsersDeletedBefore(now.Add(-AccountDeletionGracePeriod))
+	if err != nil {
+		return err
+	}
+	for _, u := range users {
+		// Users that can't be purged are retried the next time
+		if err := s.UsersRepo.PurgeUser(u.ID); err != nil {
+			log.Printf("could not purge user %d: %v", u.ID, err)
+		}
+	}
+	return nil
+}
+
+// purgeDeletedUsersPeriodically purges deleted users every PurgeInterval.
+func (s *Server) purgeDeletedUsersPeriodically() {
+	for {
+		if err := s.PurgeDeletedUsers(time.Now()); err != nil {
+			log.Printf("could not purge deleted users: %v", err)
+		}
+		time.Sleep(PurgeInterval)
+	}
+}
@@ -0,0 +1,118 @@
+package server_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/repository"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/repository/mocks"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/server"
+	"github.com/golang/mock/gomock"
+)
+
+func TestDeleteUserEndsSessions(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := createUser(s, "UserAccessToken", &models.User{Username: "User"})
+	res := doRequest(t, http.MethodDelete, fmt.Sprintf("%s/v1/users/%d", ts.URL, u.ID), "UserAccessToken", nil)
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %v", http.StatusNoContent, res.StatusCode)
+	}
+	res = doRequest(t, http.MethodGet, ts.URL+"/v1/auth", "UserAccessToken", nil)
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Expected status code %d, got %v", http.StatusUnauthorized, res.StatusCode)
+	}
+	res = doRequest(t, http.MethodGet, fmt.Sprintf("%s/v1/users/%d", ts.URL, u.ID), "", nil)
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected status code %d, got %v", http.StatusNotFound, res.StatusCode)
+	}
+}
+
+func TestDeleteOtherUserReturnForbidden(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	createUser(s, "UserAccessToken", &models.User{Username: "User"})
+	other := createUser(s, "OtherAccessToken", &models.User{Username: "Other"})
+	res := doRequest(t, http.MethodDelete, fmt.Sprintf("%s/v1/users/%d", ts.URL, other.ID), "UserAccessToken", nil)
+	if res.StatusCode != http.StatusForbidden {
+		t.Fatalf("Expected status code %d, got %v", http.StatusForbidden, res.StatusCode)
+	}
+}
+
+func TestLoginRestoresDeletedUser(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	at := login(t, ts, "Phone")
+	var u server.UserDTO
+	decodeBody(t, doRequest(t, http.MethodGet, ts.URL+"/v1/auth", at, nil), &u)
+	deleted, _ := s.UsersRepo.GetUser(u.ID)
+	res := doRequest(t, http.MethodDelete, fmt.Sprintf("%s/v1/users/%d", ts.URL, u.ID), at, nil)
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %v", http.StatusNoContent, res.StatusCode)
+	}
+
+	at = login(t, ts, "Phone")
+	var restored server.UserDTO
+	decodeBody(t, doRequest(t, http.MethodGet, ts.URL+"/v1/auth", at, nil), &restored)
+	if restored.ID != u.ID {
+		t.Fatalf("Expected to log in as restored user %d, got %d", u.ID, restored.ID)
+	}
+	if stored, _ := s.UsersRepo.GetUser(u.ID); stored.Version != deleted.Version+1 {
+		t.Fatalf("Expected version of restored user incremented, got %d", stored.Version)
+	}
+}
+
+func TestPurgeDeletedUsersAfterGracePeriod(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := createUser(s, "UserAccessToken", &models.User{Username: "User", Email: "user@nutrity.test"})
+	s.DiaryRepo.CreateDiaryEntry(&models.DiaryEntry{UserID: u.ID, Date: "2022-05-01", Meal: models.MealLunch, Quantity: 100, Unit: models.UnitGrams})
+	s.UsersRepo.CreateUserIdentity(&models.UserIdentity{UserID: u.ID, Provider: "google", Subject: "subject"})
+	doRequest(t, http.MethodDelete, fmt.Sprintf("%s/v1/users/%d", ts.URL, u.ID), "UserAccessToken", nil)
+
+	if err := s.PurgeDeletedUsers(time.Now()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := s.UsersRepo.GetUserByEmail("user@nutrity.test"); err != nil {
+		t.Fatalf("Expected user kept during grace period, got %v", err)
+	}
+
+	if err := s.PurgeDeletedUsers(time.Now().Add(server.AccountDeletionGracePeriod + time.Hour)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := s.UsersRepo.GetUserByEmail("user@nutrity.test"); err != repository.ErrNotFound {
+		t.Fatalf("Expected user purged, got %v", err)
+	}
+	if _, err := s.UsersRepo.GetUserByIdentity("google", "subject"); err != repository.ErrNotFound {
+		t.Fatalf("Expected identity purged, got %v", err)
+	}
+	entries, _ := s.DiaryRepo.GetDiaryEntries(u.ID, "2022-05-01")
+	if len(entries) != 0 {
+		t.Fatalf("Expected diary entries purged, got %v", entries)
+	}
+}
+
+func TestPurgeDeletedUsersContinuesAfterFailure(t *testing.T) {
+	s := NewTestServer()
+	mockUsersRepo := mocks.NewMockUsersRepository(gomock.NewController(t))
+	mockUsersRepo.EXPECT().GetUsersDeletedBefore(gomock.Any()).Return([]models.User{{ID: 1}, {ID: 2}}, nil)
+	mockUsersRepo.EXPECT().PurgeUser(uint(1)).Return(repository.ErrCouldNotDelete)
+	mockUsersRepo.EXPECT().PurgeUser(uint(2)).Return(nil)
+	s.UsersRepo = mockUsersRepo
+
+	if err := s.PurgeDeletedUsers(time.Now()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
@@ -3,22 +3,38 @@ package server
 import (
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/repository"
 	"github.com/gin-gonic/gin"
 	"golang.org/x/oauth2"
 )
 
 var (
-	state             = "nutrity-api"
-	googleUserInfoURL = "https://www.googleapis.com/oauth2/v3/userinfo"
-	AccessTokenName   = "AccessToken"
-	TokenLength       = 40
+	AccessTokenName = "AccessToken"
+	TokenLength     = 40
+	// LoginStateLifetime is the time users have to complete a login
+	// since they are sent to the provider.
+	LoginStateLifetime = 10 * time.Minute
+	// LoginStateCookie is the cookie holding the state of the login started by the browser,
+	// which callbacks require so that logins can't be completed by others.
+	LoginStateCookie = "nutrity_login_state"
 )
 
+var errEmailRegistered = errors.New("email registered to another user, log in as that user to link this identity")
+
 type IOauthConfig interface {
 	AuthCodeURL(string, ...oauth2.AuthCodeOption) string
 	Exchange(context.Context, string, ...oauth2.AuthCodeOption) (*oauth2.Token, error)
@@ -32,87 +48,385 @@ func generateSecureToken(length int) string {
 	return hex.EncodeToString(b)
 }
 
+// pkceChallenge returns the S256 PKCE code challenge of verifier.
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
 type tokenResponse struct {
-	AccessToken string `json:"AccessToken"`
-	TokenType   string `json:"TokenType"`
+	AccessToken       string `json:"access_token"`
+	TokenType         string `json:"token_type"`
+	ExpiresIn         int    `json:"expires_in"` // Seconds until access token expires
+	RefreshToken      string `json:"refresh_token"`
+	LegacyAccessToken string `json:"AccessToken"` // Deprecated alias of access_token
+	LegacyTokenType   string `json:"TokenType"`   // Deprecated alias of token_type
+}
+
+// MarshalJSON encodes r along with the deprecated aliases of its keys,
+// which clients read before tokens were named as in OAuth 2.0.
+func (r tokenResponse) MarshalJSON() ([]byte, error) {
+	type response tokenResponse
+	r.LegacyAccessToken, r.LegacyTokenType = r.AccessToken, r.TokenType
+	return json.Marshal(response(r))
 }
 
 // CurrentUser is the handler for GET requests to /auth
 // 	@ID GetCurrentUser
 // 	@Tags auth
 // 	@Success 200 {object} UserDTO
-// 	@Failure 403 {object} models.APIError
+// 	@Failure 401 {object} models.APIError
 // 	@Security AccessToken
+// 	@Security Bearer
 // 	@Router /auth [get]
 func (s *Server) GetCurrentUser(c *gin.Context) {
-	at := c.GetHeader(AccessTokenName)
-	u, err := s.userByAccessToken(at)
+	c.JSON(http.StatusOK, userDTOFromUser(authenticatedUser(c)))
+}
+
+// identityProvider returns the identity provider with name.
+//
+// Otherwise it responds with an error and returns false.
+func (s *Server) identityProvider(c *gin.Context, name string) (IdentityProvider, bool) {
+	provider, ok := s.identityProviders[name]
+	if !ok {
+		respondProblem(c, http.StatusNotFound, models.ErrorCodeProviderNotFound, "identity provider "+name+" not found")
+		return nil, false
+	}
+	return provider, true
+}
+
+// authCodeURL starts login ls with provider, returning the URL
+// users are sent to for granting access.
+//
+// Each login gets a random state, PKCE verifier and nonce,
+// stored until the callback consumes them.
+// If ls has a user ID, the callback links the identity to that user.
+func (s *Server) authCodeURL(provider IdentityProvider, ls *models.LoginState) (string, error) {
+	now := time.Now()
+	if err := s.LoginStatesRepo.DeleteExpiredLoginStates(now); err != nil {
+		return "", err
+	}
+	ls.State = generateSecureToken(TokenLength)
+	ls.CodeVerifier = generateSecureToken(TokenLength)
+	ls.Nonce = generateSecureToken(TokenLength)
+	ls.CreatedAt = now
+	ls.ExpiresAt = now.Add(LoginStateLifetime)
+	ls, err := s.LoginStatesRepo.CreateLoginState(ls)
 	if err != nil {
-		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "invalid access token"})
-		return
+		return "", err
 	}
-	c.JSON(http.StatusOK, userDTOFromUser(u))
+	return provider.AuthCodeURL(ls.State, oauth2.AccessTypeOffline,
+		oauth2.SetAuthURLParam("code_challenge", pkceChallenge(ls.CodeVerifier)),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+		oauth2.SetAuthURLParam("nonce", ls.Nonce)), nil
+}
+
+// setLoginStateCookie binds the login with state to the browser starting it.
+//
+// Otherwise anyone could start a login, or a link to their own user,
+// and send its URL to victims, whose identities would be logged in as
+// or linked to the user of the attacker.
+func (s *Server) setLoginStateCookie(c *gin.Context, state string) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(LoginStateCookie, state, int(LoginStateLifetime.Seconds()), "/v1/auth", "", strings.HasPrefix(s.hostname, "https://"), true)
+}
+
+// loginStartedByBrowser reports whether the login with state was started
+// by the browser completing it, clearing its login state cookie.
+func (s *Server) loginStartedByBrowser(c *gin.Context, state string) bool {
+	cookie, err := c.Cookie(LoginStateCookie)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(LoginStateCookie, "", -1, "/v1/auth", "", strings.HasPrefix(s.hostname, "https://"), true)
+	return err == nil && state != "" && subtle.ConstantTimeCompare([]byte(cookie), []byte(state)) == 1
+}
+
+// Login is the handler for GET requests to /auth/:provider/login
+// 	@ID Login
+// 	@Summary Log in
+// 	@Description Redirect to identity provider to log in, the entryway of its OAuth2 flow.
+// 	@Description Clients passing redirect_uri are redirected back to it once logged in
+// 	@Description with a one-time code in query parameter code, exchanged at /auth/token.
+// 	@Description Redirect URIs must be under an allowed custom scheme or web origin.
+// 	@Description Redirect URIs that aren't https origins require an S256 code_challenge.
+// 	@Description Sets a cookie binding the login to the browser, required by its callback.
+// 	@Tags auth
+// 	@Param provider path string true "Identity provider, such as google"
+// 	@Param redirect_uri query string false "URI of client redirected to with the code"
+// 	@Param state query string false "State of client, returned along with the code"
+// 	@Param code_challenge query string false "PKCE code challenge of client"
+// 	@Param code_challenge_method query string false "PKCE code challenge method" Enums(S256)
+// 	@Param device query string false "Label of device logging in"
+// 	@Success 307
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 404 {object} models.APIError
+// 	@Router /auth/{provider}/login [get]
+func (s *Server) Login(c *gin.Context) {
+	s.login(c, c.Param("provider"))
 }
 
 // LoginGoogle is the handler for GET requests to /auth/google-login
 // it's the entryway for Google OAuth2 flow.
 func (s *Server) LoginGoogle(c *gin.Context) {
-	url := s.googleConfig.AuthCodeURL(state, oauth2.AccessTypeOffline)
+	s.login(c, "google")
+}
+
+func (s *Server) login(c *gin.Context, name string) {
+	provider, ok := s.identityProvider(c, name)
+	if !ok {
+		return
+	}
+	ls := &models.LoginState{Provider: name}
+	if redirectURI := c.Query("redirect_uri"); redirectURI != "" {
+		if !s.redirectURIAllowed(redirectURI) {
+			respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "redirect_uri not allowed")
+			return
+		}
+		challenge := c.Query("code_challenge")
+		if challenge == "" && pkceRequired(redirectURI) {
+			respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "code_challenge required for redirect_uri that isn't an https origin")
+			return
+		}
+		if method := c.Query("code_challenge_method"); challenge != "" && method != "S256" {
+			respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "unsupported code_challenge_method: "+method)
+			return
+		}
+		ls.RedirectURI = redirectURI
+		ls.ClientState = c.Query("state")
+		ls.CodeChallenge = challenge
+		ls.Device = deviceLabel(c)
+	}
+	url, err := s.authCodeURL(provider, ls)
+	if err != nil {
+		respondError(c, fmt.Errorf("could not start login: %w", err))
+		return
+	}
+	s.setLoginStateCookie(c, ls.State)
 	c.Redirect(http.StatusTemporaryRedirect, url)
 }
 
+// Callback is the handler for GET requests to /auth/:provider/callback
+// 	@ID Callback
+// 	@Summary Complete login
+// 	@Description Complete OAuth2 flow of identity provider.
+// 	@Description Logins return the access and refresh tokens of a new session of the user,
+// 	@Description labeled with query parameter device or the user agent.
+// 	@Description Logins started with a redirect_uri redirect to it with a one-time code instead.
+// 	@Description Registers users logging in for the first time,
+// 	@Description or links them to the user with the same verified email.
+// 	@Description Restores deleted users that weren't purged yet.
+// 	@Description Identity links return the linked identity,
+// 	@Description or redirect to their redirect_uri with a one-time code exchanged at /auth/identities.
+// 	@Description Requires the cookie set by the browser that started the login or link,
+// 	@Description except for links started with a redirect_uri.
+// 	@Tags auth
+// 	@Param provider path string true "Identity provider, such as google"
+// 	@Param state query string true "State of login"
+// 	@Param code query string true "Authorization code"
+// 	@Param device query string false "Label of device logging in"
+// 	@Success 200 {object} tokenResponse
+// 	@Success 201 {object} UserIdentityDTO
+// 	@Success 302
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 404 {object} models.APIError
+// 	@Failure 409 {object} models.APIError
+// 	@Router /auth/{provider}/callback [get]
+func (s *Server) Callback(c *gin.Context) {
+	s.callback(c, c.Param("provider"))
+}
+
 // GoogleCallback is the handler for GET requests to /auth/google-callback
 // it's part of Google OAuth2 flow.
-//
-// Returns user's token.
 func (s *Server) GoogleCallback(c *gin.Context) {
-	if c.Request.URL.Query().Get("state") != state {
-		c.JSON(http.StatusBadRequest, &models.APIError{Code: http.StatusBadRequest, Message: "state did not match"})
+	s.callback(c, "google")
+}
+
+func (s *Server) callback(c *gin.Context, name string) {
+	provider, ok := s.identityProvider(c, name)
+	if !ok {
+		return
+	}
+	state := c.Request.URL.Query().Get("state")
+	ls, err := s.LoginStatesRepo.ConsumeLoginState(state)
+	if err == repository.ErrNotFound || (err == nil && (ls.Provider != name || !time.Now().Before(ls.ExpiresAt))) {
+		respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidState, "state did not match or expired")
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	// Links of clients are bound to them with PKCE instead,
+	// since the browser they send users to may not share their cookies
+	if !(ls.UserID != 0 && ls.RedirectURI != "") && !s.loginStartedByBrowser(c, state) {
+		respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidState, "login was not started by this browser")
 		return
 	}
 
 	authCode := c.Request.URL.Query().Get("code")
 	ctx := context.Background()
-	token, err := s.googleConfig.Exchange(ctx, authCode)
+	identity, err := provider.Identify(ctx, authCode, ls.Nonce, oauth2.SetAuthURLParam("code_verifier", ls.CodeVerifier))
+	if err != nil && ls.RedirectURI != "" {
+		redirectToClient(c, ls, url.Values{"error": {"access_denied"}})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusBadRequest, &models.APIError{Code: http.StatusBadRequest, Message: "failed to exchange token: " + err.Error()})
+		respondProblem(c, http.StatusBadRequest, models.ErrorCodeIdentityNotVerified, err.Error())
+		return
+	}
+	if ls.UserID != 0 && ls.RedirectURI != "" {
+		code, err := s.createAuthorizationCode(ls.UserID, ls, identity)
+		if err != nil {
+			respondError(c, fmt.Errorf("could not create authorization code: %w", err))
+			return
+		}
+		redirectToClient(c, ls, url.Values{"code": {code}})
+		return
+	}
+	if ls.UserID != 0 {
+		s.linkIdentity(c, ls.UserID, name, identity)
 		return
 	}
 
-	uinfo, err := s.googleClient.userInfoByAccessToken(token.AccessToken)
+	u, err := s.UsersRepo.GetUserByIdentity(name, identity.Subject)
+	if err == repository.ErrNotFound {
+		u, err = s.registerIdentity(name, identity)
+		if err == errEmailRegistered {
+			respondProblem(c, http.StatusConflict, models.ErrorCodeEmailRegistered, err.Error())
+			return
+		}
+		if err != nil {
+			respondError(c, fmt.Errorf("could not register user: %w", err))
+			return
+		}
+	}
 	if err != nil {
-		c.JSON(http.StatusBadRequest, &models.APIError{Code: http.StatusBadRequest, Message: "failed to get user info: " + err.Error()})
+		respondError(c, fmt.Errorf("could not get user: %w", err))
 		return
 	}
-
-	u, err := s.UsersRepo.GetUserByGoogleSub(uinfo.Sub)
-	tokenResponse := tokenResponse{
-		TokenType: "Bearer",
+	if err := s.restoreUser(u); err != nil {
+		respondError(c, fmt.Errorf("could not restore user: %w", err))
+		return
 	}
-	if u != nil {
-		tokenResponse.AccessToken = u.AccessToken
+	// Only emails the provider just verified promote users, since users can change their own
+	if u.Role != models.RoleAdministrator && s.identityRole(identity) == models.RoleAdministrator {
+		u.Role = models.RoleAdministrator
+		if u, err = s.UsersRepo.UpdateUser(u); err != nil {
+			respondError(c, fmt.Errorf("could not update user: %w", err))
+			return
+		}
 	}
+
+	if ls.RedirectURI != "" {
+		code, err := s.createAuthorizationCode(u.ID, ls, nil)
+		if err != nil {
+			respondError(c, fmt.Errorf("could not create authorization code: %w", err))
+			return
+		}
+		redirectToClient(c, ls, url.Values{"code": {code}})
+		return
+	}
+	tokens, _, err := s.createSession(u, deviceLabel(c))
 	if err != nil {
-		generatedToken := generateSecureToken(TokenLength)
-		u := &models.User{
-			GoogleSub:   uinfo.Sub,
-			AccessToken: generatedToken,
-			Username:    uinfo.Name,
-			Email:       uinfo.Email,
+		respondError(c, fmt.Errorf("could not create session: %w", err))
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, tokens)
+}
+
+// registerIdentity returns the user that identity of provider
+// logs in as for the first time.
+//
+// Identities with a verified email are linked to the user with that email
+// if the user verified it too, since both belong to whoever owns the mailbox.
+// Users that didn't verify it lose the email to the verified identity,
+// anyone can register an account with an email they don't own.
+// Otherwise a new user is registered, unless the email belongs to another user,
+// who can link the identity once logged in.
+func (s *Server) registerIdentity(provider string, identity *Identity) (*models.User, error) {
+	if identity.Email != "" {
+		u, err := s.UsersRepo.GetUserByEmail(identity.Email)
+		if err == nil && identity.EmailVerified && !u.EmailVerified {
+			u.Email = ""
+			if _, err := s.UsersRepo.UpdateUser(u); err != nil {
+				return nil, err
+			}
+			err = repository.ErrNotFound
+		}
+		if err == nil {
+			if !identity.EmailVerified {
+				return nil, errEmailRegistered
+			}
+			_, err := s.UsersRepo.CreateUserIdentity(&models.UserIdentity{
+				UserID:   u.ID,
+				Provider: provider,
+				Subject:  identity.Subject,
+				Email:    identity.Email,
+			})
+			if err != nil {
+				return nil, err
+			}
+			return u, nil
+		}
+		if err != repository.ErrNotFound {
+			return nil, err
 		}
-		s.UsersRepo.CreateUser(u)
-		tokenResponse.AccessToken = generatedToken
 	}
+	return s.UsersRepo.CreateUser(&models.User{
+		Role:          s.identityRole(identity),
+		Username:      identity.Name,
+		Email:         identity.Email,
+		EmailVerified: identity.EmailVerified && identity.Email != "",
+		Identities: []models.UserIdentity{{
+			Provider: provider,
+			Subject:  identity.Subject,
+			Email:    identity.Email,
+		}},
+	})
+}
 
-	c.JSON(http.StatusOK, tokenResponse)
+// roleForEmail returns the role bootstrapped for users with email,
+// or an empty string if it has none.
+func (s *Server) roleForEmail(email string) string {
+	if email == "" {
+		return ""
+	}
+	for _, e := range s.adminEmails {
+		if strings.EqualFold(strings.TrimSpace(e), email) {
+			return models.RoleAdministrator
+		}
+	}
+	if s.development {
+		return developmentRoles[email]
+	}
+	return ""
+}
+
+// identityRole returns the role bootstrapped for users logging in with identity,
+// which only comes from its email if the provider verified it.
+func (s *Server) identityRole(identity *Identity) string {
+	if !identity.EmailVerified {
+		return ""
+	}
+	return s.roleForEmail(identity.Email)
 }
 
-func (s *Server) userByAccessToken(at string) (*models.User, error) {
-	u, err := s.UsersRepo.GetUserByAccessToken(at)
+// authenticatedOwner returns the authenticated user
+// if it matches the user ID in path parameter id.
+//
+// Otherwise it responds with an error and returns false.
+func (s *Server) authenticatedOwner(c *gin.Context) (*models.User, bool) {
+	au := authenticatedUser(c)
+	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		return nil, err
+		respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "invalid id: "+err.Error())
+		return nil, false
+	}
+	if au.ID != uint(id) {
+		respondProblem(c, http.StatusForbidden, models.ErrorCodeForbidden, "id does not match authenticated user")
+		return nil, false
 	}
-	return u, nil
+	return au, true
 }
 
 // devOAuthAuthorize handles requests to /auth/authorize
@@ -122,11 +436,8 @@ func (s *Server) devOAuthAuthorize(c *gin.Context) {
 
 	u, err := url.Parse("http:://localhost/callback")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError,
-			models.APIError{
-				Code:    http.StatusInternalServerError,
-				Message: "could not make url",
-			})
+		respondError(c, fmt.Errorf("could not make url: %w", err))
+		return
 	}
 
 	v := url.Values{}
@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/server"
 )
 
 func TestGetCurrentUser(t *testing.T) {
@@ -18,8 +21,8 @@ func TestGetCurrentUser(t *testing.T) {
 		t.Fatalf("Expected no error, got %v", err)
 	}
 
-	if res.StatusCode != 403 {
-		t.Fatalf("Expected status code 403, got %v", res.StatusCode)
+	if res.StatusCode != 401 {
+		t.Fatalf("Expected status code 401, got %v", res.StatusCode)
 	}
 
 	val, ok := res.Header["Content-Type"]
@@ -28,7 +31,74 @@ func TestGetCurrentUser(t *testing.T) {
 		t.Fatalf("Expected Content-Type header to be set")
 	}
 
-	if val[0] != "application/json; charset=utf-8" {
-		t.Fatalf("Expected \"application/json; charset=utf-7\", got %s", val[0])
+	if val[0] != server.ProblemContentType {
+		t.Fatalf("Expected %q, got %s", server.ProblemContentType, val[0])
+	}
+}
+
+func TestLoginGoogleUsesRandomStateAndPKCE(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	res, err := client.Get(ts.URL + "/v1/auth/google-login")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	location, _ := url.Parse(res.Header.Get("Location"))
+	query := location.Query()
+	if query.Get("code_challenge") == "" || query.Get("code_challenge_method") != "S256" {
+		t.Fatalf("Expected S256 code challenge, got %v", query)
+	}
+	if other := startLogin(t, ts); other == query.Get("state") || other == "" {
+		t.Fatalf("Expected a different random state per login")
+	}
+}
+
+func TestGoogleCallbackRejectsUnknownState(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	startLogin(t, ts)
+	res := callback(t, ts, "nutrity-api", "Phone")
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status code %d, got %v", http.StatusBadRequest, res.StatusCode)
+	}
+}
+
+func TestGoogleCallbackRejectsReplayedState(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	state := startLogin(t, ts)
+	res := callback(t, ts, state, "Phone")
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+	res = callback(t, ts, state, "Phone")
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status code %d, got %v", http.StatusBadRequest, res.StatusCode)
+	}
+}
+
+func TestGoogleCallbackRejectsExpiredState(t *testing.T) {
+	lifetime := server.LoginStateLifetime
+	server.LoginStateLifetime = 0
+	defer func() { server.LoginStateLifetime = lifetime }()
+
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	res := callback(t, ts, startLogin(t, ts), "Phone")
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status code %d, got %v", http.StatusBadRequest, res.StatusCode)
 	}
 }
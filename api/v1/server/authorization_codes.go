@@ -0,0 +1,140 @@
+package server
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// AuthorizationCodeLifetime is the time clients have to exchange
+// authorization codes since they are redirected with them.
+var AuthorizationCodeLifetime = time.Minute
+
+var errInvalidAuthorizationCode = errors.New("invalid authorization code")
+
+// redirectURIAllowed reports whether clients can be redirected to uri,
+// which must be under an allowed custom scheme, such as nutrity://,
+// or web origin, such as https://app.example.com.
+func (s *Server) redirectURIAllowed(uri string) bool {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme == "" || u.User != nil || u.Fragment != "" {
+		return false
+	}
+	for _, allowed := range s.redirectURIs {
+		a, err := url.Parse(strings.TrimSpace(allowed))
+		if err != nil || !strings.EqualFold(a.Scheme, u.Scheme) {
+			continue
+		}
+		// Web origins only allow their host, only custom schemes are allowed as a whole
+		if a.Host == "" && a.Scheme != "http" && a.Scheme != "https" {
+			return true
+		}
+		if a.Host != "" && strings.EqualFold(a.Host, u.Host) {
+			return true
+		}
+	}
+	return false
+}
+
+// pkceRequired reports whether clients redirected to uri must use PKCE,
+// any app can register custom schemes and listen on http origins such as localhost.
+func pkceRequired(uri string) bool {
+	u, err := url.Parse(uri)
+	return err != nil || !strings.EqualFold(u.Scheme, "https")
+}
+
+// redirectToClient redirects to the client that started login ls
+// with params, along with the state of the client.
+func redirectToClient(c *gin.Context, ls *models.LoginState, params url.Values) {
+	u, err := url.Parse(ls.RedirectURI)
+	if err != nil {
+		respondError(c, fmt.Errorf("invalid redirect_uri: %w", err))
+		return
+	}
+	q := u.Query()
+	for k, v := range params {
+		q[k] = v
+	}
+	if ls.ClientState != "" {
+		q.Set("state", ls.ClientState)
+	}
+	u.RawQuery = q.Encode()
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, u.String())
+}
+
+// createAuthorizationCode returns a new one-time code the client that started
+// login ls exchanges for a session of user with userID,
+// or that started link ls exchanges to link identity if it's not nil.
+func (s *Server) createAuthorizationCode(userID uint, ls *models.LoginState, identity *Identity) (string, error) {
+	now := time.Now()
+	code := generateSecureToken(TokenLength)
+	ac := &models.AuthorizationCode{
+		CodeHash:      repository.HashToken(code),
+		UserID:        userID,
+		RedirectURI:   ls.RedirectURI,
+		CodeChallenge: ls.CodeChallenge,
+		Device:        ls.Device,
+		CreatedAt:     now,
+		ExpiresAt:     now.Add(AuthorizationCodeLifetime),
+	}
+	if identity != nil {
+		ac.Provider = ls.Provider
+		ac.Subject = identity.Subject
+		ac.Email = identity.Email
+	}
+	if _, err := s.LoginStatesRepo.CreateAuthorizationCode(ac); err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+// consumeAuthorizationCode returns the authorization code
+// if it's exchanged by the client it was issued to, which must prove it started
+// the login or link with the PKCE verifier of its code challenge if it sent one.
+func (s *Server) consumeAuthorizationCode(code string, redirectURI string, codeVerifier string) (*models.AuthorizationCode, error) {
+	ac, err := s.LoginStatesRepo.ConsumeAuthorizationCode(repository.HashToken(code))
+	if err == repository.ErrNotFound {
+		return nil, errInvalidAuthorizationCode
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !time.Now().Before(ac.ExpiresAt) || ac.RedirectURI != redirectURI {
+		return nil, errInvalidAuthorizationCode
+	}
+	if ac.CodeChallenge != "" && subtle.ConstantTimeCompare([]byte(pkceChallenge(codeVerifier)), []byte(ac.CodeChallenge)) != 1 {
+		return nil, errInvalidAuthorizationCode
+	}
+	return ac, nil
+}
+
+// exchangeAuthorizationCode creates a session of the user login code was issued to,
+// if it's exchanged by the client it was issued to.
+func (s *Server) exchangeAuthorizationCode(code string, redirectURI string, codeVerifier string) (tokenResponse, error) {
+	ac, err := s.consumeAuthorizationCode(code, redirectURI, codeVerifier)
+	if err != nil {
+		return tokenResponse{}, err
+	}
+	// Codes of links don't log in
+	if ac.Provider != "" {
+		return tokenResponse{}, errInvalidAuthorizationCode
+	}
+	u, err := s.UsersRepo.GetUser(ac.UserID)
+	if err == repository.ErrNotFound {
+		return tokenResponse{}, errInvalidAuthorizationCode
+	}
+	if err != nil {
+		return tokenResponse{}, err
+	}
+	tokens, _, err := s.createSession(u, ac.Device)
+	return tokens, err
+}
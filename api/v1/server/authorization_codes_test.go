@@ -0,0 +1,194 @@
+package server_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/server"
+)
+
+const (
+	testRedirectURI  = "nutrity://auth/callback"
+	testCodeVerifier = "code-verifier-of-the-native-client-that-logs-in"
+)
+
+func newRedirectTestServer() *server.Server {
+	return newTestServer(server.ServerConfig{
+		RedirectURIs: []string{"nutrity://", "http://localhost:3000"},
+	})
+}
+
+// clientLogin logs in through Google as a client redirected to redirectURI
+// with PKCE verifier testCodeVerifier, returning the URL it's redirected to.
+func clientLogin(t *testing.T, ts *httptest.Server, redirectURI string) *url.URL {
+	t.Helper()
+	sum := sha256.Sum256([]byte(testCodeVerifier))
+	q := url.Values{}
+	q.Set("redirect_uri", redirectURI)
+	q.Set("state", "client-state")
+	q.Set("code_challenge", base64.RawURLEncoding.EncodeToString(sum[:]))
+	q.Set("code_challenge_method", "S256")
+	q.Set("device", "Android")
+	state := startProviderLogin(t, ts, "/v1/auth/google/login?"+q.Encode())
+
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/v1/auth/google/callback?code=code&state="+url.QueryEscape(state), nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Set("Cookie", loginStateCookie(state))
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusFound {
+		t.Fatalf("Expected status code %d, got %v", http.StatusFound, res.StatusCode)
+	}
+	location, err := url.Parse(res.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return location
+}
+
+// exchangeCode exchanges authorization code for tokens.
+func exchangeCode(t *testing.T, ts *httptest.Server, code string, redirectURI string, codeVerifier string) *http.Response {
+	t.Helper()
+	res, err := http.PostForm(ts.URL+"/v1/auth/token", url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"code_verifier": {codeVerifier},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return res
+}
+
+func TestLoginRedirectsToClientWithCode(t *testing.T) {
+	s := newRedirectTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	location := clientLogin(t, ts, testRedirectURI)
+	if location.Scheme != "nutrity" || location.Host != "auth" || location.Path != "/callback" {
+		t.Fatalf("Expected redirect to %v, got %v", testRedirectURI, location)
+	}
+	if location.Query().Get("state") != "client-state" {
+		t.Fatalf("Expected state of client, got %v", location.Query().Get("state"))
+	}
+	code := location.Query().Get("code")
+
+	res := exchangeCode(t, ts, code, testRedirectURI, testCodeVerifier)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+	var tk tokens
+	decodeBody(t, res, &tk)
+	res = doRequest(t, http.MethodGet, ts.URL+"/v1/auth/sessions", tk.AccessToken, nil)
+	var sessions []server.SessionDTO
+	decodeBody(t, res, &sessions)
+	if len(sessions) != 1 || sessions[0].Device != "Android" {
+		t.Fatalf("Expected session of device that started login, got %+v", sessions)
+	}
+
+	// Codes are exchanged only once
+	res = exchangeCode(t, ts, code, testRedirectURI, testCodeVerifier)
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status code %d, got %v", http.StatusBadRequest, res.StatusCode)
+	}
+}
+
+func TestExchangeCodeRequiresClientProof(t *testing.T) {
+	s := newRedirectTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	tests := []struct {
+		name         string
+		redirectURI  string
+		codeVerifier string
+	}{
+		{"wrong verifier", testRedirectURI, "other-verifier"},
+		{"missing verifier", testRedirectURI, ""},
+		{"wrong redirect uri", "nutrity://other", testCodeVerifier},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code := clientLogin(t, ts, testRedirectURI).Query().Get("code")
+			res := exchangeCode(t, ts, code, tt.redirectURI, tt.codeVerifier)
+			if res.StatusCode != http.StatusBadRequest {
+				t.Fatalf("Expected status code %d, got %v", http.StatusBadRequest, res.StatusCode)
+			}
+		})
+	}
+}
+
+func TestExchangeCodeRejectsExpiredCode(t *testing.T) {
+	lifetime := server.AuthorizationCodeLifetime
+	server.AuthorizationCodeLifetime = 0
+	defer func() { server.AuthorizationCodeLifetime = lifetime }()
+
+	s := newRedirectTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	code := clientLogin(t, ts, testRedirectURI).Query().Get("code")
+	res := exchangeCode(t, ts, code, testRedirectURI, testCodeVerifier)
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status code %d, got %v", http.StatusBadRequest, res.StatusCode)
+	}
+}
+
+func TestLoginAllowsWebOrigins(t *testing.T) {
+	s := newRedirectTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	location := clientLogin(t, ts, "http://localhost:3000/login")
+	if location.Host != "localhost:3000" || location.Query().Get("code") == "" {
+		t.Fatalf("Expected redirect to web origin with code, got %v", location)
+	}
+}
+
+func TestLoginWithoutCodeChallengeRejectsRedirectURIsNotHTTPS(t *testing.T) {
+	s := newTestServer(server.ServerConfig{
+		RedirectURIs: []string{"nutrity://", "http://localhost:3000", "https://nutrity.test"},
+	})
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	for _, uri := range []string{testRedirectURI, "http://localhost:3000/login"} {
+		res := doRequest(t, http.MethodGet, ts.URL+"/v1/auth/google/login?redirect_uri="+url.QueryEscape(uri), "", nil)
+		decodeProblem(t, res, http.StatusBadRequest)
+	}
+	startProviderLogin(t, ts, "/v1/auth/google/login?redirect_uri="+url.QueryEscape("https://nutrity.test/login"))
+}
+
+func TestLoginRejectsRedirectURIsNotAllowed(t *testing.T) {
+	s := newRedirectTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	for _, uri := range []string{
+		"https://attacker.example.com/callback",
+		"http://localhost:3001/login",
+		"http://localhost:3000.attacker.example.com/login",
+		"nutrity-other://auth/callback",
+		"nutrity://auth/callback#fragment",
+	} {
+		res := doRequest(t, http.MethodGet, ts.URL+"/v1/auth/google/login?redirect_uri="+url.QueryEscape(uri), "", nil)
+		if res.StatusCode != http.StatusBadRequest {
+			t.Fatalf("Expected status code %d for %v, got %v", http.StatusBadRequest, uri, res.StatusCode)
+		}
+	}
+}
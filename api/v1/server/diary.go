@@ -0,0 +1,456 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// DateLayout is the format of dates in paths and diary entries.
+const DateLayout = "2006-01-02"
+
+type CreateDiaryEntryDTO struct {
+	Meal     string  `json:"meal" binding:"required,oneof=breakfast lunch dinner snack"`
+	FoodID   *uint   `json:"foodId"`
+	RecipeID *uint   `json:"recipeId"`
+	Quantity float64 `json:"quantity" binding:"required,gt=0"`
+	Unit     string  `json:"unit" binding:"required,oneof=g servings"`
+}
+
+type UpdateDiaryEntryDTO struct {
+	Meal     string  `json:"meal" binding:"required,oneof=breakfast lunch dinner snack"`
+	FoodID   *uint   `json:"foodId"`
+	RecipeID *uint   `json:"recipeId"`
+	Quantity float64 `json:"quantity" binding:"required,gt=0"`
+	Unit     string  `json:"unit" binding:"required,oneof=g servings"`
+}
+
+type NutrientsDTO struct {
+	Calories float64 `json:"calories"`
+	Carbs    float64 `json:"carbs"`
+	Fats     float64 `json:"fats"`
+	Proteins float64 `json:"proteins"`
+}
+
+func (n NutrientsDTO) plus(o NutrientsDTO) NutrientsDTO {
+	return NutrientsDTO{
+		Calories: n.Calories + o.Calories,
+		Carbs:    n.Carbs + o.Carbs,
+		Fats:     n.Fats + o.Fats,
+		Proteins: n.Proteins + o.Proteins,
+	}
+}
+
+func (n NutrientsDTO) minus(o NutrientsDTO) NutrientsDTO {
+	return n.plus(o.times(-1))
+}
+
+func (n NutrientsDTO) times(f float64) NutrientsDTO {
+	return NutrientsDTO{
+		Calories: n.Calories * f,
+		Carbs:    n.Carbs * f,
+		Fats:     n.Fats * f,
+		Proteins: n.Proteins * f,
+	}
+}
+
+type DiaryEntryDTO struct {
+	ID        uint         `json:"id"`
+	Date      string       `json:"date"`
+	Meal      string       `json:"meal"`
+	FoodID    *uint        `json:"foodId,omitempty"`
+	RecipeID  *uint        `json:"recipeId,omitempty"`
+	Name      string       `json:"name"` // Name of food or recipe
+	Quantity  float64      `json:"quantity"`
+	Unit      string       `json:"unit"`
+	Nutrients NutrientsDTO `json:"nutrients"`
+	// Other nutrients by nutrient key, only known for foods
+	Micronutrients map[string]float64 `json:"micronutrients,omitempty"`
+	Version        uint               `json:"version"` // Sent quoted in If-Match to update the entry only if it's unchanged
+}
+
+type DiaryDayDTO struct {
+	Date      string          `json:"date"`
+	Entries   []DiaryEntryDTO `json:"entries"`
+	Totals    NutrientsDTO    `json:"totals"`
+	Goals     NutrientsDTO    `json:"goals"`
+	Remaining NutrientsDTO    `json:"remaining"` // Negative when goals are exceeded
+	// Totals of other nutrients compared against their daily values
+	Micronutrients []NutrientIntakeDTO `json:"micronutrients"`
+}
+
+// goalNutrients returns the nutrients of goal g.
+func goalNutrients(g *models.NutritionGoal) NutrientsDTO {
+	return NutrientsDTO{
+		Calories: float64(g.Calories),
+		Carbs:    float64(g.Carbs),
+		Fats:     float64(g.Fats),
+		Proteins: float64(g.Proteins),
+	}
+}
+
+// entryNutrients returns the nutrients consumed in diary entry e.
+//
+// Foods are measured in grams or in servings of their serving size,
+// recipes are always measured in servings.
+func entryNutrients(e *models.DiaryEntry) NutrientsDTO {
+	switch {
+	case e.Food != nil:
+		grams := e.Quantity
+		if e.Unit == models.UnitServings {
+			grams *= e.Food.ServingSize
+		}
+		return NutrientsDTO{
+			Calories: e.Food.Calories,
+			Carbs:    e.Food.Carbs,
+			Fats:     e.Food.Fats,
+			Proteins: e.Food.Proteins,
+		}.times(grams / 100)
+	case e.Recipe != nil:
+		return NutrientsDTO{
+			Calories: e.Recipe.Calories,
+			Carbs:    e.Recipe.Carbs,
+			Fats:     e.Recipe.Fats,
+			Proteins: e.Recipe.Proteins,
+		}.times(e.Quantity)
+	default:
+		return NutrientsDTO{}
+	}
+}
+
+// entryMicronutrients returns the amount of each nutrient, besides macronutrients,
+// consumed in diary entry e.
+//
+// Recipes do not hold other nutrients.
+func entryMicronutrients(e *models.DiaryEntry) map[string]float64 {
+	if e.Food == nil || len(e.Food.Nutrients) == 0 {
+		return nil
+	}
+	grams := e.Quantity
+	if e.Unit == models.UnitServings {
+		grams *= e.Food.ServingSize
+	}
+	amounts := make(map[string]float64, len(e.Food.Nutrients))
+	for _, fn := range e.Food.Nutrients {
+		amounts[fn.NutrientKey] = fn.Amount * grams / 100
+	}
+	return amounts
+}
+
+func diaryEntryDTOFromDiaryEntry(e *models.DiaryEntry) DiaryEntryDTO {
+	eDTO := DiaryEntryDTO{
+		ID:             e.ID,
+		Date:           e.Date,
+		Meal:           e.Meal,
+		FoodID:         e.FoodID,
+		RecipeID:       e.RecipeID,
+		Quantity:       e.Quantity,
+		Unit:           e.Unit,
+		Nutrients:      entryNutrients(e),
+		Micronutrients: entryMicronutrients(e),
+		Version:        e.Version,
+	}
+	if e.Food != nil {
+		eDTO.Name = e.Food.Name
+	} else if e.Recipe != nil {
+		eDTO.Name = e.Recipe.Name
+	}
+	return eDTO
+}
+
+// diaryDate returns path parameter date if it is a valid date.
+//
+// Otherwise it responds with an error and returns false.
+func diaryDate(c *gin.Context) (string, bool) {
+	date := c.Param("date")
+	if _, err := time.Parse(DateLayout, date); err != nil {
+		respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "invalid date, expected format YYYY-MM-DD")
+		return "", false
+	}
+	return date, true
+}
+
+// setDiaryEntryItem validates the food or recipe referenced by an entry
+// and sets it on e. Invalid references are returned as validation problems.
+func (s *Server) setDiaryEntryItem(e *models.DiaryEntry, foodID *uint, recipeID *uint) error {
+	if (foodID == nil) == (recipeID == nil) {
+		return invalidDiaryEntry("exactly one of foodId or recipeId is required")
+	}
+	e.FoodID, e.Food, e.RecipeID, e.Recipe = nil, nil, nil, nil
+	if foodID != nil {
+		f, err := s.FoodsRepo.GetFood(*foodID)
+		if err == repository.ErrNotFound {
+			return invalidDiaryEntry("food with id " + strconv.Itoa(int(*foodID)) + " not found")
+		}
+		if err != nil {
+			return fmt.Errorf("could not get food: %w", err)
+		}
+		if e.Unit == models.UnitServings && f.ServingSize == 0 {
+			return invalidDiaryEntry("food has no serving size, quantity must be in grams")
+		}
+		e.FoodID, e.Food = foodID, f
+		return nil
+	}
+	r, err := s.RecipesRepo.GetRecipe(*recipeID)
+	if err == repository.ErrNotFound {
+		return invalidDiaryEntry("recipe with id " + strconv.Itoa(int(*recipeID)) + " not found")
+	}
+	if err != nil {
+		return fmt.Errorf("could not get recipe: %w", err)
+	}
+	if e.Unit != models.UnitServings {
+		return invalidDiaryEntry("recipes must be measured in servings")
+	}
+	e.RecipeID, e.Recipe = recipeID, r
+	return nil
+}
+
+// invalidDiaryEntry returns the validation problem of a diary entry described by reason.
+func invalidDiaryEntry(reason string) *problem {
+	return newProblem(http.StatusBadRequest, models.ErrorCodeValidationFailed, "invalid diary entry: "+reason)
+}
+
+// diaryEntryOfDay returns the entry in path parameter entryId
+// if it belongs to user u in date.
+//
+// Otherwise it responds with an error and returns false.
+func (s *Server) diaryEntryOfDay(c *gin.Context, u *models.User, date string) (*models.DiaryEntry, bool) {
+	entryID, err := strconv.Atoi(c.Param("entryId"))
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "invalid entry id: "+err.Error())
+		return nil, false
+	}
+	e, err := s.DiaryRepo.GetDiaryEntry(uint(entryID))
+	if err == repository.ErrNotFound || (err == nil && (e.UserID != u.ID || e.Date != date)) {
+		respondProblem(c, http.StatusNotFound, models.ErrorCodeDiaryEntryNotFound, "diary entry with provided id not found")
+		return nil, false
+	}
+	if err != nil {
+		respondError(c, err)
+		return nil, false
+	}
+	return e, true
+}
+
+// GetDiaryDay is the handler for GET requests to /users/:id/diary/:date
+// 	@ID GetDiaryDay
+// 	@Summary Get diary day
+// 	@Description Get diary entries of user in date, along with
+// 	@Description their nutrient totals compared against user's goals in effect that date
+// 	@Description and against daily values of other nutrients.
+// 	@Tags diary
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Param id path int true "User ID"
+// 	@Param date path string true "Date formatted as YYYY-MM-DD"
+// 	@Param If-None-Match header string false "ETag of day retrieved before, responds 304 if it's still current"
+// 	@Success 200 {object} DiaryDayDTO
+// 	@Header 200 {string} ETag "Tag of current content of day"
+// 	@Success 304 "Not modified"
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 401 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Router /users/{id}/diary/{date} [get]
+func (s *Server) GetDiaryDay(c *gin.Context) {
+	u, ok := s.authenticatedOwner(c)
+	if !ok {
+		return
+	}
+	date, ok := diaryDate(c)
+	if !ok {
+		return
+	}
+	entries, err := s.DiaryRepo.GetDiaryEntries(u.ID, date)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	g, err := s.goalAt(u, date)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	day := DiaryDayDTO{
+		Date:    date,
+		Entries: make([]DiaryEntryDTO, len(entries)),
+		Goals:   goalNutrients(g),
+	}
+	micronutrients := make(map[string]float64)
+	for i := range entries {
+		day.Entries[i] = diaryEntryDTOFromDiaryEntry(&entries[i])
+		day.Totals = day.Totals.plus(day.Entries[i].Nutrients)
+		for key, amount := range day.Entries[i].Micronutrients {
+			micronutrients[key] += amount
+		}
+	}
+	day.Remaining = day.Goals.minus(day.Totals)
+	if day.Micronutrients, err = s.nutrientIntakes(micronutrients); err != nil {
+		respondError(c, err)
+		return
+	}
+	if notModified(c, contentETag(day)) {
+		return
+	}
+	c.JSON(http.StatusOK, day)
+}
+
+// CreateDiaryEntry is the handler for POST requests to /users/:id/diary/:date
+// 	@ID CreateDiaryEntry
+// 	@Summary Create diary entry
+// 	@Description Log a food or recipe eaten by user in date.
+// 	@Description Foods are measured in grams (g) or servings, recipes in servings.
+// 	@Tags diary
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Param id path int true "User ID"
+// 	@Param date path string true "Date formatted as YYYY-MM-DD"
+// 	@Param entry body CreateDiaryEntryDTO true "Diary entry"
+// 	@Success 201 {object} DiaryEntryDTO
+// 	@Header 201 {string} ETag "Tag of version of entry"
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 401 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Router /users/{id}/diary/{date} [post]
+func (s *Server) CreateDiaryEntry(c *gin.Context) {
+	u, ok := s.authenticatedOwner(c)
+	if !ok {
+		return
+	}
+	date, ok := diaryDate(c)
+	if !ok {
+		return
+	}
+	var ce CreateDiaryEntryDTO
+	if err := c.ShouldBindJSON(&ce); err != nil {
+		respondError(c, validationProblem(&ce, "invalid diary entry", err))
+		return
+	}
+
+	e := &models.DiaryEntry{
+		UserID:   u.ID,
+		Date:     date,
+		Meal:     ce.Meal,
+		Quantity: ce.Quantity,
+		Unit:     ce.Unit,
+	}
+	if err := s.setDiaryEntryItem(e, ce.FoodID, ce.RecipeID); err != nil {
+		respondError(c, err)
+		return
+	}
+	e, err := s.DiaryRepo.CreateDiaryEntry(e)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Header("ETag", versionETag(e.Version))
+	c.JSON(http.StatusCreated, diaryEntryDTOFromDiaryEntry(e))
+}
+
+// UpdateDiaryEntry is the handler for PUT requests to /users/:id/diary/:date/:entryId
+// 	@ID UpdateDiaryEntry
+// 	@Summary Update diary entry
+// 	@Description Update matching diary entry with provided data.
+// 	@Tags diary
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Param id path int true "User ID"
+// 	@Param date path string true "Date formatted as YYYY-MM-DD"
+// 	@Param entryId path int true "Diary entry ID"
+// 	@Param If-Match header string false "ETag of entry retrieved, responds 412 if it changed since"
+// 	@Param entry body UpdateDiaryEntryDTO true "Diary entry"
+// 	@Success 200 {object} DiaryEntryDTO
+// 	@Header 200 {string} ETag "Tag of updated version of entry"
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 401 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 404 {object} models.APIError
+// 	@Failure 412 {object} models.APIError
+// 	@Router /users/{id}/diary/{date}/{entryId} [put]
+func (s *Server) UpdateDiaryEntry(c *gin.Context) {
+	u, ok := s.authenticatedOwner(c)
+	if !ok {
+		return
+	}
+	date, ok := diaryDate(c)
+	if !ok {
+		return
+	}
+	var ue UpdateDiaryEntryDTO
+	if err := c.ShouldBindJSON(&ue); err != nil {
+		respondError(c, validationProblem(&ue, "invalid diary entry", err))
+		return
+	}
+	e, ok := s.diaryEntryOfDay(c, u, date)
+	if !ok {
+		return
+	}
+	if preconditionFailed(c, versionETag(e.Version)) {
+		return
+	}
+
+	e.Meal = ue.Meal
+	e.Quantity = ue.Quantity
+	e.Unit = ue.Unit
+	if err := s.setDiaryEntryItem(e, ue.FoodID, ue.RecipeID); err != nil {
+		respondError(c, err)
+		return
+	}
+	e, err := s.DiaryRepo.UpdateDiaryEntry(e)
+	if err == repository.ErrVersionConflict {
+		respondVersionConflict(c, "")
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Header("ETag", versionETag(e.Version))
+	c.JSON(http.StatusOK, diaryEntryDTOFromDiaryEntry(e))
+}
+
+// DeleteDiaryEntry is the handler for DELETE requests to /users/:id/diary/:date/:entryId
+// 	@ID DeleteDiaryEntry
+// 	@Summary Delete diary entry
+// 	@Description Delete matching diary entry.
+// 	@Tags diary
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Param id path int true "User ID"
+// 	@Param date path string true "Date formatted as YYYY-MM-DD"
+// 	@Param entryId path int true "Diary entry ID"
+// 	@Param If-Match header string false "ETag of entry retrieved, responds 412 if it changed since"
+// 	@Success 204
+// 	@Failure 401 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 404 {object} models.APIError
+// 	@Failure 412 {object} models.APIError
+// 	@Router /users/{id}/diary/{date}/{entryId} [delete]
+func (s *Server) DeleteDiaryEntry(c *gin.Context) {
+	u, ok := s.authenticatedOwner(c)
+	if !ok {
+		return
+	}
+	date, ok := diaryDate(c)
+	if !ok {
+		return
+	}
+	e, ok := s.diaryEntryOfDay(c, u, date)
+	if !ok {
+		return
+	}
+	if preconditionFailed(c, versionETag(e.Version)) {
+		return
+	}
+	if err := s.DiaryRepo.DeleteDiaryEntry(e.ID); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
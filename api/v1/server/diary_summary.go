@@ -0,0 +1,122 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeficiencyThreshold is the percentage of daily value under which
+// the average daily intake of a nutrient is reported as deficient.
+var DeficiencyThreshold = 70.0
+
+type DiarySummaryDayDTO struct {
+	Date   string       `json:"date"`
+	Logged bool         `json:"logged"` // Whether user logged any entry that date
+	Totals NutrientsDTO `json:"totals"`
+	Goals  NutrientsDTO `json:"goals"`
+}
+
+type DiaryWeekDTO struct {
+	From       string               `json:"from"`
+	To         string               `json:"to"`
+	Days       []DiarySummaryDayDTO `json:"days"`
+	LoggedDays int                  `json:"loggedDays"`
+	Average    NutrientsDTO         `json:"average"` // Daily average of logged days
+	// Daily average of other nutrients in logged days compared against their daily values
+	Micronutrients []NutrientIntakeDTO `json:"micronutrients"`
+	Deficiencies   []string            `json:"deficiencies"` // Keys of nutrients below DeficiencyThreshold percent of daily value
+	Excesses       []string            `json:"excesses"`     // Keys of nutrients above their daily value upper limit
+}
+
+// GetDiaryWeek is the handler for GET requests to /users/:id/diary/:date/week
+// 	@ID GetDiaryWeek
+// 	@Summary Get diary week
+// 	@Description Get nutrient totals of user in the seven days ending in date,
+// 	@Description along with the daily average of logged days and the nutrients
+// 	@Description whose average intake is deficient or exceeds their limit.
+// 	@Description Only foods hold nutrients besides calories and macronutrients.
+// 	@Tags diary
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Param id path int true "User ID"
+// 	@Param date path string true "Last date formatted as YYYY-MM-DD"
+// 	@Success 200 {object} DiaryWeekDTO
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 401 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Router /users/{id}/diary/{date}/week [get]
+func (s *Server) GetDiaryWeek(c *gin.Context) {
+	u, ok := s.authenticatedOwner(c)
+	if !ok {
+		return
+	}
+	to, ok := diaryDate(c)
+	if !ok {
+		return
+	}
+	last, _ := time.Parse(DateLayout, to)
+	from := last.AddDate(0, 0, -6).Format(DateLayout)
+	entries, err := s.DiaryRepo.GetDiaryEntriesBetween(u.ID, from, to)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	week := DiaryWeekDTO{
+		From:         from,
+		To:           to,
+		Days:         make([]DiarySummaryDayDTO, 7),
+		Deficiencies: []string{},
+		Excesses:     []string{},
+	}
+	dayIndex := make(map[string]int, 7)
+	for i := range week.Days {
+		date := last.AddDate(0, 0, i-6).Format(DateLayout)
+		g, err := s.goalAt(u, date)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		week.Days[i] = DiarySummaryDayDTO{Date: date, Goals: goalNutrients(g)}
+		dayIndex[date] = i
+	}
+	micronutrients := make(map[string]float64)
+	for i := range entries {
+		day := &week.Days[dayIndex[entries[i].Date]]
+		day.Logged = true
+		day.Totals = day.Totals.plus(entryNutrients(&entries[i]))
+		for key, amount := range entryMicronutrients(&entries[i]) {
+			micronutrients[key] += amount
+		}
+	}
+
+	for _, day := range week.Days {
+		if day.Logged {
+			week.LoggedDays++
+			week.Average = week.Average.plus(day.Totals)
+		}
+	}
+	if week.LoggedDays > 0 {
+		week.Average = week.Average.times(1 / float64(week.LoggedDays))
+		for key := range micronutrients {
+			micronutrients[key] /= float64(week.LoggedDays)
+		}
+	}
+	if week.Micronutrients, err = s.nutrientIntakes(micronutrients); err != nil {
+		respondError(c, err)
+		return
+	}
+	// Weeks without entries say nothing about deficiencies
+	if week.LoggedDays > 0 {
+		for _, intake := range week.Micronutrients {
+			if intake.Limit && intake.PercentDailyValue > 100 {
+				week.Excesses = append(week.Excesses, intake.Key)
+			} else if !intake.Limit && intake.PercentDailyValue < DeficiencyThreshold {
+				week.Deficiencies = append(week.Deficiencies, intake.Key)
+			}
+		}
+	}
+	c.JSON(http.StatusOK, week)
+}
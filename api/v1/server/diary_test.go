@@ -0,0 +1,205 @@
+package server_test
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/repository"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/repository/mocks"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/server"
+	"github.com/golang/mock/gomock"
+)
+
+func uintPtr(v uint) *uint {
+	return &v
+}
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+func TestDiaryDayComputesTotalsAgainstGoals(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := createUser(s, "AccessToken", &models.User{Calories: 2000, Carbs: 250, Fats: 70, Proteins: 100})
+	apple, _ := s.FoodsRepo.CreateFood(&models.Food{Name: "Apple", ServingSize: 200, Calories: 50, Carbs: 14, Fats: 0.2, Proteins: 0.3})
+	pancakes, _ := s.RecipesRepo.CreateRecipe(&models.Recipe{Name: "Pancakes", Servings: 4, Calories: 227, Carbs: 28, Fats: 9.7, Proteins: 6.4, AuthorID: u.ID})
+
+	dayURL := fmt.Sprintf("%s/v1/users/%d/diary/2022-05-01", ts.URL, u.ID)
+	entries := []server.CreateDiaryEntryDTO{
+		{Meal: models.MealBreakfast, RecipeID: uintPtr(pancakes.ID), Quantity: 2, Unit: models.UnitServings},
+		{Meal: models.MealSnack, FoodID: uintPtr(apple.ID), Quantity: 150, Unit: models.UnitGrams},
+		{Meal: models.MealSnack, FoodID: uintPtr(apple.ID), Quantity: 1, Unit: models.UnitServings},
+	}
+	for _, e := range entries {
+		res := doRequest(t, http.MethodPost, dayURL, "AccessToken", e)
+		if res.StatusCode != http.StatusCreated {
+			t.Fatalf("Expected status code %d, got %v", http.StatusCreated, res.StatusCode)
+		}
+	}
+
+	res := doRequest(t, http.MethodGet, dayURL, "AccessToken", nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+	var day server.DiaryDayDTO
+	decodeBody(t, res, &day)
+	if len(day.Entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %v", len(day.Entries))
+	}
+	if day.Entries[0].Name != "Pancakes" || !almostEqual(day.Entries[0].Nutrients.Calories, 454) {
+		t.Fatalf("Expected 2 servings of Pancakes with 454 calories, got %v", day.Entries[0])
+	}
+	// 454 from pancakes, 75 from 150 g of apple and 100 from a 200 g serving of apple
+	if !almostEqual(day.Totals.Calories, 629) {
+		t.Fatalf("Expected 629 total calories, got %v", day.Totals.Calories)
+	}
+	if !almostEqual(day.Goals.Calories, 2000) || !almostEqual(day.Remaining.Calories, 1371) {
+		t.Fatalf("Expected goal 2000 and 1371 remaining calories, got %v and %v", day.Goals.Calories, day.Remaining.Calories)
+	}
+}
+
+func TestCreateDiaryEntryWithRecipeInGramsReturnBadRequest(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := createUser(s, "AccessToken", &models.User{})
+	r, _ := s.RecipesRepo.CreateRecipe(&models.Recipe{Name: "Pancakes", Servings: 4, AuthorID: u.ID})
+
+	res := doRequest(t, http.MethodPost, fmt.Sprintf("%s/v1/users/%d/diary/2022-05-01", ts.URL, u.ID), "AccessToken",
+		server.CreateDiaryEntryDTO{Meal: models.MealLunch, RecipeID: uintPtr(r.ID), Quantity: 100, Unit: models.UnitGrams})
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status code %d, got %v", http.StatusBadRequest, res.StatusCode)
+	}
+}
+
+func TestCreateDiaryEntryOfFoodNotRetrievedReturnInternalServerError(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := createUser(s, "AccessToken", &models.User{})
+	mockFoodsRepo := mocks.NewMockFoodsRepository(gomock.NewController(t))
+	mockFoodsRepo.EXPECT().GetFood(uint(1)).Return(nil, repository.ErrCouldNotRetrieve)
+	s.FoodsRepo = mockFoodsRepo
+
+	dayURL := fmt.Sprintf("%s/v1/users/%d/diary/2022-05-01", ts.URL, u.ID)
+	res := doRequest(t, http.MethodPost, dayURL, "AccessToken",
+		server.CreateDiaryEntryDTO{Meal: models.MealLunch, FoodID: uintPtr(1), Quantity: 100, Unit: models.UnitGrams})
+	if res.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("Expected status code %d, got %v", http.StatusInternalServerError, res.StatusCode)
+	}
+}
+
+func TestGetDiaryDayOfDifferentUserReturnForbidden(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	createUser(s, "AccessToken", &models.User{})
+	other := createUser(s, "OtherAccessToken", &models.User{})
+
+	res := doRequest(t, http.MethodGet, fmt.Sprintf("%s/v1/users/%d/diary/2022-05-01", ts.URL, other.ID), "AccessToken", nil)
+	if res.StatusCode != http.StatusForbidden {
+		t.Fatalf("Expected status code %d, got %v", http.StatusForbidden, res.StatusCode)
+	}
+}
+
+func TestUpdateAndDeleteDiaryEntry(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := createUser(s, "AccessToken", &models.User{})
+	apple, _ := s.FoodsRepo.CreateFood(&models.Food{Name: "Apple", ServingSize: 200, Calories: 50})
+	dayURL := fmt.Sprintf("%s/v1/users/%d/diary/2022-05-01", ts.URL, u.ID)
+
+	res := doRequest(t, http.MethodPost, dayURL, "AccessToken",
+		server.CreateDiaryEntryDTO{Meal: models.MealSnack, FoodID: uintPtr(apple.ID), Quantity: 100, Unit: models.UnitGrams})
+	var created server.DiaryEntryDTO
+	decodeBody(t, res, &created)
+
+	res = doRequest(t, http.MethodPut, fmt.Sprintf("%s/%d", dayURL, created.ID), "AccessToken",
+		server.UpdateDiaryEntryDTO{Meal: models.MealDinner, FoodID: uintPtr(apple.ID), Quantity: 300, Unit: models.UnitGrams})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+	var updated server.DiaryEntryDTO
+	decodeBody(t, res, &updated)
+	if updated.Meal != models.MealDinner || !almostEqual(updated.Nutrients.Calories, 150) {
+		t.Fatalf("Expected dinner entry with 150 calories, got %v", updated)
+	}
+
+	// Entry does not belong to a different date
+	res = doRequest(t, http.MethodDelete, fmt.Sprintf("%s/v1/users/%d/diary/2022-05-02/%d", ts.URL, u.ID, created.ID), "AccessToken", nil)
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected status code %d, got %v", http.StatusNotFound, res.StatusCode)
+	}
+	res = doRequest(t, http.MethodDelete, fmt.Sprintf("%s/%d", dayURL, created.ID), "AccessToken", nil)
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %v", http.StatusNoContent, res.StatusCode)
+	}
+}
+
+func TestDiaryETags(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := createUser(s, "AccessToken", &models.User{})
+	apple, _ := s.FoodsRepo.CreateFood(&models.Food{Name: "Apple", ServingSize: 200, Calories: 50})
+	dayURL := fmt.Sprintf("%s/v1/users/%d/diary/2022-05-01", ts.URL, u.ID)
+
+	res := doRequest(t, http.MethodPost, dayURL, "AccessToken",
+		server.CreateDiaryEntryDTO{Meal: models.MealSnack, FoodID: uintPtr(apple.ID), Quantity: 100, Unit: models.UnitGrams})
+	entryETag := res.Header.Get("ETag")
+	var created server.DiaryEntryDTO
+	decodeBody(t, res, &created)
+	entryURL := fmt.Sprintf("%s/%d", dayURL, created.ID)
+
+	res = doRequest(t, http.MethodGet, dayURL, "AccessToken", nil)
+	dayETag := res.Header.Get("ETag")
+	if dayETag == "" {
+		t.Fatalf("Expected ETag of day")
+	}
+	res = doRequestWithHeader(t, http.MethodGet, dayURL, "AccessToken", "If-None-Match", dayETag, nil)
+	if res.StatusCode != http.StatusNotModified {
+		t.Fatalf("Expected status code %d, got %v", http.StatusNotModified, res.StatusCode)
+	}
+
+	update := server.UpdateDiaryEntryDTO{Meal: models.MealDinner, FoodID: uintPtr(apple.ID), Quantity: 300, Unit: models.UnitGrams}
+	res = doRequestWithHeader(t, http.MethodPut, entryURL, "AccessToken", "If-Match", entryETag, update)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+	var updated server.DiaryEntryDTO
+	decodeBody(t, res, &updated)
+	if updated.Version == created.Version {
+		t.Fatalf("Expected version of entry incremented, got %v", updated.Version)
+	}
+	res = doRequestWithHeader(t, http.MethodPut, entryURL, "AccessToken", "If-Match", entryETag, update)
+	if res.StatusCode != http.StatusPreconditionFailed {
+		t.Fatalf("Expected status code %d, got %v", http.StatusPreconditionFailed, res.StatusCode)
+	}
+	res = doRequestWithHeader(t, http.MethodDelete, entryURL, "AccessToken", "If-Match", entryETag, nil)
+	if res.StatusCode != http.StatusPreconditionFailed {
+		t.Fatalf("Expected status code %d, got %v", http.StatusPreconditionFailed, res.StatusCode)
+	}
+
+	// Day changed along with its entry
+	res = doRequestWithHeader(t, http.MethodGet, dayURL, "AccessToken", "If-None-Match", dayETag, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+	res = doRequestWithHeader(t, http.MethodDelete, entryURL, "AccessToken", "If-Match", fmt.Sprintf(`"%d"`, updated.Version), nil)
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %v", http.StatusNoContent, res.StatusCode)
+	}
+}
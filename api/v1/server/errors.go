@@ -0,0 +1,105 @@
+package server
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"regexp"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/repository"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// ProblemContentType is the media type of error responses.
+	ProblemContentType = "application/problem+json"
+	// RequestIDHeader is the header requests are identified by,
+	// sent by clients or else generated, and echoed in responses.
+	RequestIDHeader = "X-Request-ID"
+	// requestIDKey is the context key of the ID of the request.
+	requestIDKey = "requestID"
+)
+
+// validRequestID matches request IDs sent by clients that are kept.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// problem is an error responded to clients as problem details.
+type problem struct {
+	status int
+	code   string
+	detail string
+	fields []models.FieldError
+}
+
+func (p *problem) Error() string {
+	return p.detail
+}
+
+// newProblem returns an error responded with status, code and detail.
+func newProblem(status int, code string, detail string) *problem {
+	return &problem{status: status, code: code, detail: detail}
+}
+
+// fieldProblem returns a problem caused by field of the request body,
+// described by message.
+func fieldProblem(status int, code string, detail string, field string, message string) *problem {
+	return &problem{status: status, code: code, detail: detail, fields: []models.FieldError{{Field: field, Message: message}}}
+}
+
+// errorProblem maps err to the problem responded for it: problems as they are,
+// repository errors to their status and other errors to internal errors,
+// whose details are not exposed to clients.
+func errorProblem(err error) (p *problem, internal bool) {
+	if errors.As(err, &p) {
+		return p, false
+	}
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return newProblem(http.StatusNotFound, models.ErrorCodeNotFound, "resource not found"), false
+	case errors.Is(err, repository.ErrVersionConflict):
+		return newProblem(http.StatusPreconditionFailed, models.ErrorCodePreconditionFailed, "resource was changed since it was retrieved, get it again to update it"), false
+	case errors.Is(err, repository.ErrAlreadyUsed):
+		return newProblem(http.StatusConflict, models.ErrorCodeConflict, err.Error()), false
+	}
+	return newProblem(http.StatusInternalServerError, models.ErrorCodeInternal, "internal server error, report it along with the request ID"), true
+}
+
+// respondError responds err as problem details and aborts the request.
+// Internal errors are logged along with the ID of the request.
+func respondError(c *gin.Context, err error) {
+	p, internal := errorProblem(err)
+	if internal {
+		log.Printf("request %s: %s %s: %v", c.GetString(requestIDKey), c.Request.Method, c.Request.URL.Path, err)
+	}
+	c.Header("Content-Type", ProblemContentType)
+	c.AbortWithStatusJSON(p.status, models.APIError{
+		Type:      "about:blank",
+		Title:     http.StatusText(p.status),
+		Status:    p.status,
+		Code:      p.code,
+		Detail:    p.detail,
+		Instance:  c.Request.URL.Path,
+		RequestID: c.GetString(requestIDKey),
+		Errors:    p.fields,
+	})
+}
+
+// respondProblem responds problem of status, with code and detail.
+func respondProblem(c *gin.Context, status int, code string, detail string) {
+	respondError(c, newProblem(status, code, detail))
+}
+
+// requestID identifies requests by the ID sent by clients in RequestIDHeader,
+// or else a generated one, echoing it in responses.
+func requestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = generateSecureToken(8)
+		}
+		c.Set(requestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
@@ -0,0 +1,115 @@
+package server_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/repository"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/repository/mocks"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/server"
+	"github.com/golang/mock/gomock"
+)
+
+// decodeProblem decodes problem details of res, which must have status.
+func decodeProblem(t *testing.T, res *http.Response, status int) models.APIError {
+	t.Helper()
+	if res.StatusCode != status {
+		t.Fatalf("Expected status code %d, got %v", status, res.StatusCode)
+	}
+	if ct := res.Header.Get("Content-Type"); ct != server.ProblemContentType {
+		t.Fatalf("Expected Content-Type %q, got %q", server.ProblemContentType, ct)
+	}
+	var p models.APIError
+	decodeBody(t, res, &p)
+	if p.Status != status || p.Title != http.StatusText(status) {
+		t.Fatalf("Expected problem of status %d, got %+v", status, p)
+	}
+	return p
+}
+
+func TestErrorsAreProblemDetails(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	res := doRequest(t, http.MethodGet, ts.URL+"/v1/users/404", "", nil)
+	p := decodeProblem(t, res, http.StatusNotFound)
+	if p.Code != models.ErrorCodeUserNotFound || p.Instance != "/v1/users/404" || p.Detail == "" {
+		t.Fatalf("Expected user_not_found problem of request path, got %+v", p)
+	}
+	if p.RequestID == "" || p.RequestID != res.Header.Get(server.RequestIDHeader) {
+		t.Fatalf("Expected request ID %q in problem, got %q", res.Header.Get(server.RequestIDHeader), p.RequestID)
+	}
+
+	p = decodeProblem(t, doRequest(t, http.MethodGet, ts.URL+"/v1/unknown", "", nil), http.StatusNotFound)
+	if p.Code != models.ErrorCodeNotFound {
+		t.Fatalf("Expected %v, got %v", models.ErrorCodeNotFound, p.Code)
+	}
+
+	u := createUser(s, "AccessToken", &models.User{Username: "User"})
+	res = doRequest(t, http.MethodPut, fmt.Sprintf("%s/v1/users/%d", ts.URL, u.ID), "AccessToken", server.UpdateUserDTO{Username: "User", Email: "not-an-email"})
+	p = decodeProblem(t, res, http.StatusBadRequest)
+	if p.Code != models.ErrorCodeValidationFailed || len(p.Errors) != 1 || p.Errors[0].Field != "email" {
+		t.Fatalf("Expected validation_failed problem with error of email, got %+v", p)
+	}
+}
+
+func TestRequestIDOfClientIsKept(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	res := doRequestWithHeader(t, http.MethodGet, ts.URL+"/v1/users/404", "", server.RequestIDHeader, "client-request-1", nil)
+	if p := decodeProblem(t, res, http.StatusNotFound); p.RequestID != "client-request-1" {
+		t.Fatalf("Expected request ID of client, got %q", p.RequestID)
+	}
+
+	// IDs that could forge log lines are replaced
+	res = doRequestWithHeader(t, http.MethodGet, ts.URL+"/v1/users/404", "", server.RequestIDHeader, "id with spaces", nil)
+	if p := decodeProblem(t, res, http.StatusNotFound); p.RequestID == "" || p.RequestID == "id with spaces" {
+		t.Fatalf("Expected generated request ID, got %q", p.RequestID)
+	}
+}
+
+func TestExpiredAccessTokenReturnsTokenExpired(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u, _ := s.UsersRepo.CreateUser(&models.User{Username: "User"})
+	s.SessionsRepo.CreateSession(&models.Session{
+		UserID:          u.ID,
+		TokenHash:       repository.HashToken("ExpiredToken"),
+		ExpiresAt:       time.Now().Add(time.Hour),
+		AccessExpiresAt: time.Now().Add(-time.Minute),
+	})
+
+	p := decodeProblem(t, doRequest(t, http.MethodGet, ts.URL+"/v1/auth", "ExpiredToken", nil), http.StatusUnauthorized)
+	if p.Code != models.ErrorCodeTokenExpired {
+		t.Fatalf("Expected %v, got %v", models.ErrorCodeTokenExpired, p.Code)
+	}
+	p = decodeProblem(t, doRequest(t, http.MethodGet, ts.URL+"/v1/auth", "UnknownToken", nil), http.StatusUnauthorized)
+	if p.Code != models.ErrorCodeUnauthenticated {
+		t.Fatalf("Expected %v, got %v", models.ErrorCodeUnauthenticated, p.Code)
+	}
+}
+
+func TestInternalErrorsAreNotExposed(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	mockUsersRepo := mocks.NewMockUsersRepository(gomock.NewController(t))
+	mockUsersRepo.EXPECT().GetUser(uint(1)).Return(nil, repository.ErrCouldNotRetrieve)
+	s.UsersRepo = mockUsersRepo
+
+	p := decodeProblem(t, doRequest(t, http.MethodGet, ts.URL+"/v1/users/1", "", nil), http.StatusInternalServerError)
+	if p.Code != models.ErrorCodeInternal || strings.Contains(p.Detail, repository.ErrCouldNotRetrieve.Error()) {
+		t.Fatalf("Expected internal_error problem without details of error, got %+v", p)
+	}
+}
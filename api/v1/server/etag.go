@@ -0,0 +1,82 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"github.com/gin-gonic/gin"
+)
+
+// versionETag returns the strong entity tag of version of a resource.
+func versionETag(version uint) string {
+	return `"` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+// contentETag returns the weak entity tag of representation v,
+// for resources without a version of their own, such as diary days.
+func contentETag(v interface{}) string {
+	data, _ := json.Marshal(v)
+	sum := sha256.Sum256(data)
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches reports whether etag is in header, a list of entity tags
+// as sent in If-Match and If-None-Match. Weak tags only match if weak is true.
+func etagMatches(header string, etag string, weak bool) bool {
+	if !weak && strings.HasPrefix(etag, "W/") {
+		return false
+	}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return true
+		}
+		if weak {
+			tag = strings.TrimPrefix(tag, "W/")
+			etag = strings.TrimPrefix(etag, "W/")
+		}
+		if tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified sets the ETag header to etag, the current tag of the resource read,
+// and responds 304 Not Modified, returning true, if the copy of the client
+// tagged in If-None-Match is still current.
+func notModified(c *gin.Context, etag string) bool {
+	c.Header("ETag", etag)
+	if inm := c.GetHeader("If-None-Match"); inm != "" && etagMatches(inm, etag, true) {
+		c.Status(http.StatusNotModified)
+		return true
+	}
+	return false
+}
+
+// preconditionFailed responds 412 Precondition Failed, returning true,
+// if the request has If-Match header that doesn't match etag,
+// the current tag of the resource written, meaning the client
+// would overwrite changes it hasn't seen.
+func preconditionFailed(c *gin.Context, etag string) bool {
+	if im := c.GetHeader("If-Match"); im != "" && !etagMatches(im, etag, false) {
+		respondVersionConflict(c, etag)
+		return true
+	}
+	return false
+}
+
+// respondVersionConflict responds 412 Precondition Failed to writes
+// of resources changed since the client retrieved them, along with etag,
+// the current tag of the resource, if it's known.
+func respondVersionConflict(c *gin.Context, etag string) {
+	if etag != "" {
+		c.Header("ETag", etag)
+	}
+	respondProblem(c, http.StatusPreconditionFailed, models.ErrorCodePreconditionFailed, "resource was changed since it was retrieved, get it again to update it")
+}
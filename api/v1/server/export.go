@@ -0,0 +1,231 @@
+package server
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Dates bounding every diary and water entry
+const (
+	firstDate = "0000-01-01"
+	lastDate  = "9999-12-31"
+)
+
+// exportFile is a file of user data exports, written both as JSON and as CSV.
+type exportFile struct {
+	name   string      // Name without extension
+	data   interface{} // Data encoded as JSON
+	header []string    // Columns of CSV
+	rows   [][]string
+}
+
+// ExportRecipeDTO is a recipe the user authored or added to their list.
+type ExportRecipeDTO struct {
+	RecipeDTO
+	Authored bool `json:"authored"`
+	Added    bool `json:"added"`
+}
+
+func formatUint(v uint) string {
+	return strconv.FormatUint(uint64(v), 10)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// formatOptionalUint returns an empty string if v is nil.
+func formatOptionalUint(v *uint) string {
+	if v == nil {
+		return ""
+	}
+	return formatUint(*v)
+}
+
+// formatOptionalFloat returns an empty string if v is nil.
+func formatOptionalFloat(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return formatFloat(*v)
+}
+
+// userExportFiles returns the files with all the data of u.
+func (s *Server) userExportFiles(u *models.User) ([]exportFile, error) {
+	profile := userDTOFromUser(u)
+	files := []exportFile{{
+		name:   "profile",
+		data:   profile,
+		header: []string{"id", "role", "username", "email", "emailVerified", "firstname", "lastname", "profileVisibility", "hideGoals", "birthDate", "sex", "height", "weight", "activityLevel", "weightGoal", "calories", "carbs", "fats", "proteins", "water"},
+		rows: [][]string{{formatUint(profile.ID), profile.Role, profile.Username, profile.Email, strconv.FormatBool(profile.EmailVerified),
+			profile.FirstName, profile.LastName, profile.ProfileVisibility, strconv.FormatBool(profile.HideGoals), profile.BirthDate, profile.Sex, formatFloat(profile.Height), formatFloat(profile.Weight),
+			profile.ActivityLevel, profile.WeightGoal, formatUint(profile.Calories), formatUint(profile.Carbs), formatUint(profile.Fats),
+			formatUint(profile.Proteins), formatUint(profile.Water)}},
+	}}
+
+	identities, err := s.UsersRepo.GetUserIdentities(u.ID)
+	if err != nil {
+		return nil, err
+	}
+	identityDTOs := make([]UserIdentityDTO, 0, len(identities))
+	identityRows := make([][]string, 0, len(identities))
+	for i := range identities {
+		dto := userIdentityDTOFromUserIdentity(&identities[i])
+		identityDTOs = append(identityDTOs, dto)
+		identityRows = append(identityRows, []string{formatUint(dto.ID), dto.Provider, dto.Email, dto.CreatedAt.Format(time.RFC3339)})
+	}
+	files = append(files, exportFile{"identities", identityDTOs, []string{"id", "provider", "email", "createdAt"}, identityRows})
+
+	goals, err := s.GoalsRepo.GetGoals(u.ID)
+	if err != nil {
+		return nil, err
+	}
+	goalDTOs := make([]GoalDTO, 0, len(goals))
+	goalRows := make([][]string, 0, len(goals))
+	for i := range goals {
+		dto := goalDTOFromGoal(&goals[i])
+		goalDTOs = append(goalDTOs, dto)
+		goalRows = append(goalRows, []string{dto.EffectiveDate, formatUint(dto.Calories), formatUint(dto.Carbs), formatUint(dto.Fats), formatUint(dto.Proteins), formatUint(dto.Water)})
+	}
+	files = append(files, exportFile{"goals", goalDTOs, []string{"effectiveDate", "calories", "carbs", "fats", "proteins", "water"}, goalRows})
+
+	entries, err := s.DiaryRepo.GetDiaryEntriesBetween(u.ID, firstDate, lastDate)
+	if err != nil {
+		return nil, err
+	}
+	entryDTOs := make([]DiaryEntryDTO, 0, len(entries))
+	entryRows := make([][]string, 0, len(entries))
+	for i := range entries {
+		dto := diaryEntryDTOFromDiaryEntry(&entries[i])
+		entryDTOs = append(entryDTOs, dto)
+		entryRows = append(entryRows, []string{formatUint(dto.ID), dto.Date, dto.Meal, formatOptionalUint(dto.FoodID), formatOptionalUint(dto.RecipeID),
+			dto.Name, formatFloat(dto.Quantity), dto.Unit, formatFloat(dto.Nutrients.Calories), formatFloat(dto.Nutrients.Carbs),
+			formatFloat(dto.Nutrients.Fats), formatFloat(dto.Nutrients.Proteins)})
+	}
+	files = append(files, exportFile{"diary", entryDTOs, []string{"id", "date", "meal", "foodId", "recipeId", "name", "quantity", "unit", "calories", "carbs", "fats", "proteins"}, entryRows})
+
+	added := map[uint]bool{}
+	addedIDs := make([]uint, 0, len(u.RecipesAdded))
+	for _, r := range u.RecipesAdded {
+		added[r.ID] = true
+		addedIDs = append(addedIDs, r.ID)
+	}
+	recipes, err := s.RecipesRepo.GetUserRecipes(u.ID, addedIDs)
+	if err != nil {
+		return nil, err
+	}
+	recipeDTOs := make([]ExportRecipeDTO, 0, len(recipes))
+	recipeRows := make([][]string, 0, len(recipes))
+	for i := range recipes {
+		authored := recipes[i].AuthorID == u.ID
+		dto := ExportRecipeDTO{RecipeDTO: recipeDTOFromRecipe(&recipes[i]), Authored: authored, Added: added[recipes[i].ID]}
+		recipeDTOs = append(recipeDTOs, dto)
+		recipeRows = append(recipeRows, []string{formatUint(dto.ID), dto.Name, formatUint(dto.Servings), formatFloat(dto.Calories), formatFloat(dto.Carbs),
+			formatFloat(dto.Fats), formatFloat(dto.Proteins), strconv.FormatBool(dto.Authored), strconv.FormatBool(dto.Added)})
+	}
+	files = append(files, exportFile{"recipes", recipeDTOs, []string{"id", "name", "servings", "calories", "carbs", "fats", "proteins", "authored", "added"}, recipeRows})
+
+	measurements, err := s.MeasurementsRepo.GetMeasurements(u.ID, time.Time{}, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	measurementDTOs := make([]MeasurementDTO, 0, len(measurements))
+	measurementRows := make([][]string, 0, len(measurements))
+	for i := range measurements {
+		dto := measurementDTOFromMeasurement(&measurements[i])
+		measurementDTOs = append(measurementDTOs, dto)
+		measurementRows = append(measurementRows, []string{formatUint(dto.ID), dto.TakenAt.Format(time.RFC3339), formatOptionalFloat(dto.Weight),
+			formatOptionalFloat(dto.BodyFat), formatOptionalFloat(dto.Waist), formatOptionalFloat(dto.Hips), formatOptionalFloat(dto.Chest), formatOptionalFloat(dto.Neck)})
+	}
+	files = append(files, exportFile{"measurements", measurementDTOs, []string{"id", "takenAt", "weight", "bodyFat", "waist", "hips", "chest", "neck"}, measurementRows})
+
+	water, err := s.WaterRepo.GetWaterEntriesBetween(u.ID, firstDate, lastDate)
+	if err != nil {
+		return nil, err
+	}
+	waterDTOs := make([]WaterEntryDTO, 0, len(water))
+	waterRows := make([][]string, 0, len(water))
+	for i := range water {
+		dto := waterEntryDTOFromWaterEntry(&water[i])
+		waterDTOs = append(waterDTOs, dto)
+		waterRows = append(waterRows, []string{formatUint(dto.ID), dto.Date, dto.LoggedAt.Format(time.RFC3339), formatUint(dto.Amount)})
+	}
+	files = append(files, exportFile{"water", waterDTOs, []string{"id", "date", "loggedAt", "amount"}, waterRows})
+	return files, nil
+}
+
+// writeExport writes files into a ZIP archive, each one as JSON and as CSV.
+func writeExport(files []exportFile) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range files {
+		w, err := zw.Create(f.name + ".json")
+		if err != nil {
+			return nil, err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(f.data); err != nil {
+			return nil, err
+		}
+		w, err = zw.Create(f.name + ".csv")
+		if err != nil {
+			return nil, err
+		}
+		cw := csv.NewWriter(w)
+		cw.Write(f.header)
+		cw.WriteAll(f.rows)
+		if err := cw.Error(); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ExportUser is the handler for GET requests to /users/:id/export
+// 	@ID ExportUser
+// 	@Summary Export user data
+// 	@Description Download a ZIP archive with all the data of authenticated user:
+// 	@Description profile, identities, goals, diary, recipes, measurements and water,
+// 	@Description each one as JSON and as CSV.
+// 	@Tags users
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Produce application/zip
+// 	@Param id path int true "User ID"
+// 	@Success 200 {file} file
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 401 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Router /users/{id}/export [get]
+func (s *Server) ExportUser(c *gin.Context) {
+	au, ok := s.authenticatedOwner(c)
+	if !ok {
+		return
+	}
+	files, err := s.userExportFiles(au)
+	if err != nil {
+		respondError(c, fmt.Errorf("could not export user data: %w", err))
+		return
+	}
+	archive, err := writeExport(files)
+	if err != nil {
+		respondError(c, fmt.Errorf("could not export user data: %w", err))
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="nutrity-export-`+formatUint(au.ID)+`.zip"`)
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "application/zip", archive)
+}
@@ -0,0 +1,86 @@
+package server_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/server"
+)
+
+func TestExportUser(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	other := createUser(s, "OtherAccessToken", &models.User{Username: "Other"})
+	tacos, _ := s.RecipesRepo.CreateRecipe(&models.Recipe{Name: "Tacos", Servings: 2, AuthorID: other.ID})
+	s.RecipesRepo.CreateRecipe(&models.Recipe{Name: "Soup", Servings: 2, AuthorID: other.ID})
+	u := createUser(s, "UserAccessToken", &models.User{Username: "User", RecipesAdded: []models.Recipe{*tacos}})
+	s.DiaryRepo.CreateDiaryEntry(&models.DiaryEntry{UserID: u.ID, Date: "2022-05-01", Meal: models.MealLunch, Quantity: 100, Unit: models.UnitGrams})
+	s.GoalsRepo.SaveGoal(&models.NutritionGoal{UserID: u.ID, EffectiveDate: "2022-05-01", Calories: 2000})
+	s.RecipesRepo.CreateRecipe(&models.Recipe{Name: "Salad", Servings: 1, AuthorID: u.ID})
+
+	res := doRequest(t, http.MethodGet, fmt.Sprintf("%s/v1/users/%d/export", ts.URL, u.ID), "UserAccessToken", nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+	if contentType := res.Header.Get("Content-Type"); contentType != "application/zip" {
+		t.Fatalf("Expected application/zip, got %v", contentType)
+	}
+	body, _ := io.ReadAll(res.Body)
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	for _, name := range []string{"profile", "identities", "goals", "diary", "recipes", "measurements", "water"} {
+		if files[name+".json"] == nil || files[name+".csv"] == nil {
+			t.Fatalf("Expected %v as JSON and CSV, got %v", name, files)
+		}
+	}
+
+	r, _ := files["profile.json"].Open()
+	var profile server.UserDTO
+	json.NewDecoder(r).Decode(&profile)
+	if profile.Username != "User" {
+		t.Fatalf("Expected profile of user, got %+v", profile)
+	}
+	r, _ = files["diary.csv"].Open()
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(rows) != 2 || rows[1][1] != "2022-05-01" {
+		t.Fatalf("Expected header and diary entry, got %v", rows)
+	}
+	r, _ = files["recipes.json"].Open()
+	var recipes []server.ExportRecipeDTO
+	json.NewDecoder(r).Decode(&recipes)
+	if len(recipes) != 2 || !recipes[0].Added || recipes[0].Authored || !recipes[1].Authored {
+		t.Fatalf("Expected added and authored recipes, got %+v", recipes)
+	}
+}
+
+func TestExportOtherUserReturnForbidden(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	createUser(s, "UserAccessToken", &models.User{Username: "User"})
+	other := createUser(s, "OtherAccessToken", &models.User{Username: "Other"})
+	res := doRequest(t, http.MethodGet, fmt.Sprintf("%s/v1/users/%d/export", ts.URL, other.ID), "UserAccessToken", nil)
+	if res.StatusCode != http.StatusForbidden {
+		t.Fatalf("Expected status code %d, got %v", http.StatusForbidden, res.StatusCode)
+	}
+}
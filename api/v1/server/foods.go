@@ -0,0 +1,326 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/repository"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+type CreateFoodDTO struct {
+	Name        string             `json:"name" binding:"required"`
+	Brand       string             `json:"brand"`
+	ServingSize float64            `json:"servingSize" binding:"min=0"`
+	Calories    float64            `json:"calories" binding:"min=0"`
+	Carbs       float64            `json:"carbs" binding:"min=0"`
+	Fats        float64            `json:"fats" binding:"min=0"`
+	Proteins    float64            `json:"proteins" binding:"min=0"`
+	Nutrients   map[string]float64 `json:"nutrients"` // Per 100 g by nutrient key, in unit of nutrient
+}
+
+type UpdateFoodDTO struct {
+	Name        string             `json:"name" binding:"required"`
+	Brand       string             `json:"brand"`
+	ServingSize float64            `json:"servingSize" binding:"min=0"`
+	Calories    float64            `json:"calories" binding:"min=0"`
+	Carbs       float64            `json:"carbs" binding:"min=0"`
+	Fats        float64            `json:"fats" binding:"min=0"`
+	Proteins    float64            `json:"proteins" binding:"min=0"`
+	Nutrients   map[string]float64 `json:"nutrients"` // Per 100 g by nutrient key, in unit of nutrient
+}
+
+type FoodDTO struct {
+	ID          uint               `json:"id,omitempty"`
+	Name        string             `json:"name"`
+	Brand       string             `json:"brand"`
+	ServingSize float64            `json:"servingSize"` // In grams
+	Calories    float64            `json:"calories"`    // Per 100 g
+	Carbs       float64            `json:"carbs"`       // Per 100 g
+	Fats        float64            `json:"fats"`        // Per 100 g
+	Proteins    float64            `json:"proteins"`    // Per 100 g
+	Nutrients   map[string]float64 `json:"nutrients"`   // Per 100 g by nutrient key, in unit of nutrient
+}
+
+type FoodsPageDTO struct {
+	Foods  []FoodDTO `json:"foods"`
+	Total  int64     `json:"total"`
+	Limit  int       `json:"limit"`
+	Offset int       `json:"offset"`
+}
+
+func foodDTOFromFood(f *models.Food) FoodDTO {
+	nutrients := make(map[string]float64, len(f.Nutrients))
+	for _, fn := range f.Nutrients {
+		nutrients[fn.NutrientKey] = fn.Amount
+	}
+	return FoodDTO{
+		ID:          f.ID,
+		Name:        f.Name,
+		Brand:       f.Brand,
+		ServingSize: f.ServingSize,
+		Calories:    f.Calories,
+		Carbs:       f.Carbs,
+		Fats:        f.Fats,
+		Proteins:    f.Proteins,
+		Nutrients:   nutrients,
+	}
+}
+
+// foodNutrients returns amounts, by nutrient key, as food nutrients.
+//
+// If a nutrient is unknown or its amount is negative
+// it responds with an error and returns false.
+func (s *Server) foodNutrients(c *gin.Context, amounts map[string]float64) ([]models.FoodNutrient, bool) {
+	var nutrients []models.FoodNutrient
+	for key, amount := range amounts {
+		if _, err := s.NutrientsRepo.GetNutrient(key); err == repository.ErrNotFound {
+			respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "unknown nutrient "+key)
+			return nil, false
+		} else if err != nil {
+			respondError(c, err)
+			return nil, false
+		}
+		if amount < 0 {
+			respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "amount of nutrient "+key+" must not be negative")
+			return nil, false
+		}
+		nutrients = append(nutrients, models.FoodNutrient{NutrientKey: key, Amount: amount})
+	}
+	return nutrients, true
+}
+
+// pageLimit reads limit query parameter,
+// applying DefaultPageLimit and MaxPageLimit.
+func pageLimit(c *gin.Context) (int, error) {
+	limit := DefaultPageLimit
+	if l := c.Query("limit"); l != "" {
+		var err error
+		if limit, err = strconv.Atoi(l); err != nil || limit < 1 {
+			return 0, errors.New("invalid limit query parameter")
+		}
+	}
+	if limit > MaxPageLimit {
+		limit = MaxPageLimit
+	}
+	return limit, nil
+}
+
+// pageParams reads limit and offset query parameters,
+// applying DefaultPageLimit and MaxPageLimit.
+func pageParams(c *gin.Context) (int, int, error) {
+	limit, err := pageLimit(c)
+	if err != nil {
+		return 0, 0, err
+	}
+	offset := 0
+	if o := c.Query("offset"); o != "" {
+		var err error
+		if offset, err = strconv.Atoi(o); err != nil || offset < 0 {
+			return 0, 0, errors.New("invalid offset query parameter")
+		}
+	}
+	return limit, offset, nil
+}
+
+// SearchFoods is the handler for GET requests to /foods
+// 	@ID SearchFoods
+// 	@Summary Search foods
+// 	@Description Get foods whose name or brand contain query, ignoring case.
+// 	@Description Foods whose name starts with query are listed first.
+// 	@Tags foods
+// 	@Param q query string false "Search query"
+// 	@Param limit query int false "Maximum number of foods to return" default(20)
+// 	@Param offset query int false "Number of foods to skip" default(0)
+// 	@Success 200 {object} FoodsPageDTO
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 500 {object} models.APIError
+// 	@Router /foods [get]
+func (s *Server) SearchFoods(c *gin.Context) {
+	limit, offset, err := pageParams(c)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, err.Error())
+		return
+	}
+	foods, total, err := s.FoodsRepo.SearchFoods(c.Query("q"), offset, limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	foodDTOs := make([]FoodDTO, len(foods))
+	for i := range foods {
+		foodDTOs[i] = foodDTOFromFood(&foods[i])
+	}
+	c.JSON(http.StatusOK, FoodsPageDTO{
+		Foods:  foodDTOs,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+// GetFood is the handler for GET requests to /foods/:id
+// 	@ID GetFood
+// 	@Summary Get food
+// 	@Description Get food with matching ID.
+// 	@Tags foods
+// 	@Param id path int true "Food ID"
+// 	@Success 200 {object} FoodDTO
+// 	@Failure 404 {object} models.APIError
+// 	@Router /foods/{id} [get]
+func (s *Server) GetFood(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "invalid id: "+err.Error())
+		return
+	}
+	f, err := s.FoodsRepo.GetFood(uint(id))
+	if err == repository.ErrNotFound {
+		respondProblem(c, http.StatusNotFound, models.ErrorCodeFoodNotFound, "food with provided id not found")
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, foodDTOFromFood(f))
+}
+
+// CreateFood is the handler for POST requests to /foods
+// 	@ID CreateFood
+// 	@Summary Create food
+// 	@Description Register a new food in the catalog.
+// 	@Description Requires Writer or Administrator role.
+// 	@Description Nutrients besides macronutrients are keyed by the keys listed in /nutrients.
+// 	@Tags foods
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Param food body CreateFoodDTO true "Food"
+// 	@Success 201 {object} FoodDTO
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 401 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Router /foods [post]
+func (s *Server) CreateFood(c *gin.Context) {
+	var cf CreateFoodDTO
+	if err := c.ShouldBindJSON(&cf); err != nil {
+		respondError(c, validationProblem(&cf, "invalid food", err))
+		return
+	}
+	nutrients, ok := s.foodNutrients(c, cf.Nutrients)
+	if !ok {
+		return
+	}
+	f, err := s.FoodsRepo.CreateFood(&models.Food{
+		Name:        cf.Name,
+		Brand:       cf.Brand,
+		ServingSize: cf.ServingSize,
+		Calories:    cf.Calories,
+		Carbs:       cf.Carbs,
+		Fats:        cf.Fats,
+		Proteins:    cf.Proteins,
+		Nutrients:   nutrients,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, foodDTOFromFood(f))
+}
+
+// UpdateFood is the handler for PUT requests to /foods/:id
+// 	@ID UpdateFood
+// 	@Summary Update food
+// 	@Description Update matching food with provided data.
+// 	@Description Requires Administrator role.
+// 	@Tags foods
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Param id path int true "Food ID"
+// 	@Param food body UpdateFoodDTO true "Food"
+// 	@Success 200 {object} FoodDTO
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 401 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 404 {object} models.APIError
+// 	@Router /foods/{id} [put]
+func (s *Server) UpdateFood(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "invalid id: "+err.Error())
+		return
+	}
+	var uf UpdateFoodDTO
+	if err := c.ShouldBindJSON(&uf); err != nil {
+		respondError(c, validationProblem(&uf, "invalid food", err))
+		return
+	}
+	nutrients, ok := s.foodNutrients(c, uf.Nutrients)
+	if !ok {
+		return
+	}
+	f, err := s.FoodsRepo.GetFood(uint(id))
+	if err == repository.ErrNotFound {
+		respondProblem(c, http.StatusNotFound, models.ErrorCodeFoodNotFound, "food with provided id not found")
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	f.Name = uf.Name
+	f.Brand = uf.Brand
+	f.ServingSize = uf.ServingSize
+	f.Calories = uf.Calories
+	f.Carbs = uf.Carbs
+	f.Fats = uf.Fats
+	f.Proteins = uf.Proteins
+	f.Nutrients = nutrients
+
+	f, err = s.FoodsRepo.UpdateFood(f)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, foodDTOFromFood(f))
+}
+
+// DeleteFood is the handler for DELETE requests to /foods/:id
+// 	@ID DeleteFood
+// 	@Summary Delete food
+// 	@Description Delete matching food from the catalog.
+// 	@Description Requires Administrator role.
+// 	@Tags foods
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Param id path int true "Food ID"
+// 	@Success 204
+// 	@Failure 401 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 404 {object} models.APIError
+// 	@Router /foods/{id} [delete]
+func (s *Server) DeleteFood(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "invalid id: "+err.Error())
+		return
+	}
+	err = s.FoodsRepo.DeleteFood(uint(id))
+	if err == repository.ErrNotFound {
+		respondProblem(c, http.StatusNotFound, models.ErrorCodeFoodNotFound, "food with provided id not found")
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,123 @@
+package server_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/server"
+)
+
+var mockFoods = []models.Food{
+	{Name: "Pineapple", ServingSize: 165, Calories: 50, Carbs: 13.1, Fats: 0.1, Proteins: 0.5},
+	{Name: "Apple", ServingSize: 182, Calories: 52, Carbs: 13.8, Fats: 0.2, Proteins: 0.3},
+	{Name: "Apple pie", Brand: "Grandma's", ServingSize: 125, Calories: 237, Carbs: 34, Fats: 11, Proteins: 1.9},
+	{Name: "Rice", Brand: "Happy Farms", ServingSize: 100, Calories: 130, Carbs: 28, Fats: 0.3, Proteins: 2.7},
+	{Name: "100% Orange juice", ServingSize: 248, Calories: 45, Carbs: 10.4, Fats: 0.2, Proteins: 0.7},
+}
+
+func TestSearchFoodsIgnoresCaseAndListsPrefixMatchesFirst(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	for _, f := range mockFoods {
+		s.FoodsRepo.CreateFood(&f)
+	}
+
+	res, err := http.Get(fmt.Sprintf("%s/v1/foods/?q=aPP", ts.URL))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+
+	var page server.FoodsPageDTO
+	err = json.NewDecoder(res.Body).Decode(&page)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if page.Total != 4 {
+		t.Fatalf("Expected 4 matching foods, got %v", page.Total)
+	}
+	expectedNames := []string{"Apple", "Apple pie", "Pineapple", "Rice"}
+	for i, name := range expectedNames {
+		if page.Foods[i].Name != name {
+			t.Fatalf("Expected %v at position %d, got %v", name, i, page.Foods[i].Name)
+		}
+	}
+}
+
+func TestSearchFoodsPaginates(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	for _, f := range mockFoods {
+		s.FoodsRepo.CreateFood(&f)
+	}
+
+	res, err := http.Get(fmt.Sprintf("%s/v1/foods/?q=app&limit=2&offset=2", ts.URL))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+
+	var page server.FoodsPageDTO
+	err = json.NewDecoder(res.Body).Decode(&page)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if page.Total != 4 || page.Limit != 2 || page.Offset != 2 {
+		t.Fatalf("Expected total 4, limit 2 and offset 2, got %v", page)
+	}
+	if len(page.Foods) != 2 || page.Foods[0].Name != "Pineapple" {
+		t.Fatalf("Expected second page starting with Pineapple, got %v", page.Foods)
+	}
+}
+
+// TestSearchFoodsTreatsWildcardsLiterally tests that LIKE wildcards
+// in query match only themselves.
+func TestSearchFoodsTreatsWildcardsLiterally(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	for _, f := range mockFoods {
+		s.FoodsRepo.CreateFood(&f)
+	}
+
+	res, err := http.Get(fmt.Sprintf("%s/v1/foods/?q=%%25", ts.URL))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var page server.FoodsPageDTO
+	err = json.NewDecoder(res.Body).Decode(&page)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if page.Total != 1 || page.Foods[0].Name != "100% Orange juice" {
+		t.Fatalf("Expected only 100%% Orange juice, got %v", page.Foods)
+	}
+}
+
+func TestSearchFoodsInvalidLimitReturnBadRequest(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	res, err := http.Get(fmt.Sprintf("%s/v1/foods/?limit=none", ts.URL))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status code %d, got %v", http.StatusBadRequest, res.StatusCode)
+	}
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+	"strconv"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/endpoints"
+)
+
+var (
+	githubUserURL       = "https://api.github.com/user"
+	githubUserEmailsURL = "https://api.github.com/user/emails"
+)
+
+type githubUserResponse struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+	Name  string `json:"name"`
+}
+
+type githubEmailResponse struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+// NewGitHubProvider returns a provider of GitHub accounts,
+// identified by their primary email.
+func NewGitHubProvider(clientID string, clientSecret string, redirectURL string) *OAuth2Provider {
+	return &OAuth2Provider{
+		Config: &oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			Endpoint:     endpoints.GitHub,
+			RedirectURL:  redirectURL,
+			Scopes:       []string{"read:user", "user:email"},
+		},
+		UserInfo: githubUserInfo,
+	}
+}
+
+func githubUserInfo(ctx context.Context, token *oauth2.Token) (*Identity, error) {
+	var user githubUserResponse
+	if err := getJSON(ctx, githubUserURL, token, &user); err != nil {
+		return nil, err
+	}
+	var emails []githubEmailResponse
+	if err := getJSON(ctx, githubUserEmailsURL, token, &emails); err != nil {
+		return nil, err
+	}
+	identity := &Identity{
+		Subject: strconv.FormatInt(user.ID, 10),
+		Name:    user.Name,
+	}
+	if identity.Name == "" {
+		identity.Name = user.Login
+	}
+	for _, e := range emails {
+		if e.Primary {
+			identity.Email = e.Email
+			identity.EmailVerified = e.Verified
+		}
+	}
+	return identity, nil
+}
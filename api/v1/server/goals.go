@@ -0,0 +1,221 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// NutritionGoalsDTO are the daily goals users set,
+// bounded to sane ranges wherever they're set.
+type NutritionGoalsDTO struct {
+	Calories uint `json:"calories" binding:"max=20000"`
+	Carbs    uint `json:"carbs" binding:"max=2000"`    // In grams
+	Fats     uint `json:"fats" binding:"max=2000"`     // In grams
+	Proteins uint `json:"proteins" binding:"max=2000"` // In grams
+	Water    uint `json:"water" binding:"max=20000"`   // In milliliters
+}
+
+type CreateGoalDTO struct {
+	EffectiveDate string `json:"effectiveDate"` // Formatted as YYYY-MM-DD, defaults to today
+	NutritionGoalsDTO
+}
+
+type GoalDTO struct {
+	EffectiveDate string `json:"effectiveDate"`
+	Calories      uint   `json:"calories"`
+	Carbs         uint   `json:"carbs"`
+	Fats          uint   `json:"fats"`
+	Proteins      uint   `json:"proteins"`
+	Water         uint   `json:"water"` // In milliliters
+}
+
+func goalDTOFromGoal(g *models.NutritionGoal) GoalDTO {
+	return GoalDTO{
+		EffectiveDate: g.EffectiveDate,
+		Calories:      g.Calories,
+		Carbs:         g.Carbs,
+		Fats:          g.Fats,
+		Proteins:      g.Proteins,
+		Water:         g.Water,
+	}
+}
+
+func today() string {
+	return time.Now().Format(DateLayout)
+}
+
+// goalAt returns the goal of u in effect at date.
+//
+// Users without goals history are evaluated against their current goals.
+func (s *Server) goalAt(u *models.User, date string) (*models.NutritionGoal, error) {
+	g, err := s.GoalsRepo.GetGoalAt(u.ID, date)
+	if err == repository.ErrNotFound {
+		return &models.NutritionGoal{
+			UserID:   u.ID,
+			Calories: u.Calories,
+			Carbs:    u.Carbs,
+			Fats:     u.Fats,
+			Proteins: u.Proteins,
+			Water:    u.Water,
+		}, nil
+	}
+	return g, err
+}
+
+// goalOfUser returns the current goals of u as its goal effective since date.
+func goalOfUser(u *models.User, date string) models.NutritionGoal {
+	return models.NutritionGoal{
+		UserID:        u.ID,
+		EffectiveDate: date,
+		Calories:      u.Calories,
+		Carbs:         u.Carbs,
+		Fats:          u.Fats,
+		Proteins:      u.Proteins,
+		Water:         u.Water,
+	}
+}
+
+// sameGoals reports whether a and b set the same goals, regardless of their dates.
+func sameGoals(a *models.NutritionGoal, b *models.NutritionGoal) bool {
+	return a.Calories == b.Calories && a.Carbs == b.Carbs && a.Fats == b.Fats && a.Proteins == b.Proteins && a.Water == b.Water
+}
+
+// goalsHistoryStart returns the goals to start the history of u with,
+// if it has none yet, along with the goal of u in effect today.
+//
+// Goals users had before their history are in effect since InitialGoalDate,
+// so days before any change keep being evaluated against them.
+func (s *Server) goalsHistoryStart(u *models.User) ([]models.NutritionGoal, *models.NutritionGoal, error) {
+	g, err := s.GoalsRepo.GetGoalAt(u.ID, today())
+	if err != repository.ErrNotFound {
+		return nil, g, err
+	}
+	initial := goalOfUser(u, models.InitialGoalDate)
+	if sameGoals(&initial, &models.NutritionGoal{}) {
+		return nil, &initial, nil
+	}
+	return []models.NutritionGoal{initial}, &initial, nil
+}
+
+// GetGoal is the handler for GET requests to /users/:id/goals
+// 	@ID GetGoal
+// 	@Summary Get goal
+// 	@Description Get daily goals of user in effect at date.
+// 	@Tags goals
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Param id path int true "User ID"
+// 	@Param at query string false "Date formatted as YYYY-MM-DD, defaults to today"
+// 	@Success 200 {object} GoalDTO
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 401 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Router /users/{id}/goals [get]
+func (s *Server) GetGoal(c *gin.Context) {
+	u, ok := s.authenticatedOwner(c)
+	if !ok {
+		return
+	}
+	at := c.DefaultQuery("at", today())
+	if _, err := time.Parse(DateLayout, at); err != nil {
+		respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "invalid at, expected format YYYY-MM-DD")
+		return
+	}
+	g, err := s.goalAt(u, at)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, goalDTOFromGoal(g))
+}
+
+// GetGoalsHistory is the handler for GET requests to /users/:id/goals/history
+// 	@ID GetGoalsHistory
+// 	@Summary Get goals history
+// 	@Description Get every goal of user ordered by effective date.
+// 	@Tags goals
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Param id path int true "User ID"
+// 	@Success 200 {array} GoalDTO
+// 	@Failure 401 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Router /users/{id}/goals/history [get]
+func (s *Server) GetGoalsHistory(c *gin.Context) {
+	u, ok := s.authenticatedOwner(c)
+	if !ok {
+		return
+	}
+	goals, err := s.GoalsRepo.GetGoals(u.ID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	goalDTOs := make([]GoalDTO, len(goals))
+	for i := range goals {
+		goalDTOs[i] = goalDTOFromGoal(&goals[i])
+	}
+	c.JSON(http.StatusOK, goalDTOs)
+}
+
+// CreateGoal is the handler for POST requests to /users/:id/goals
+// 	@ID CreateGoal
+// 	@Summary Create goal
+// 	@Description Set daily goals of user effective since provided date,
+// 	@Description replacing goals set for that same date.
+// 	@Description Diary days before that date keep being evaluated against previous goals.
+// 	@Tags goals
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Param id path int true "User ID"
+// 	@Param goal body CreateGoalDTO true "Goal"
+// 	@Success 201 {object} GoalDTO
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 401 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Router /users/{id}/goals [post]
+func (s *Server) CreateGoal(c *gin.Context) {
+	u, ok := s.authenticatedOwner(c)
+	if !ok {
+		return
+	}
+	var cg CreateGoalDTO
+	if err := c.ShouldBindJSON(&cg); err != nil {
+		respondError(c, validationProblem(&cg, "invalid goal", err))
+		return
+	}
+	if cg.EffectiveDate == "" {
+		cg.EffectiveDate = today()
+	}
+	if _, err := time.Parse(DateLayout, cg.EffectiveDate); err != nil {
+		respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "invalid effectiveDate, expected format YYYY-MM-DD")
+		return
+	}
+	// Current goals of users without history remain in effect until the new goal
+	goals, _, err := s.goalsHistoryStart(u)
+	if err != nil {
+		respondError(c, fmt.Errorf("could not get goals: %w", err))
+		return
+	}
+	goals = append(goals, models.NutritionGoal{
+		UserID:        u.ID,
+		EffectiveDate: cg.EffectiveDate,
+		Calories:      cg.Calories,
+		Carbs:         cg.Carbs,
+		Fats:          cg.Fats,
+		Proteins:      cg.Proteins,
+		Water:         cg.Water,
+	})
+	// Keep user's current goals in sync with its history
+	if _, err := s.UsersRepo.UpdateUserGoals(u, goals, today()); err != nil {
+		respondError(c, err)
+		return
+	}
+	g := &goals[len(goals)-1]
+	c.JSON(http.StatusCreated, goalDTOFromGoal(g))
+}
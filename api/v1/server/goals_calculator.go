@@ -0,0 +1,218 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	FormulaMifflinStJeor  = "mifflin-st-jeor"
+	FormulaHarrisBenedict = "harris-benedict"
+)
+
+// MacroSplit is the percentage of calories taken from each macronutrient.
+type MacroSplit struct {
+	Carbs    float64 `json:"carbs"`
+	Fats     float64 `json:"fats"`
+	Proteins float64 `json:"proteins"`
+}
+
+var (
+	// MacroSplitPresets are the macro splits that can be requested by name.
+	MacroSplitPresets = map[string]MacroSplit{
+		"balanced":     {Carbs: 50, Fats: 30, Proteins: 20},
+		"low-carb":     {Carbs: 25, Fats: 45, Proteins: 30},
+		"high-protein": {Carbs: 40, Fats: 25, Proteins: 35},
+		"keto":         {Carbs: 5, Fats: 75, Proteins: 20},
+	}
+	DefaultMacroSplitPreset = "balanced"
+	// ActivityFactors multiply BMR to estimate total daily energy expenditure.
+	ActivityFactors = map[string]float64{
+		models.ActivitySedentary:  1.2,
+		models.ActivityLight:      1.375,
+		models.ActivityModerate:   1.55,
+		models.ActivityActive:     1.725,
+		models.ActivityVeryActive: 1.9,
+	}
+	// WeightGoalAdjustments are the calories added to TDEE for each weight goal.
+	WeightGoalAdjustments = map[string]float64{
+		models.WeightGoalLose:     -500,
+		models.WeightGoalMaintain: 0,
+		models.WeightGoalGain:     300,
+	}
+	// MinimumCalories is the lowest daily calories goal that will be calculated.
+	MinimumCalories = 1200.0
+)
+
+type CalculateGoalsDTO struct {
+	Formula string      `json:"formula" binding:"omitempty,oneof=mifflin-st-jeor harris-benedict"` // Defaults to mifflin-st-jeor
+	Preset  string      `json:"preset"`                                                            // Name of macro split preset, defaults to balanced
+	Split   *MacroSplit `json:"split"`                                                             // Custom macro split, overrides preset
+	Save    bool        `json:"save"`                                                              // Save calculated goals as user's goals
+}
+
+type CalculatedGoalsDTO struct {
+	Formula  string     `json:"formula"`
+	BMR      float64    `json:"bmr"`  // Basal metabolic rate
+	TDEE     float64    `json:"tdee"` // Total daily energy expenditure
+	Split    MacroSplit `json:"split"`
+	Calories uint       `json:"calories"`
+	Carbs    uint       `json:"carbs"`
+	Fats     uint       `json:"fats"`
+	Proteins uint       `json:"proteins"`
+	Saved    bool       `json:"saved"`
+}
+
+// age returns the age in years at now of someone born on birthDate.
+func age(birthDate time.Time, now time.Time) int {
+	years := now.Year() - birthDate.Year()
+	if now.Month() < birthDate.Month() || (now.Month() == birthDate.Month() && now.Day() < birthDate.Day()) {
+		years--
+	}
+	return years
+}
+
+// bmr returns the basal metabolic rate of u calculated with formula.
+func bmr(u *models.User, formula string) (float64, error) {
+	var missing []string
+	birthDate, err := time.Parse(DateLayout, u.BirthDate)
+	if err != nil {
+		missing = append(missing, "birthDate")
+	}
+	if u.Sex != models.SexMale && u.Sex != models.SexFemale {
+		missing = append(missing, "sex")
+	}
+	if u.Height <= 0 {
+		missing = append(missing, "height")
+	}
+	if u.Weight <= 0 {
+		missing = append(missing, "weight")
+	}
+	if len(missing) > 0 {
+		return 0, errors.New("user profile is missing " + strings.Join(missing, ", "))
+	}
+
+	a := float64(age(birthDate, time.Now()))
+	if formula == FormulaHarrisBenedict {
+		// Revised by Roza and Shizgal in 1984
+		if u.Sex == models.SexMale {
+			return 88.362 + 13.397*u.Weight + 4.799*u.Height - 5.677*a, nil
+		}
+		return 447.593 + 9.247*u.Weight + 3.098*u.Height - 4.330*a, nil
+	}
+	b := 10*u.Weight + 6.25*u.Height - 5*a
+	if u.Sex == models.SexMale {
+		return b + 5, nil
+	}
+	return b - 161, nil
+}
+
+// calculateGoals returns the daily goals of u
+// calculated with formula and split.
+func calculateGoals(u *models.User, formula string, split MacroSplit) (*CalculatedGoalsDTO, error) {
+	b, err := bmr(u, formula)
+	if err != nil {
+		return nil, err
+	}
+	activityFactor, ok := ActivityFactors[u.ActivityLevel]
+	if !ok {
+		return nil, errors.New("user profile is missing activityLevel")
+	}
+	tdee := b * activityFactor
+	calories := math.Max(tdee+WeightGoalAdjustments[u.WeightGoal], MinimumCalories)
+
+	return &CalculatedGoalsDTO{
+		Formula:  formula,
+		BMR:      math.Round(b),
+		TDEE:     math.Round(tdee),
+		Split:    split,
+		Calories: uint(math.Round(calories)),
+		Carbs:    uint(math.Round(calories * split.Carbs / 100 / 4)),
+		Fats:     uint(math.Round(calories * split.Fats / 100 / 9)),
+		Proteins: uint(math.Round(calories * split.Proteins / 100 / 4)),
+	}, nil
+}
+
+// CalculateGoals is the handler for POST requests to /users/:id/goals/calculate
+// 	@ID CalculateGoals
+// 	@Summary Calculate goals
+// 	@Description Calculate daily goals from user's profile using Mifflin-St Jeor
+// 	@Description or Harris-Benedict formula and a macro split preset or custom split.
+// 	@Description Presets are balanced, low-carb, high-protein and keto.
+// 	@Description Goals are saved as user's goals effective since today if save is true.
+// 	@Tags goals
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Param id path int true "User ID"
+// 	@Param options body CalculateGoalsDTO true "Calculation options"
+// 	@Success 200 {object} CalculatedGoalsDTO
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 401 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Router /users/{id}/goals/calculate [post]
+func (s *Server) CalculateGoals(c *gin.Context) {
+	u, ok := s.authenticatedOwner(c)
+	if !ok {
+		return
+	}
+	var cg CalculateGoalsDTO
+	if err := c.ShouldBindJSON(&cg); err != nil {
+		respondError(c, validationProblem(&cg, "invalid calculation options", err))
+		return
+	}
+	if cg.Formula == "" {
+		cg.Formula = FormulaMifflinStJeor
+	}
+	var split MacroSplit
+	if cg.Split != nil {
+		split = *cg.Split
+		if split.Carbs < 0 || split.Fats < 0 || split.Proteins < 0 || math.Abs(split.Carbs+split.Fats+split.Proteins-100) > 0.01 {
+			respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "macro split percentages must add up to 100")
+			return
+		}
+	} else {
+		if cg.Preset == "" {
+			cg.Preset = DefaultMacroSplitPreset
+		}
+		preset, ok := MacroSplitPresets[cg.Preset]
+		if !ok {
+			respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "unknown macro split preset "+cg.Preset)
+			return
+		}
+		split = preset
+	}
+
+	goals, err := calculateGoals(u, cg.Formula, split)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, err.Error())
+		return
+	}
+	if cg.Save {
+		history, inEffect, err := s.goalsHistoryStart(u)
+		if err != nil {
+			respondError(c, fmt.Errorf("could not get goals: %w", err))
+			return
+		}
+		u.Calories = goals.Calories
+		u.Carbs = goals.Carbs
+		u.Fats = goals.Fats
+		u.Proteins = goals.Proteins
+		var changed []models.NutritionGoal
+		if current := goalOfUser(u, today()); !sameGoals(&current, inEffect) {
+			changed = append(history, current)
+		}
+		if _, err := s.UsersRepo.UpdateUserGoals(u, changed, ""); err != nil {
+			respondError(c, err)
+			return
+		}
+		goals.Saved = true
+	}
+	c.JSON(http.StatusOK, goals)
+}
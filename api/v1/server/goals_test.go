@@ -0,0 +1,256 @@
+package server_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/repository"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/server"
+)
+
+func TestGetGoalReturnsGoalInEffectAtDate(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := createUser(s, "AccessToken", &models.User{})
+	goalsURL := fmt.Sprintf("%s/v1/users/%d/goals", ts.URL, u.ID)
+
+	for _, g := range []server.CreateGoalDTO{
+		{EffectiveDate: "2022-01-01", NutritionGoalsDTO: server.NutritionGoalsDTO{Calories: 1800, Proteins: 90}},
+		{EffectiveDate: "2022-03-01", NutritionGoalsDTO: server.NutritionGoalsDTO{Calories: 2200, Proteins: 120}},
+	} {
+		res := doRequest(t, http.MethodPost, goalsURL, "AccessToken", g)
+		if res.StatusCode != http.StatusCreated {
+			t.Fatalf("Expected status code %d, got %v", http.StatusCreated, res.StatusCode)
+		}
+	}
+
+	expectedCalories := map[string]uint{
+		"2021-12-01": 1800, // Before first goal
+		"2022-02-01": 1800,
+		"2022-03-01": 2200,
+		"2022-05-01": 2200,
+	}
+	for at, calories := range expectedCalories {
+		res := doRequest(t, http.MethodGet, goalsURL+"?at="+at, "AccessToken", nil)
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+		}
+		var g server.GoalDTO
+		decodeBody(t, res, &g)
+		if g.Calories != calories {
+			t.Fatalf("Expected %v calories at %v, got %v", calories, at, g.Calories)
+		}
+	}
+
+	// Current goals of user are kept in sync
+	current, _ := s.UsersRepo.GetUser(u.ID)
+	if current.Calories != 2200 || current.Proteins != 120 {
+		t.Fatalf("Expected current goals of 2200 calories and 120 proteins, got %v and %v", current.Calories, current.Proteins)
+	}
+}
+
+func TestUpdateUserGoalsKeepsPreviousDiaryEvaluation(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := createUser(s, "AccessToken", &models.User{})
+	res := doRequest(t, http.MethodPost, fmt.Sprintf("%s/v1/users/%d/goals", ts.URL, u.ID), "AccessToken",
+		server.CreateGoalDTO{EffectiveDate: "2022-01-01", NutritionGoalsDTO: server.NutritionGoalsDTO{Calories: 1800}})
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %v", http.StatusCreated, res.StatusCode)
+	}
+
+	res = doRequest(t, http.MethodPut, fmt.Sprintf("%s/v1/users/%d", ts.URL, u.ID), "AccessToken",
+		server.UpdateUserDTO{Username: "Updated username", NutritionGoalsDTO: server.NutritionGoalsDTO{Calories: 2500}})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+
+	res = doRequest(t, http.MethodGet, fmt.Sprintf("%s/v1/users/%d/goals/history", ts.URL, u.ID), "AccessToken", nil)
+	var history []server.GoalDTO
+	decodeBody(t, res, &history)
+	if len(history) != 2 || history[1].Calories != 2500 {
+		t.Fatalf("Expected 2 goals ending with 2500 calories, got %v", history)
+	}
+
+	res = doRequest(t, http.MethodGet, fmt.Sprintf("%s/v1/users/%d/diary/2022-02-01", ts.URL, u.ID), "AccessToken", nil)
+	var day server.DiaryDayDTO
+	decodeBody(t, res, &day)
+	if day.Goals.Calories != 1800 {
+		t.Fatalf("Expected diary day evaluated against 1800 calories, got %v", day.Goals.Calories)
+	}
+}
+
+func TestCreateBackdatedGoalOfUserWithoutHistoryBecomesCurrent(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := createUser(s, "AccessToken", &models.User{Calories: 2000})
+	res := doRequest(t, http.MethodPost, fmt.Sprintf("%s/v1/users/%d/goals", ts.URL, u.ID), "AccessToken",
+		server.CreateGoalDTO{EffectiveDate: "2022-01-01", NutritionGoalsDTO: server.NutritionGoalsDTO{Calories: 1800}})
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %v", http.StatusCreated, res.StatusCode)
+	}
+
+	current, _ := s.UsersRepo.GetUser(u.ID)
+	if current.Calories != 1800 {
+		t.Fatalf("Expected current goal of 1800 calories, got %v", current.Calories)
+	}
+	// Previous goals keep evaluating days before the new goal
+	goal, _ := s.GoalsRepo.GetGoalAt(u.ID, "2021-12-01")
+	if goal.EffectiveDate != models.InitialGoalDate || goal.Calories != 2000 {
+		t.Fatalf("Expected initial goal of 2000 calories, got %+v", goal)
+	}
+}
+
+func TestUpdateUserGoalsOfOutdatedVersionDoesNotRecordGoals(t *testing.T) {
+	s := NewTestServer()
+
+	u := createUser(s, "AccessToken", &models.User{})
+	outdated := *u
+	s.UsersRepo.UpdateUser(u)
+
+	outdated.Calories = 2500
+	_, err := s.UsersRepo.UpdateUserGoals(&outdated, []models.NutritionGoal{{EffectiveDate: "2022-05-01", Calories: 2500}}, "")
+	if err != repository.ErrVersionConflict {
+		t.Fatalf("Expected %v, got %v", repository.ErrVersionConflict, err)
+	}
+	if goals, _ := s.GoalsRepo.GetGoals(u.ID); len(goals) != 0 {
+		t.Fatalf("Expected no goals recorded, got %v", goals)
+	}
+}
+
+func TestCreateGoalOutOfRangeReturnFieldErrors(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := createUser(s, "AccessToken", &models.User{})
+	res := doRequest(t, http.MethodPost, fmt.Sprintf("%s/v1/users/%d/goals", ts.URL, u.ID), "AccessToken",
+		server.CreateGoalDTO{NutritionGoalsDTO: server.NutritionGoalsDTO{Calories: 50000, Proteins: 5000}})
+	apiErr := decodeProblem(t, res, http.StatusBadRequest)
+	if len(apiErr.Errors) != 2 || apiErr.Errors[0].Field != "calories" || apiErr.Errors[1].Field != "proteins" {
+		t.Fatalf("Expected errors of calories and proteins, got %+v", apiErr.Errors)
+	}
+	if goals, _ := s.GoalsRepo.GetGoals(u.ID); len(goals) != 0 {
+		t.Fatalf("Expected no goal created, got %v", goals)
+	}
+}
+
+func TestCalculateGoalsWithMifflinStJeor(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := createUser(s, "AccessToken", &models.User{
+		BirthDate:     time.Now().AddDate(-30, 0, -1).Format(server.DateLayout),
+		Sex:           models.SexMale,
+		Height:        180,
+		Weight:        80,
+		ActivityLevel: models.ActivityModerate,
+		WeightGoal:    models.WeightGoalMaintain,
+	})
+
+	res := doRequest(t, http.MethodPost, fmt.Sprintf("%s/v1/users/%d/goals/calculate", ts.URL, u.ID), "AccessToken",
+		server.CalculateGoalsDTO{Preset: "balanced", Save: true})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+	var goals server.CalculatedGoalsDTO
+	decodeBody(t, res, &goals)
+	// BMR = 10 * 80 + 6.25 * 180 - 5 * 30 + 5 = 1780, TDEE = 1780 * 1.55 = 2759
+	if goals.BMR != 1780 || goals.Calories != 2759 {
+		t.Fatalf("Expected BMR 1780 and 2759 calories, got %v and %v", goals.BMR, goals.Calories)
+	}
+	if goals.Carbs != 345 || goals.Fats != 92 || goals.Proteins != 138 {
+		t.Fatalf("Expected 345 carbs, 92 fats and 138 proteins, got %v, %v and %v", goals.Carbs, goals.Fats, goals.Proteins)
+	}
+
+	saved, _ := s.UsersRepo.GetUser(u.ID)
+	if saved.Calories != 2759 || saved.Proteins != 138 {
+		t.Fatalf("Expected saved goals of 2759 calories and 138 proteins, got %v and %v", saved.Calories, saved.Proteins)
+	}
+}
+
+func TestCalculateGoalsWithHarrisBenedictAndCustomSplit(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := createUser(s, "AccessToken", &models.User{
+		Calories:      2000,
+		BirthDate:     time.Now().AddDate(-40, 0, -1).Format(server.DateLayout),
+		Sex:           models.SexFemale,
+		Height:        165,
+		Weight:        70,
+		ActivityLevel: models.ActivitySedentary,
+		WeightGoal:    models.WeightGoalLose,
+	})
+
+	res := doRequest(t, http.MethodPost, fmt.Sprintf("%s/v1/users/%d/goals/calculate", ts.URL, u.ID), "AccessToken",
+		server.CalculateGoalsDTO{Formula: server.FormulaHarrisBenedict, Split: &server.MacroSplit{Carbs: 40, Fats: 30, Proteins: 30}})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+	var goals server.CalculatedGoalsDTO
+	decodeBody(t, res, &goals)
+	// BMR = 447.593 + 9.247 * 70 + 3.098 * 165 - 4.330 * 40 = 1432.853
+	// Calories = 1432.853 * 1.2 - 500 = 1219.4236
+	if goals.BMR != 1433 || goals.Calories != 1219 || goals.Proteins != 91 {
+		t.Fatalf("Expected BMR 1433, 1219 calories and 91 proteins, got %v, %v and %v", goals.BMR, goals.Calories, goals.Proteins)
+	}
+
+	notSaved, _ := s.UsersRepo.GetUser(u.ID)
+	if notSaved.Calories != 2000 {
+		t.Fatalf("Expected goals not to be saved, got %v calories", notSaved.Calories)
+	}
+}
+
+func TestCalculateGoalsWithCustomSplitIgnoresPreset(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := createUser(s, "AccessToken", &models.User{
+		BirthDate:     time.Now().AddDate(-30, 0, -1).Format(server.DateLayout),
+		Sex:           models.SexMale,
+		Height:        180,
+		Weight:        80,
+		ActivityLevel: models.ActivityModerate,
+		WeightGoal:    models.WeightGoalMaintain,
+	})
+
+	split := server.MacroSplit{Carbs: 40, Fats: 30, Proteins: 30}
+	res := doRequest(t, http.MethodPost, fmt.Sprintf("%s/v1/users/%d/goals/calculate", ts.URL, u.ID), "AccessToken",
+		server.CalculateGoalsDTO{Preset: "unknown", Split: &split})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+	var goals server.CalculatedGoalsDTO
+	decodeBody(t, res, &goals)
+	if goals.Split != split {
+		t.Fatalf("Expected custom split %v, got %v", split, goals.Split)
+	}
+}
+
+func TestCalculateGoalsWithIncompleteProfileReturnBadRequest(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := createUser(s, "AccessToken", &models.User{Sex: models.SexMale})
+
+	res := doRequest(t, http.MethodPost, fmt.Sprintf("%s/v1/users/%d/goals/calculate", ts.URL, u.ID), "AccessToken",
+		server.CalculateGoalsDTO{})
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status code %d, got %v", http.StatusBadRequest, res.StatusCode)
+	}
+}
@@ -1,52 +1,52 @@
 package server
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
-	"net/http"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"golang.org/x/oauth2"
 )
 
-type googleUserInfoResponse struct {
-	Sub           string `json:"sub"`
-	Name          string `json:"name"`
-	GivenName     string `json:"given_name"`
-	FamilyName    string `json:"family_name"`
-	Picture       string `json:"picture"`
-	Email         string `json:"email"`
-	EmailVerified bool   `json:"email_verified"`
-	Locale        string `json:"locale"`
+// mockUsersInfo are the identities MockUserInfo returns for each access token.
+var mockUsersInfo = map[string]Identity{
+	"AccessToken":   {Subject: "123123213", Name: "Mock User", Email: "mock@nutrity.test", EmailVerified: true},
+	"Administrator": {Subject: "administrator", Name: "Mock Administrator", Email: "administrator@nutrity.test", EmailVerified: true},
+	"Writer":        {Subject: "writer", Name: "Mock Writer", Email: "writer@nutrity.test", EmailVerified: true},
+	"Reader":        {Subject: "reader", Name: "Mock Reader", Email: "reader@nutrity.test", EmailVerified: true},
 }
 
-type IGoogleClient interface {
-	userInfoByAccessToken(string) (*googleUserInfoResponse, error)
+// developmentRoles are the roles given during development
+// to users registered with MockUserInfo access tokens.
+var developmentRoles = map[string]string{
+	"administrator@nutrity.test": models.RoleAdministrator,
+	"writer@nutrity.test":        models.RoleWriter,
+	"reader@nutrity.test":        models.RoleReader,
 }
-type GoogleClient struct{}
-type GoogleClientMock struct{}
 
-// userInfoByAccessToken returns userInfo
-func (g *GoogleClient) userInfoByAccessToken(at string) (*googleUserInfoResponse, error) {
-	response, err := http.Get(googleUserInfoURL + "?access_token=" + at)
-	if err != nil {
-		return nil, err
-	}
-	if response.StatusCode != http.StatusOK {
-		return nil, errors.New("invalid access token")
+var (
+	googleCertsURL = "https://www.googleapis.com/oauth2/v3/certs"
+	googleIssuers  = []string{"https://accounts.google.com", "accounts.google.com"}
+)
+
+// NewGoogleProvider returns a provider of Google accounts granting access with config,
+// identified by the ID tokens Google issues to its client.
+func NewGoogleProvider(config *oauth2.Config) *OAuth2Provider {
+	return &OAuth2Provider{
+		Config: config,
+		IDTokenVerifier: &IDTokenVerifier{
+			Keys:     NewRemoteKeySource(googleCertsURL),
+			Issuers:  googleIssuers,
+			Audience: config.ClientID,
+		},
 	}
-	defer response.Body.Close()
-	var uinfo *googleUserInfoResponse
-	json.NewDecoder(response.Body).Decode(&uinfo)
-	return uinfo, nil
 }
 
-// userInfoByAccessToken returns userInfo
-func (g *GoogleClientMock) userInfoByAccessToken(at string) (*googleUserInfoResponse, error) {
-	switch at {
-	case "AccessToken", "Administrator", "Writer", "Reader":
-		return &googleUserInfoResponse{
-			Sub:  "123123213",
-			Name: "Mock User",
-		}, nil
-	default:
+// MockUserInfo returns mock identities by access token, for development.
+func MockUserInfo(ctx context.Context, token *oauth2.Token) (*Identity, error) {
+	uinfo, ok := mockUsersInfo[token.AccessToken]
+	if !ok {
 		return nil, errors.New("invalid access token")
 	}
+	return &uinfo, nil
 }
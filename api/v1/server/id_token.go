@@ -0,0 +1,262 @@
+package server
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ProviderRequestTimeout is the maximum time requests to identity providers take.
+	ProviderRequestTimeout = 10 * time.Second
+	// DefaultKeysCacheLifetime is the time keys of identity providers are cached
+	// if their response doesn't say.
+	DefaultKeysCacheLifetime = time.Hour
+	// KeysRefreshInterval is the minimum time between fetches of keys of identity providers
+	// looking for keys they rotated in.
+	KeysRefreshInterval = time.Minute
+	// IDTokenClockSkew is the difference allowed between clocks
+	// of the server and identity providers when checking ID tokens expiry.
+	IDTokenClockSkew = time.Minute
+)
+
+var providerClient = &http.Client{Timeout: ProviderRequestTimeout}
+
+var errUnknownKey = errors.New("unknown key")
+
+// KeySource provides the public keys that verify ID tokens.
+type KeySource interface {
+	// PublicKey returns the key with ID kid.
+	PublicKey(ctx context.Context, kid string) (crypto.PublicKey, error)
+}
+
+// RemoteKeySource is a key source that fetches and caches
+// a JSON Web Key Set from URL.
+type RemoteKeySource struct {
+	URL       string
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+	expiresAt time.Time
+}
+
+// NewRemoteKeySource returns a key source of the JSON Web Key Set at url.
+func NewRemoteKeySource(url string) *RemoteKeySource {
+	return &RemoteKeySource{URL: url}
+}
+
+// PublicKey returns the key with ID kid, fetching keys again
+// if they expired or kid is unknown, since keys could have been rotated.
+func (ks *RemoteKeySource) PublicKey(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	now := time.Now()
+	key, ok := ks.keys[kid]
+	if ok && now.Before(ks.expiresAt) {
+		return key, nil
+	}
+	if !ok && now.Before(ks.expiresAt) && now.Sub(ks.fetchedAt) < KeysRefreshInterval {
+		return nil, errUnknownKey
+	}
+	if err := ks.fetch(ctx, now); err != nil {
+		return nil, err
+	}
+	if key, ok := ks.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, errUnknownKey
+}
+
+// fetch replaces the cached keys. Must be called holding mu.
+func (ks *RemoteKeySource) fetch(ctx context.Context, now time.Time) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ks.URL, nil)
+	if err != nil {
+		return err
+	}
+	res, err := providerClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s responded with status code %d", ks.URL, res.StatusCode)
+	}
+	var set JWKSet
+	if err := json.NewDecoder(res.Body).Decode(&set); err != nil {
+		return err
+	}
+	keys := map[string]crypto.PublicKey{}
+	for _, jwk := range set.Keys {
+		key, err := publicKeyFromJWK(jwk)
+		if err != nil {
+			continue // Keys of unsupported types can't verify tokens anyway
+		}
+		keys[jwk.KeyID] = key
+	}
+	ks.keys = keys
+	ks.fetchedAt = now
+	ks.expiresAt = now.Add(cacheLifetime(res.Header.Get("Cache-Control")))
+	return nil
+}
+
+// cacheLifetime returns the max-age of Cache-Control header cc,
+// or DefaultKeysCacheLifetime if it has none.
+func cacheLifetime(cc string) time.Duration {
+	for _, directive := range strings.Split(cc, ",") {
+		directive = strings.TrimSpace(directive)
+		if strings.HasPrefix(directive, "max-age=") {
+			if seconds, err := strconv.Atoi(strings.TrimPrefix(directive, "max-age=")); err == nil {
+				return time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	return DefaultKeysCacheLifetime
+}
+
+func publicKeyFromJWK(jwk JWK) (crypto.PublicKey, error) {
+	switch jwk.KeyType {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		if jwk.Curve != "P-256" {
+			return nil, errors.New("unsupported curve " + jwk.Curve)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	default:
+		return nil, errors.New("unsupported key type " + jwk.KeyType)
+	}
+}
+
+// idTokenClaims are the claims of OpenID Connect ID tokens.
+type idTokenClaims struct {
+	Issuer        string      `json:"iss"`
+	Subject       string      `json:"sub"`
+	Audience      audience    `json:"aud"`
+	ExpiresAt     int64       `json:"exp"`
+	Nonce         string      `json:"nonce"`
+	Name          string      `json:"name"`
+	Email         string      `json:"email"`
+	EmailVerified interface{} `json:"email_verified"` // Boolean, or string for some providers
+}
+
+// audience is the aud claim, a single string or an array of them.
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+// IDTokenVerifier verifies OpenID Connect ID tokens offline
+// with the keys of their provider.
+type IDTokenVerifier struct {
+	Keys     KeySource
+	Issuers  []string // Issuers tokens are accepted from
+	Audience string   // Client ID tokens must be issued to
+}
+
+// Verify returns the identity in raw ID token if it's signed by a key of the source,
+// issued by one of the issuers to the audience, unexpired at now and carries nonce.
+func (v *IDTokenVerifier) Verify(ctx context.Context, raw string, nonce string, now time.Time) (*Identity, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed ID token")
+	}
+	var header jwtHeader
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return nil, errors.New("malformed ID token header")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("malformed ID token signature")
+	}
+	key, err := v.Keys.PublicKey(ctx, header.KeyID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get key of ID token: %w", err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if header.Algorithm != algRS256 || rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], signature) != nil {
+			return nil, errors.New("invalid ID token signature")
+		}
+	case *ecdsa.PublicKey:
+		if header.Algorithm != "ES256" || len(signature) != 64 ||
+			!ecdsa.Verify(k, digest[:], new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])) {
+			return nil, errors.New("invalid ID token signature")
+		}
+	default:
+		return nil, errors.New("invalid ID token signature")
+	}
+
+	var claims idTokenClaims
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return nil, errors.New("malformed ID token claims")
+	}
+	if !contains(v.Issuers, claims.Issuer) {
+		return nil, errors.New("ID token issued by " + claims.Issuer)
+	}
+	if !contains(claims.Audience, v.Audience) {
+		return nil, errors.New("ID token not issued to this client")
+	}
+	if !now.Before(time.Unix(claims.ExpiresAt, 0).Add(IDTokenClockSkew)) {
+		return nil, errors.New("ID token expired")
+	}
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return nil, errors.New("ID token nonce did not match")
+	}
+	return &Identity{
+		Subject:       claims.Subject,
+		Name:          claims.Name,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified == true || claims.EmailVerified == "true",
+	}, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
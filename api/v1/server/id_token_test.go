@@ -0,0 +1,186 @@
+package server_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/server"
+)
+
+const (
+	testIssuer   = "https://accounts.google.com"
+	testClientID = "client-id"
+)
+
+// KeySourceMock provides keys by their ID.
+type KeySourceMock map[string]crypto.PublicKey
+
+func (ks KeySourceMock) PublicKey(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	if k, ok := ks[kid]; ok {
+		return k, nil
+	}
+	return nil, errors.New("unknown key")
+}
+
+// signIDToken returns claims as an ID token signed by k with key ID kid.
+func signIDToken(t *testing.T, k *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// newIDTokenServer returns a test server whose Google provider issues ID tokens
+// with the claims returned by claims for the nonce of the login.
+func newIDTokenServer(t *testing.T, claims func(nonce string) map[string]interface{}) *server.Server {
+	k, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	config := &OAuth2ConfigMock{
+		IDToken: func(nonce string) string {
+			return signIDToken(t, k, "key-1", claims(nonce))
+		},
+	}
+	return newTestServer(server.ServerConfig{
+		IdentityProviders: map[string]server.IdentityProvider{
+			"google": &server.OAuth2Provider{
+				Config: config,
+				IDTokenVerifier: &server.IDTokenVerifier{
+					Keys:     KeySourceMock{"key-1": &k.PublicKey},
+					Issuers:  []string{testIssuer},
+					Audience: testClientID,
+				},
+			},
+		},
+	})
+}
+
+func validClaims(nonce string) map[string]interface{} {
+	return map[string]interface{}{
+		"iss":            testIssuer,
+		"aud":            testClientID,
+		"sub":            "google-subject",
+		"email":          "user@example.com",
+		"email_verified": true,
+		"name":           "ID Token User",
+		"nonce":          nonce,
+		"exp":            time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func TestLoginWithIDToken(t *testing.T) {
+	s := newIDTokenServer(t, validClaims)
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	at := login(t, ts, "Phone")
+	res := doRequest(t, http.MethodGet, ts.URL+"/v1/auth", at, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+	u, err := s.UsersRepo.GetUserByIdentity("google", "google-subject")
+	if err != nil {
+		t.Fatalf("Expected user identified by ID token subject, got %v", err)
+	}
+	if u.Email != "user@example.com" {
+		t.Fatalf("Expected email of ID token, got %v", u.Email)
+	}
+}
+
+func TestLoginRejectsInvalidIDTokens(t *testing.T) {
+	tests := []struct {
+		name  string
+		claim string
+		value func(nonce string) interface{}
+	}{
+		{"wrong audience", "aud", func(string) interface{} { return "other-client" }},
+		{"wrong issuer", "iss", func(string) interface{} { return "https://issuer.example.com" }},
+		{"expired", "exp", func(string) interface{} { return time.Now().Add(-time.Hour).Unix() }},
+		{"nonce mismatch", "nonce", func(nonce string) interface{} { return nonce + "-other" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newIDTokenServer(t, func(nonce string) map[string]interface{} {
+				claims := validClaims(nonce)
+				claims[tt.claim] = tt.value(nonce)
+				return claims
+			})
+			ts := httptest.NewServer(s.Router)
+			defer ts.Close()
+
+			res := callback(t, ts, startLogin(t, ts), "Phone")
+			if res.StatusCode != http.StatusBadRequest {
+				t.Fatalf("Expected status code %d, got %v", http.StatusBadRequest, res.StatusCode)
+			}
+		})
+	}
+}
+
+func TestRemoteKeySourceCachesKeys(t *testing.T) {
+	k, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	fetches := 0
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		json.NewEncoder(w).Encode(server.JWKSet{Keys: []server.JWK{{
+			KeyType:   "RSA",
+			KeyID:     "key-1",
+			Algorithm: "RS256",
+			N:         base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+			E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+		}}})
+	}))
+	defer jwks.Close()
+
+	v := &server.IDTokenVerifier{
+		Keys:     server.NewRemoteKeySource(jwks.URL),
+		Issuers:  []string{testIssuer},
+		Audience: testClientID,
+	}
+	for i := 0; i < 2; i++ {
+		token := signIDToken(t, k, "key-1", validClaims("nonce"))
+		identity, err := v.Verify(context.Background(), token, "nonce", time.Now())
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if identity.Subject != "google-subject" || !identity.EmailVerified {
+			t.Fatalf("Expected identity of ID token, got %+v", identity)
+		}
+	}
+	if fetches != 1 {
+		t.Fatalf("Expected keys fetched once, got %d fetches", fetches)
+	}
+
+	// Unknown keys aren't fetched again until keys could have been rotated
+	token := signIDToken(t, k, "key-2", validClaims("nonce"))
+	if _, err := v.Verify(context.Background(), token, "nonce", time.Now()); err == nil {
+		t.Fatalf("Expected error verifying token signed by unknown key")
+	}
+	if fetches != 1 {
+		t.Fatalf("Expected keys fetched once, got %d fetches", fetches)
+	}
+}
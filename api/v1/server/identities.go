@@ -0,0 +1,223 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/repository"
+	"github.com/gin-gonic/gin"
+)
+
+type UserIdentityDTO struct {
+	ID        uint      `json:"id"`
+	Provider  string    `json:"provider"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type AuthorizationURLDTO struct {
+	URL string `json:"url"` // URL users are sent to for granting access
+}
+
+type LinkIdentityCodeDTO struct {
+	Code         string `json:"code" binding:"required"`         // One-time code the client was redirected to with
+	RedirectURI  string `json:"redirectUri" binding:"required"`  // Redirect URI the link was started with
+	CodeVerifier string `json:"codeVerifier" binding:"required"` // PKCE code verifier of the code challenge of the link
+}
+
+func userIdentityDTOFromUserIdentity(i *models.UserIdentity) UserIdentityDTO {
+	return UserIdentityDTO{
+		ID:        i.ID,
+		Provider:  i.Provider,
+		Email:     i.Email,
+		CreatedAt: i.CreatedAt,
+	}
+}
+
+// linkIdentity links identity of provider to user with userID,
+// unless it belongs to another user.
+func (s *Server) linkIdentity(c *gin.Context, userID uint, provider string, identity *Identity) {
+	existing, err := s.UsersRepo.GetUserIdentityBySubject(provider, identity.Subject)
+	if err == nil {
+		if existing.UserID != userID {
+			respondProblem(c, http.StatusConflict, models.ErrorCodeIdentityLinked, "identity is linked to another user")
+			return
+		}
+		c.JSON(http.StatusOK, userIdentityDTOFromUserIdentity(existing))
+		return
+	}
+	if err != repository.ErrNotFound {
+		respondError(c, err)
+		return
+	}
+	linked, err := s.UsersRepo.CreateUserIdentity(&models.UserIdentity{
+		UserID:   userID,
+		Provider: provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	})
+	if err != nil {
+		respondError(c, fmt.Errorf("could not link identity: %w", err))
+		return
+	}
+	c.JSON(http.StatusCreated, userIdentityDTOFromUserIdentity(linked))
+}
+
+// GetIdentities is the handler for GET requests to /auth/identities
+// 	@ID GetIdentities
+// 	@Summary Get identities
+// 	@Description Get accounts of identity providers that log in as authenticated user.
+// 	@Tags auth
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Success 200 {array} UserIdentityDTO
+// 	@Failure 401 {object} models.APIError
+// 	@Router /auth/identities [get]
+func (s *Server) GetIdentities(c *gin.Context) {
+	identities, err := s.UsersRepo.GetUserIdentities(authenticatedUser(c).ID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	identityDTOs := make([]UserIdentityDTO, 0, len(identities))
+	for i := range identities {
+		identityDTOs = append(identityDTOs, userIdentityDTOFromUserIdentity(&identities[i]))
+	}
+	c.JSON(http.StatusOK, identityDTOs)
+}
+
+// LinkIdentity is the handler for POST requests to /auth/:provider/link
+// 	@ID LinkIdentity
+// 	@Summary Link identity
+// 	@Description Start linking an account of identity provider to authenticated user,
+// 	@Description returning the URL to send the user to. Its callback returns the linked identity.
+// 	@Description Sets a cookie binding the link to the browser, which must send the user to the URL.
+// 	@Description Clients that can't share cookies with the browser pass redirect_uri and an S256 code_challenge instead,
+// 	@Description they're redirected back to it with a one-time code in query parameter code, exchanged at /auth/identities.
+// 	@Tags auth
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Param provider path string true "Identity provider, such as google"
+// 	@Param redirect_uri query string false "URI of client redirected to with the code"
+// 	@Param state query string false "State of client, returned along with the code"
+// 	@Param code_challenge query string false "PKCE code challenge of client, required along with redirect_uri"
+// 	@Param code_challenge_method query string false "PKCE code challenge method" Enums(S256)
+// 	@Success 200 {object} AuthorizationURLDTO
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 401 {object} models.APIError
+// 	@Failure 404 {object} models.APIError
+// 	@Router /auth/{provider}/link [post]
+func (s *Server) LinkIdentity(c *gin.Context) {
+	name := c.Param("provider")
+	provider, ok := s.identityProvider(c, name)
+	if !ok {
+		return
+	}
+	ls := &models.LoginState{Provider: name, UserID: authenticatedUser(c).ID}
+	if redirectURI := c.Query("redirect_uri"); redirectURI != "" {
+		if !s.redirectURIAllowed(redirectURI) {
+			respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "redirect_uri not allowed")
+			return
+		}
+		// Without the cookie, only PKCE proves the client exchanging the code started the link
+		if c.Query("code_challenge") == "" || c.Query("code_challenge_method") != "S256" {
+			respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "S256 code_challenge required along with redirect_uri")
+			return
+		}
+		ls.RedirectURI = redirectURI
+		ls.ClientState = c.Query("state")
+		ls.CodeChallenge = c.Query("code_challenge")
+	}
+	url, err := s.authCodeURL(provider, ls)
+	if err != nil {
+		respondError(c, fmt.Errorf("could not start link: %w", err))
+		return
+	}
+	if ls.RedirectURI == "" {
+		s.setLoginStateCookie(c, ls.State)
+	}
+	c.JSON(http.StatusOK, AuthorizationURLDTO{URL: url})
+}
+
+// LinkIdentityWithCode is the handler for POST requests to /auth/identities
+// 	@ID LinkIdentityWithCode
+// 	@Summary Complete link with code
+// 	@Description Link the identity of the one-time code the client was redirected to
+// 	@Description after linking with a redirect_uri, proving it started the link with the PKCE verifier.
+// 	@Tags auth
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Accept json
+// 	@Param link body LinkIdentityCodeDTO true "Code of link"
+// 	@Success 200 {object} UserIdentityDTO
+// 	@Success 201 {object} UserIdentityDTO
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 401 {object} models.APIError
+// 	@Failure 409 {object} models.APIError
+// 	@Router /auth/identities [post]
+func (s *Server) LinkIdentityWithCode(c *gin.Context) {
+	var dto LinkIdentityCodeDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		respondError(c, validationProblem(&dto, "invalid link", err))
+		return
+	}
+	ac, err := s.consumeAuthorizationCode(dto.Code, dto.RedirectURI, dto.CodeVerifier)
+	if err == errInvalidAuthorizationCode || (err == nil && (ac.Provider == "" || ac.UserID != authenticatedUser(c).ID)) {
+		respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidGrant, errInvalidAuthorizationCode.Error())
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	s.linkIdentity(c, ac.UserID, ac.Provider, &Identity{Subject: ac.Subject, Email: ac.Email})
+}
+
+// DeleteIdentity is the handler for DELETE requests to /auth/identities/:id
+// 	@ID DeleteIdentity
+// 	@Summary Unlink identity
+// 	@Description Unlink matching account of identity provider from authenticated user.
+// 	@Description The last identity of a user without password can't be unlinked.
+// 	@Tags auth
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Param id path int true "Identity ID"
+// 	@Success 204
+// 	@Failure 401 {object} models.APIError
+// 	@Failure 404 {object} models.APIError
+// 	@Failure 409 {object} models.APIError
+// 	@Router /auth/identities/{id} [delete]
+func (s *Server) DeleteIdentity(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "invalid id: "+err.Error())
+		return
+	}
+	au := authenticatedUser(c)
+	identity, err := s.UsersRepo.GetUserIdentity(uint(id))
+	if err == repository.ErrNotFound || (err == nil && identity.UserID != au.ID) {
+		respondProblem(c, http.StatusNotFound, models.ErrorCodeIdentityNotFound, "identity with provided id not found")
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	identities, err := s.UsersRepo.GetUserIdentities(au.ID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if len(identities) <= 1 && au.PasswordHash == "" {
+		respondProblem(c, http.StatusConflict, models.ErrorCodeLastIdentity, "last identity of user without password can not be unlinked")
+		return
+	}
+	if err := s.UsersRepo.DeleteUserIdentity(identity.ID); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,341 @@
+package server_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/repository"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/repository/mocks"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/server"
+	"github.com/golang/mock/gomock"
+	"golang.org/x/oauth2"
+)
+
+// providerCallback completes the login or link with provider and state.
+func providerCallback(t *testing.T, ts *httptest.Server, provider string, state string) *http.Response {
+	t.Helper()
+	return doRequestWithHeader(t, http.MethodGet, ts.URL+"/v1/auth/"+provider+"/callback?code=code&state="+url.QueryEscape(state), "",
+		"Cookie", loginStateCookie(state), nil)
+}
+
+// startLink starts linking provider to user authenticated by at,
+// returning the state of the authorization URL.
+func startLink(t *testing.T, ts *httptest.Server, provider string, at string) string {
+	t.Helper()
+	res := doRequest(t, http.MethodPost, ts.URL+"/v1/auth/"+provider+"/link", at, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+	var authorization server.AuthorizationURLDTO
+	decodeBody(t, res, &authorization)
+	u, err := url.Parse(authorization.URL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	state := u.Query().Get("state")
+	expectLoginStateCookie(t, res, state)
+	return state
+}
+
+func TestLoginWithProvider(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	res := providerCallback(t, ts, "github", startProviderLogin(t, ts, "/v1/auth/github/login"))
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+	var tk tokens
+	decodeBody(t, res, &tk)
+
+	res = doRequest(t, http.MethodGet, ts.URL+"/v1/auth/identities", tk.AccessToken, nil)
+	var identities []server.UserIdentityDTO
+	decodeBody(t, res, &identities)
+	if len(identities) != 1 || identities[0].Provider != "github" {
+		t.Fatalf("Expected github identity, got %v", identities)
+	}
+}
+
+func TestLoginWithUnknownProviderReturnNotFound(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	res := doRequest(t, http.MethodGet, ts.URL+"/v1/auth/myspace/login", "", nil)
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected status code %d, got %v", http.StatusNotFound, res.StatusCode)
+	}
+}
+
+func TestCallbackRejectsStateOfOtherProvider(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	res := providerCallback(t, ts, "github", startLogin(t, ts))
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status code %d, got %v", http.StatusBadRequest, res.StatusCode)
+	}
+}
+
+func TestLinkAndUnlinkIdentity(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := createUser(s, "UserAccessToken", &models.User{})
+	res := providerCallback(t, ts, "github", startLink(t, ts, "github", "UserAccessToken"))
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %v", http.StatusCreated, res.StatusCode)
+	}
+	var linked server.UserIdentityDTO
+	decodeBody(t, res, &linked)
+
+	// Linked identity logs in as user
+	res = providerCallback(t, ts, "github", startProviderLogin(t, ts, "/v1/auth/github/login"))
+	var tk tokens
+	decodeBody(t, res, &tk)
+	res = doRequest(t, http.MethodGet, ts.URL+"/v1/auth/", tk.AccessToken, nil)
+	var current server.UserDTO
+	decodeBody(t, res, &current)
+	if current.ID != u.ID {
+		t.Fatalf("Expected to log in as user %d, got %d", u.ID, current.ID)
+	}
+
+	res = doRequest(t, http.MethodDelete, fmt.Sprintf("%s/v1/auth/identities/%d", ts.URL, linked.ID), "UserAccessToken", nil)
+	if res.StatusCode != http.StatusConflict {
+		t.Fatalf("Expected status code %d for last identity, got %v", http.StatusConflict, res.StatusCode)
+	}
+
+	res = providerCallback(t, ts, "google", startLink(t, ts, "google", "UserAccessToken"))
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %v", http.StatusCreated, res.StatusCode)
+	}
+	res = doRequest(t, http.MethodDelete, fmt.Sprintf("%s/v1/auth/identities/%d", ts.URL, linked.ID), "UserAccessToken", nil)
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %v", http.StatusNoContent, res.StatusCode)
+	}
+}
+
+func TestLinkCompletedByOtherBrowserIsRejected(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	attacker := createUser(s, "AttackerAccessToken", &models.User{})
+	state := startLink(t, ts, "github", "AttackerAccessToken")
+
+	// The victim's browser, sent to the URL of the link, lacks the cookie of the attacker
+	res := doRequest(t, http.MethodGet, ts.URL+"/v1/auth/github/callback?code=code&state="+url.QueryEscape(state), "", nil)
+	if p := decodeProblem(t, res, http.StatusBadRequest); p.Code != models.ErrorCodeInvalidState {
+		t.Fatalf("Expected %v, got %v", models.ErrorCodeInvalidState, p.Code)
+	}
+	res = doRequestWithHeader(t, http.MethodGet, ts.URL+"/v1/auth/github/callback?code=code&state="+url.QueryEscape(state), "",
+		"Cookie", loginStateCookie("state-of-victim"), nil)
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status code %d, got %v", http.StatusBadRequest, res.StatusCode)
+	}
+	if identities, _ := s.UsersRepo.GetUserIdentities(attacker.ID); len(identities) != 0 {
+		t.Fatalf("Expected no identity linked, got %v", identities)
+	}
+}
+
+// clientLink links an identity of GitHub to the user of at as a client redirected to
+// testRedirectURI with PKCE verifier testCodeVerifier, returning the code it's redirected with.
+func clientLink(t *testing.T, ts *httptest.Server, at string) string {
+	t.Helper()
+	sum := sha256.Sum256([]byte(testCodeVerifier))
+	q := url.Values{}
+	q.Set("redirect_uri", testRedirectURI)
+	q.Set("code_challenge", base64.RawURLEncoding.EncodeToString(sum[:]))
+	q.Set("code_challenge_method", "S256")
+	res := doRequest(t, http.MethodPost, ts.URL+"/v1/auth/github/link?"+q.Encode(), at, nil)
+	if res.StatusCode != http.StatusOK || len(res.Cookies()) != 0 {
+		t.Fatalf("Expected status code %d without cookie, got %v and %v", http.StatusOK, res.StatusCode, res.Cookies())
+	}
+	var authorization server.AuthorizationURLDTO
+	decodeBody(t, res, &authorization)
+	authorizationURL, err := url.Parse(authorization.URL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The browser the client sends the user to doesn't share its cookies
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	res, err = client.Get(ts.URL + "/v1/auth/github/callback?code=code&state=" + url.QueryEscape(authorizationURL.Query().Get("state")))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusFound {
+		t.Fatalf("Expected status code %d, got %v", http.StatusFound, res.StatusCode)
+	}
+	location, err := url.Parse(res.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return location.Query().Get("code")
+}
+
+func TestLinkIdentityOfClientWithCode(t *testing.T) {
+	s := newRedirectTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := createUser(s, "UserAccessToken", &models.User{})
+	code := clientLink(t, ts, "UserAccessToken")
+	if identities, _ := s.UsersRepo.GetUserIdentities(u.ID); len(identities) != 0 {
+		t.Fatalf("Expected no identity linked before exchanging code, got %v", identities)
+	}
+	res := doRequest(t, http.MethodPost, ts.URL+"/v1/auth/identities", "UserAccessToken",
+		server.LinkIdentityCodeDTO{Code: code, RedirectURI: testRedirectURI, CodeVerifier: "verifier-of-attacker"})
+	decodeProblem(t, res, http.StatusBadRequest)
+
+	code = clientLink(t, ts, "UserAccessToken")
+	res = doRequest(t, http.MethodPost, ts.URL+"/v1/auth/identities", "UserAccessToken",
+		server.LinkIdentityCodeDTO{Code: code, RedirectURI: testRedirectURI, CodeVerifier: testCodeVerifier})
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %v", http.StatusCreated, res.StatusCode)
+	}
+	if identities, _ := s.UsersRepo.GetUserIdentities(u.ID); len(identities) != 1 || identities[0].Provider != "github" {
+		t.Fatalf("Expected GitHub identity linked, got %v", identities)
+	}
+	// Codes of links don't log in
+	res = exchangeCode(t, ts, clientLink(t, ts, "UserAccessToken"), testRedirectURI, testCodeVerifier)
+	decodeProblem(t, res, http.StatusBadRequest)
+}
+
+func TestLinkIdentityOfOtherUserReturnConflict(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	login(t, ts, "Phone")
+	createUser(s, "OtherAccessToken", &models.User{})
+
+	res := providerCallback(t, ts, "google", startLink(t, ts, "google", "OtherAccessToken"))
+	if res.StatusCode != http.StatusConflict {
+		t.Fatalf("Expected status code %d, got %v", http.StatusConflict, res.StatusCode)
+	}
+}
+
+func TestLoginLinksUserWithVerifiedEmail(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := createUser(s, "UserAccessToken", &models.User{Email: "Mock@nutrity.test", EmailVerified: true})
+	res := providerCallback(t, ts, "github", startProviderLogin(t, ts, "/v1/auth/github/login"))
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+	var tk tokens
+	decodeBody(t, res, &tk)
+	res = doRequest(t, http.MethodGet, ts.URL+"/v1/auth/", tk.AccessToken, nil)
+	var current server.UserDTO
+	decodeBody(t, res, &current)
+	if current.ID != u.ID {
+		t.Fatalf("Expected to log in as user %d, got %d", u.ID, current.ID)
+	}
+	identities, _ := s.UsersRepo.GetUserIdentities(u.ID)
+	if len(identities) != 1 || identities[0].Provider != "github" {
+		t.Fatalf("Expected github identity linked, got %v", identities)
+	}
+}
+
+func TestLoginDoesNotPromoteUserWithAdministratorEmailOfProfile(t *testing.T) {
+	s := newTestServer(server.ServerConfig{AdminEmails: []string{"admin@nutrity.test"}})
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	res := providerCallback(t, ts, "github", startProviderLogin(t, ts, "/v1/auth/github/login"))
+	var tk tokens
+	decodeBody(t, res, &tk)
+	res = doRequest(t, http.MethodGet, ts.URL+"/v1/auth/", tk.AccessToken, nil)
+	var current server.UserDTO
+	decodeBody(t, res, &current)
+	res = patchUser(t, ts, current.ID, tk.AccessToken, `{"email": "admin@nutrity.test"}`)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+
+	res = providerCallback(t, ts, "github", startProviderLogin(t, ts, "/v1/auth/github/login"))
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+	u, _ := s.UsersRepo.GetUser(current.ID)
+	if u.Role == models.RoleAdministrator {
+		t.Fatalf("Expected user not to be promoted by email of its profile")
+	}
+}
+
+func TestLoginWithUnverifiedAdministratorEmailRegistersReader(t *testing.T) {
+	unverified := func(ctx context.Context, token *oauth2.Token) (*server.Identity, error) {
+		return &server.Identity{Subject: "unverified", Name: "Unverified", Email: "admin@nutrity.test"}, nil
+	}
+	s := newTestServer(server.ServerConfig{
+		AdminEmails: []string{"admin@nutrity.test"},
+		IdentityProviders: map[string]server.IdentityProvider{
+			"github": &server.OAuth2Provider{Config: &OAuth2ConfigMock{}, UserInfo: unverified},
+		},
+	})
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	res := providerCallback(t, ts, "github", startProviderLogin(t, ts, "/v1/auth/github/login"))
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+	u, _ := s.UsersRepo.GetUserByIdentity("github", "unverified")
+	if u.Role != models.RoleReader {
+		t.Fatalf("Expected %v role, got %v", models.RoleReader, u.Role)
+	}
+}
+
+func TestLoginWithEmailOfUnverifiedUserClaimsEmail(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	// Anyone can register a password account with an email they don't own
+	unverified := createUser(s, "UserAccessToken", &models.User{Email: "mock@nutrity.test", PasswordHash: "hash"})
+	res := providerCallback(t, ts, "github", startProviderLogin(t, ts, "/v1/auth/github/login"))
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+	u, _ := s.UsersRepo.GetUserByEmail("mock@nutrity.test")
+	if u == nil || u.ID == unverified.ID || !u.EmailVerified {
+		t.Fatalf("Expected new user with verified email, got %+v", u)
+	}
+	if previous, _ := s.UsersRepo.GetUser(unverified.ID); previous.Email != "" {
+		t.Fatalf("Expected email removed from unverified user, got %v", previous.Email)
+	}
+}
+
+func TestCallbackDoesNotRegisterOnRepositoryError(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	state := startLogin(t, ts)
+	// Unexpected calls, such as creating a user, fail the test
+	mockUsersRepo := mocks.NewMockUsersRepository(gomock.NewController(t))
+	mockUsersRepo.EXPECT().GetUserByIdentity("google", gomock.Any()).Return(nil, repository.ErrCouldNotRetrieve)
+	s.UsersRepo = mockUsersRepo
+
+	res := callback(t, ts, state, "Phone")
+	if res.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("Expected status code %d, got %v", http.StatusInternalServerError, res.StatusCode)
+	}
+}
@@ -0,0 +1,166 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// Identity is an account of an external identity provider.
+type Identity struct {
+	Subject       string // ID of account in identity provider
+	Name          string
+	Email         string
+	EmailVerified bool
+}
+
+// IdentityProvider logs users in with their account of an external provider.
+type IdentityProvider interface {
+	// AuthCodeURL returns the URL users are sent to for granting access.
+	AuthCodeURL(state string, opts ...oauth2.AuthCodeOption) string
+	// Identify exchanges authorization code for the identity of the user who granted it,
+	// which must carry nonce if the provider issues ID tokens.
+	Identify(ctx context.Context, code string, nonce string, opts ...oauth2.AuthCodeOption) (*Identity, error)
+}
+
+// OAuth2Provider is an identity provider that grants access with OAuth2.
+//
+// The identity of users granting it is read from the ID token of the token response
+// if IDTokenVerifier is set, or else requested with UserInfo.
+type OAuth2Provider struct {
+	Config          IOauthConfig
+	IDTokenVerifier *IDTokenVerifier
+	UserInfo        func(ctx context.Context, token *oauth2.Token) (*Identity, error)
+}
+
+func (p *OAuth2Provider) AuthCodeURL(state string, opts ...oauth2.AuthCodeOption) string {
+	return p.Config.AuthCodeURL(state, opts...)
+}
+
+func (p *OAuth2Provider) Identify(ctx context.Context, code string, nonce string, opts ...oauth2.AuthCodeOption) (*Identity, error) {
+	token, err := p.Config.Exchange(ctx, code, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange token: %w", err)
+	}
+	var identity *Identity
+	if p.IDTokenVerifier != nil {
+		idToken, _ := token.Extra("id_token").(string)
+		if idToken == "" {
+			return nil, errors.New("failed to verify ID token: missing from token response")
+		}
+		identity, err = p.IDTokenVerifier.Verify(ctx, idToken, nonce, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("failed to verify ID token: %w", err)
+		}
+	} else {
+		identity, err = p.UserInfo(ctx, token)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user info: %w", err)
+		}
+	}
+	if identity.Subject == "" {
+		return nil, errors.New("failed to get user info: missing subject")
+	}
+	return identity, nil
+}
+
+// getJSON decodes into v the JSON response of a GET request to url
+// authorized by token, if it's not nil.
+func getJSON(ctx context.Context, url string, token *oauth2.Token, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if token != nil {
+		req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	}
+	res, err := providerClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s responded with status code %d", url, res.StatusCode)
+	}
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+type oidcUserInfoResponse struct {
+	Sub           string `json:"sub"`
+	Name          string `json:"name"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+}
+
+// oidcUserInfo returns a UserInfo function that reads the identity
+// from OpenID Connect userinfo endpoint at url.
+func oidcUserInfo(url string) func(context.Context, *oauth2.Token) (*Identity, error) {
+	return func(ctx context.Context, token *oauth2.Token) (*Identity, error) {
+		var uinfo oidcUserInfoResponse
+		if err := getJSON(ctx, url, token, &uinfo); err != nil {
+			return nil, err
+		}
+		return &Identity{
+			Subject:       uinfo.Sub,
+			Name:          uinfo.Name,
+			Email:         uinfo.Email,
+			EmailVerified: uinfo.EmailVerified,
+		}, nil
+	}
+}
+
+type oidcDiscoveryResponse struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// NewOIDCProvider returns an OpenID Connect provider configured
+// from the discovery document at discoveryURL, or at the well-known
+// location under discoveryURL if it's the URL of the issuer.
+//
+// Identities are read from verified ID tokens if the provider publishes its keys,
+// or else from its userinfo endpoint.
+func NewOIDCProvider(ctx context.Context, discoveryURL string, clientID string, clientSecret string, redirectURL string) (*OAuth2Provider, error) {
+	if !strings.HasSuffix(discoveryURL, "/.well-known/openid-configuration") {
+		discoveryURL = strings.TrimSuffix(discoveryURL, "/") + "/.well-known/openid-configuration"
+	}
+	var discovery oidcDiscoveryResponse
+	if err := getJSON(ctx, discoveryURL, nil, &discovery); err != nil {
+		return nil, err
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || (discovery.UserInfoEndpoint == "" && discovery.JWKSURI == "") {
+		return nil, errors.New("discovery document of " + discoveryURL + " is missing endpoints")
+	}
+	provider := &OAuth2Provider{
+		Config: &oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  discovery.AuthorizationEndpoint,
+				TokenURL: discovery.TokenEndpoint,
+			},
+			RedirectURL: redirectURL,
+			Scopes:      []string{"openid", "profile", "email"},
+		},
+	}
+	if discovery.JWKSURI != "" {
+		provider.IDTokenVerifier = &IDTokenVerifier{
+			Keys:     NewRemoteKeySource(discovery.JWKSURI),
+			Issuers:  []string{discovery.Issuer},
+			Audience: clientID,
+		}
+	} else {
+		provider.UserInfo = oidcUserInfo(discovery.UserInfoEndpoint)
+	}
+	return provider, nil
+}
@@ -0,0 +1,330 @@
+package server
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	algRS256 = "RS256"
+	algEdDSA = "EdDSA"
+)
+
+// JWTKeysReloadInterval is the minimum time between reloads
+// of the JWT keys directory, so rotated keys are picked up while running.
+var JWTKeysReloadInterval = time.Minute
+
+var errInvalidToken = errors.New("invalid access token")
+
+// accessClaims are the claims of JWT access tokens.
+type accessClaims struct {
+	Issuer    string   `json:"iss"`
+	Subject   string   `json:"sub"` // ID of user
+	Roles     []string `json:"roles"`
+	SessionID uint     `json:"sid"`
+	IssuedAt  int64    `json:"iat"`
+	ExpiresAt int64    `json:"exp"`
+}
+
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ,omitempty"`
+	KeyID     string `json:"kid"`
+}
+
+// jwtKey is a key that signs JWT access tokens,
+// identified by the name of its file without extension.
+type jwtKey struct {
+	id        string
+	algorithm string
+	signer    crypto.Signer
+}
+
+// JWK is a public key in JSON Web Key format.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`   // Modulus of RSA keys
+	E         string `json:"e,omitempty"`   // Exponent of RSA keys
+	Curve     string `json:"crv,omitempty"` // Curve of OKP keys
+	X         string `json:"x,omitempty"`   // Public key of OKP keys, or x coordinate of EC keys
+	Y         string `json:"y,omitempty"`   // Y coordinate of EC keys
+}
+
+// JWKSet is a set of public keys in JSON Web Key Set format.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// jwtKeySet holds the keys of a directory of PEM encoded
+// RSA or Ed25519 private keys.
+//
+// Access tokens are signed with the key whose file name sorts last,
+// so keys are rotated by adding a file with a greater name,
+// such as one prefixed with its creation date.
+// Previous keys keep verifying tokens until their files are removed.
+type jwtKeySet struct {
+	dir      string
+	mu       sync.Mutex
+	keys     []jwtKey
+	loadedAt time.Time
+}
+
+func newJWTKeySet(dir string) (*jwtKeySet, error) {
+	ks := &jwtKeySet{dir: dir}
+	keys, err := loadJWTKeys(dir)
+	if err != nil {
+		return nil, err
+	}
+	ks.keys = keys
+	ks.loadedAt = time.Now()
+	return ks, nil
+}
+
+// loadJWTKeys reads the keys of .pem files in dir, sorted by file name.
+func loadJWTKeys(dir string) ([]jwtKey, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var keys []jwtKey
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".pem" {
+			continue
+		}
+		key, err := loadJWTKey(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", e.Name(), err)
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no .pem keys in " + dir)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].id < keys[j].id })
+	return keys, nil
+}
+
+func loadJWTKey(path string) (jwtKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return jwtKey{}, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return jwtKey{}, errors.New("no PEM block found")
+	}
+	var parsed interface{}
+	if block.Type == "RSA PRIVATE KEY" {
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	} else {
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return jwtKey{}, err
+	}
+	id := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		return jwtKey{id: id, algorithm: algRS256, signer: k}, nil
+	case ed25519.PrivateKey:
+		return jwtKey{id: id, algorithm: algEdDSA, signer: k}, nil
+	default:
+		return jwtKey{}, fmt.Errorf("unsupported key type %T", parsed)
+	}
+}
+
+// current returns the keys of the set,
+// reloading them if JWTKeysReloadInterval has passed.
+//
+// If keys can't be reloaded, the previous ones are kept.
+func (ks *jwtKeySet) current() []jwtKey {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	if time.Since(ks.loadedAt) >= JWTKeysReloadInterval {
+		keys, err := loadJWTKeys(ks.dir)
+		if err != nil {
+			log.Printf("could not reload JWT keys: %v", err)
+		} else {
+			ks.keys = keys
+		}
+		ks.loadedAt = time.Now()
+	}
+	return ks.keys
+}
+
+// sign returns the JWT of claims signed with the latest key.
+func (ks *jwtKeySet) sign(claims accessClaims) (string, error) {
+	keys := ks.current()
+	key := keys[len(keys)-1]
+	header, err := json.Marshal(jwtHeader{Algorithm: key.algorithm, Type: "JWT", KeyID: key.id})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	var signature []byte
+	if key.algorithm == algEdDSA {
+		signature, err = key.signer.Sign(rand.Reader, []byte(signingInput), crypto.Hash(0))
+	} else {
+		digest := sha256.Sum256([]byte(signingInput))
+		signature, err = key.signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// verify returns the claims of token if it's signed with one of the keys
+// using the algorithm of that key, and issued by issuer.
+//
+// Returns errSessionExpired if token is expired at now.
+func (ks *jwtKeySet) verify(token string, issuer string, now time.Time) (*accessClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errInvalidToken
+	}
+	var header jwtHeader
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return nil, errInvalidToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errInvalidToken
+	}
+	var key *jwtKey
+	keys := ks.current()
+	for i := range keys {
+		if keys[i].id == header.KeyID {
+			key = &keys[i]
+		}
+	}
+	if key == nil || key.algorithm != header.Algorithm {
+		return nil, errInvalidToken
+	}
+	signingInput := []byte(parts[0] + "." + parts[1])
+	switch pub := key.signer.Public().(type) {
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(signingInput)
+		if rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], signature) != nil {
+			return nil, errInvalidToken
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(pub, signingInput, signature) {
+			return nil, errInvalidToken
+		}
+	default:
+		return nil, errInvalidToken
+	}
+	var claims accessClaims
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return nil, errInvalidToken
+	}
+	if claims.Issuer != issuer {
+		return nil, errInvalidToken
+	}
+	if now.Unix() >= claims.ExpiresAt {
+		return nil, errSessionExpired
+	}
+	return &claims, nil
+}
+
+func decodeJWTPart(part string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// jwks returns the public keys of the set.
+func (ks *jwtKeySet) jwks() JWKSet {
+	keys := ks.current()
+	set := JWKSet{Keys: make([]JWK, 0, len(keys))}
+	for _, k := range keys {
+		jwk := JWK{KeyID: k.id, Use: "sig", Algorithm: k.algorithm}
+		switch pub := k.signer.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// isJWT reports whether token looks like a JWT rather than an opaque token.
+func isJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// signAccessToken returns a JWT access token of session of u, expiring at expiresAt.
+func (s *Server) signAccessToken(session *models.Session, u *models.User, now time.Time, expiresAt time.Time) (string, error) {
+	return s.jwtKeys.sign(accessClaims{
+		Issuer:    s.hostname,
+		Subject:   strconv.FormatUint(uint64(u.ID), 10),
+		Roles:     []string{u.Role},
+		SessionID: session.ID,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	})
+}
+
+// verifyJWT verifies the signature, issuer and expiry of JWT access token at.
+func (s *Server) verifyJWT(at string) error {
+	claims, err := s.jwtKeys.verify(at, s.hostname, time.Now())
+	if err != nil {
+		return err
+	}
+	if _, err := strconv.ParseUint(claims.Subject, 10, 64); err != nil {
+		return errInvalidToken
+	}
+	return nil
+}
+
+// GetJWKS is the handler for GET requests to /.well-known/jwks.json
+// 	@ID GetJWKS
+// 	@Summary Get JSON Web Key Set
+// 	@Description Get public keys that verify JWT access tokens.
+// 	@Description Only available when the server issues JWT access tokens.
+// 	@Tags auth
+// 	@Produce json
+// 	@Success 200 {object} JWKSet
+// 	@Router /.well-known/jwks.json [get]
+func (s *Server) GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age="+strconv.Itoa(int(JWTKeysReloadInterval.Seconds())))
+	c.JSON(http.StatusOK, s.jwtKeys.jwks())
+}
@@ -0,0 +1,180 @@
+package server_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/server"
+)
+
+// writeKey writes private key k to file name in dir as PKCS #8 PEM.
+func writeKey(t *testing.T, dir string, name string, k interface{}) {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(k)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+// verifyWithJWKS verifies Ed25519 signed token with keys published by ts,
+// as services that don't call back into the API do.
+func verifyWithJWKS(t *testing.T, ts *httptest.Server, token string) map[string]interface{} {
+	t.Helper()
+	res := doRequest(t, http.MethodGet, ts.URL+"/v1/.well-known/jwks.json", "", nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+	var jwks server.JWKSet
+	decodeBody(t, res, &jwks)
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("Expected JWT access token, got %v", token)
+	}
+	var header struct {
+		Alg string
+		Kid string
+	}
+	headerJSON, _ := base64.RawURLEncoding.DecodeString(parts[0])
+	json.Unmarshal(headerJSON, &header)
+	for _, k := range jwks.Keys {
+		if k.KeyID != header.Kid {
+			continue
+		}
+		if k.Algorithm != "EdDSA" || header.Alg != "EdDSA" {
+			t.Fatalf("Expected EdDSA key, got %v", k.Algorithm)
+		}
+		pub, _ := base64.RawURLEncoding.DecodeString(k.X)
+		signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		if !ed25519.Verify(ed25519.PublicKey(pub), []byte(parts[0]+"."+parts[1]), signature) {
+			t.Fatalf("Expected signature to be verified by published key")
+		}
+		var claims map[string]interface{}
+		claimsJSON, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		json.Unmarshal(claimsJSON, &claims)
+		return claims
+	}
+	t.Fatalf("Expected key %v to be published", header.Kid)
+	return nil
+}
+
+func TestJWTAccessTokens(t *testing.T) {
+	dir := t.TempDir()
+	_, k, _ := ed25519.GenerateKey(rand.Reader)
+	writeKey(t, dir, "2022-01-01.pem", k)
+	s := newTestServer(server.ServerConfig{JWTKeysDir: dir})
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	at := login(t, ts, "Phone")
+	claims := verifyWithJWKS(t, ts, at)
+	roles, _ := claims["roles"].([]interface{})
+	if claims["sub"] != "1" || len(roles) != 1 || claims["exp"] == nil {
+		t.Fatalf("Expected claims with user ID, roles and expiry, got %v", claims)
+	}
+
+	res := doRequest(t, http.MethodGet, ts.URL+"/v1/auth/", at, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+
+	tampered := strings.Split(at, ".")
+	tampered[1] = base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"2","exp":9999999999}`))
+	res = doRequest(t, http.MethodGet, ts.URL+"/v1/auth/", strings.Join(tampered, "."), nil)
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Expected status code %d, got %v", http.StatusUnauthorized, res.StatusCode)
+	}
+}
+
+func TestLogoutRevokesJWTAccessToken(t *testing.T) {
+	dir := t.TempDir()
+	_, k, _ := ed25519.GenerateKey(rand.Reader)
+	writeKey(t, dir, "2022-01-01.pem", k)
+	s := newTestServer(server.ServerConfig{JWTKeysDir: dir})
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	at := login(t, ts, "Phone")
+	res := doRequest(t, http.MethodPost, ts.URL+"/v1/auth/logout", at, nil)
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %v", http.StatusNoContent, res.StatusCode)
+	}
+	res = doRequest(t, http.MethodGet, ts.URL+"/v1/auth/", at, nil)
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Expected status code %d after logout, got %v", http.StatusUnauthorized, res.StatusCode)
+	}
+}
+
+func TestJWTKeyRotation(t *testing.T) {
+	reloadInterval := server.JWTKeysReloadInterval
+	server.JWTKeysReloadInterval = 0
+	defer func() { server.JWTKeysReloadInterval = reloadInterval }()
+
+	dir := t.TempDir()
+	_, k, _ := ed25519.GenerateKey(rand.Reader)
+	writeKey(t, dir, "2022-01-01.pem", k)
+	s := newTestServer(server.ServerConfig{JWTKeysDir: dir})
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	oldToken := login(t, ts, "Phone")
+
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	writeKey(t, dir, "2022-02-01.pem", rsaKey)
+	newToken := login(t, ts, "Laptop")
+	var header struct {
+		Alg string
+		Kid string
+	}
+	headerJSON, _ := base64.RawURLEncoding.DecodeString(strings.Split(newToken, ".")[0])
+	json.Unmarshal(headerJSON, &header)
+	if header.Kid != "2022-02-01" || header.Alg != "RS256" {
+		t.Fatalf("Expected token signed with new RS256 key, got %v", header)
+	}
+
+	res := doRequest(t, http.MethodGet, ts.URL+"/v1/.well-known/jwks.json", "", nil)
+	var jwks server.JWKSet
+	decodeBody(t, res, &jwks)
+	if len(jwks.Keys) != 2 || jwks.Keys[1].KeyType != "RSA" || jwks.Keys[1].N == "" {
+		t.Fatalf("Expected both keys to be published, got %v", jwks.Keys)
+	}
+
+	for _, at := range []string{oldToken, newToken} {
+		res := doRequest(t, http.MethodGet, ts.URL+"/v1/auth/", at, nil)
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+		}
+	}
+}
+
+func TestJWKSNotFoundWithOpaqueTokens(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	res := doRequest(t, http.MethodGet, ts.URL+"/v1/.well-known/jwks.json", "", nil)
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected status code %d, got %v", http.StatusNotFound, res.StatusCode)
+	}
+}
+
+func TestNewServerWithoutJWTKeysReturnsError(t *testing.T) {
+	if _, err := server.NewServer(server.ServerConfig{JWTKeysDir: t.TempDir()}); err == nil {
+		t.Fatalf("Expected error loading JWT keys of empty directory")
+	}
+}
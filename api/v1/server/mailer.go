@@ -0,0 +1,42 @@
+package server
+
+import (
+	"errors"
+	"log"
+	"net/smtp"
+	"strings"
+)
+
+// Mailer sends emails to users.
+type Mailer interface {
+	SendMail(to string, subject string, body string) error
+}
+
+// SMTPMailer sends emails through an SMTP server at Addr, formatted as host:port.
+type SMTPMailer struct {
+	Addr string
+	From string
+	Auth smtp.Auth // Authentication of sender, nil if not required
+}
+
+func (m *SMTPMailer) SendMail(to string, subject string, body string) error {
+	if strings.ContainsAny(to+subject, "\r\n") {
+		return errors.New("invalid email header")
+	}
+	msg := "From: " + m.From + "\r\n" +
+		"To: " + to + "\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n" +
+		"\r\n" +
+		strings.ReplaceAll(body, "\n", "\r\n")
+	return smtp.SendMail(m.Addr, m.Auth, m.From, []string{to}, []byte(msg))
+}
+
+// LogMailer writes emails to the log instead of sending them, for development.
+// Their bodies carry tokens that reset passwords, so it must not be used in production.
+type LogMailer struct{}
+
+func (m *LogMailer) SendMail(to string, subject string, body string) error {
+	log.Printf("email to %s: %s\n%s", to, subject, body)
+	return nil
+}
@@ -0,0 +1,420 @@
+package server
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/repository"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	// DefaultTrendAlpha is the default smoothing factor of weight trend.
+	DefaultTrendAlpha = 0.1
+	// TrendRateWindow is the period of weight trend used to calculate its weekly rate.
+	TrendRateWindow = 14 * 24 * time.Hour
+	// MaintenanceTolerance is the weekly rate of change, in kilograms,
+	// still considered on track for users maintaining their weight.
+	MaintenanceTolerance = 0.25
+)
+
+type CreateMeasurementDTO struct {
+	TakenAt *time.Time `json:"takenAt"` // Defaults to now
+	Weight  *float64   `json:"weight" binding:"omitempty,gt=0"`
+	BodyFat *float64   `json:"bodyFat" binding:"omitempty,gte=0,lte=100"`
+	Waist   *float64   `json:"waist" binding:"omitempty,gt=0"`
+	Hips    *float64   `json:"hips" binding:"omitempty,gt=0"`
+	Chest   *float64   `json:"chest" binding:"omitempty,gt=0"`
+	Neck    *float64   `json:"neck" binding:"omitempty,gt=0"`
+}
+
+type UpdateMeasurementDTO struct {
+	TakenAt time.Time `json:"takenAt" binding:"required"`
+	Weight  *float64  `json:"weight" binding:"omitempty,gt=0"`
+	BodyFat *float64  `json:"bodyFat" binding:"omitempty,gte=0,lte=100"`
+	Waist   *float64  `json:"waist" binding:"omitempty,gt=0"`
+	Hips    *float64  `json:"hips" binding:"omitempty,gt=0"`
+	Chest   *float64  `json:"chest" binding:"omitempty,gt=0"`
+	Neck    *float64  `json:"neck" binding:"omitempty,gt=0"`
+}
+
+type MeasurementDTO struct {
+	ID      uint      `json:"id"`
+	TakenAt time.Time `json:"takenAt"`
+	Weight  *float64  `json:"weight,omitempty"`  // In kilograms
+	BodyFat *float64  `json:"bodyFat,omitempty"` // Percentage
+	Waist   *float64  `json:"waist,omitempty"`   // In centimeters
+	Hips    *float64  `json:"hips,omitempty"`    // In centimeters
+	Chest   *float64  `json:"chest,omitempty"`   // In centimeters
+	Neck    *float64  `json:"neck,omitempty"`    // In centimeters
+}
+
+type TrendPointDTO struct {
+	TakenAt time.Time `json:"takenAt"`
+	Weight  float64   `json:"weight"`
+	Trend   float64   `json:"trend"`
+}
+
+type WeightTrendDTO struct {
+	Alpha      float64         `json:"alpha"`
+	Points     []TrendPointDTO `json:"points"`
+	WeeklyRate float64         `json:"weeklyRate"` // Kilograms per week
+	WeightGoal string          `json:"weightGoal"`
+	OnTrack    bool            `json:"onTrack"` // Whether weekly rate agrees with weight goal
+}
+
+func measurementDTOFromMeasurement(m *models.Measurement) MeasurementDTO {
+	return MeasurementDTO{
+		ID:      m.ID,
+		TakenAt: m.TakenAt,
+		Weight:  m.Weight,
+		BodyFat: m.BodyFat,
+		Waist:   m.Waist,
+		Hips:    m.Hips,
+		Chest:   m.Chest,
+		Neck:    m.Neck,
+	}
+}
+
+// weightTrend smooths the weights in measurements, ordered by time,
+// with an exponential moving average using smoothing factor alpha.
+func weightTrend(measurements []models.Measurement, alpha float64) []TrendPointDTO {
+	var points []TrendPointDTO
+	for _, m := range measurements {
+		if m.Weight == nil {
+			continue
+		}
+		trend := *m.Weight
+		if len(points) > 0 {
+			prev := points[len(points)-1].Trend
+			trend = prev + alpha*(*m.Weight-prev)
+		}
+		points = append(points, TrendPointDTO{TakenAt: m.TakenAt, Weight: *m.Weight, Trend: trend})
+	}
+	return points
+}
+
+// weeklyRate returns the slope, in units per week, of the least squares
+// line through the trend of points within window of the last point.
+func weeklyRate(points []TrendPointDTO, window time.Duration) float64 {
+	if len(points) < 2 {
+		return 0
+	}
+	last := points[len(points)-1].TakenAt
+	var n, sumX, sumY, sumXY, sumXX float64
+	for _, p := range points {
+		if last.Sub(p.TakenAt) > window {
+			continue
+		}
+		x := p.TakenAt.Sub(last).Hours() / 24
+		n++
+		sumX += x
+		sumY += p.Trend
+		sumXY += x * p.Trend
+		sumXX += x * x
+	}
+	den := n*sumXX - sumX*sumX
+	if n < 2 || den == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / den * 7
+}
+
+// onTrack reports whether weight changing at rate agrees with weightGoal.
+func onTrack(rate float64, weightGoal string) bool {
+	switch weightGoal {
+	case models.WeightGoalLose:
+		return rate < 0
+	case models.WeightGoalGain:
+		return rate > 0
+	default:
+		return math.Abs(rate) <= MaintenanceTolerance
+	}
+}
+
+// dateRange reads from and to query parameters, formatted as YYYY-MM-DD,
+// as the start of day from and the end of day to.
+//
+// If they are invalid it responds with an error and returns false.
+func dateRange(c *gin.Context) (time.Time, time.Time, bool) {
+	var from, to time.Time
+	var err error
+	if f := c.Query("from"); f != "" {
+		if from, err = time.Parse(DateLayout, f); err != nil {
+			respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "invalid from, expected format YYYY-MM-DD")
+			return from, to, false
+		}
+	}
+	if t := c.Query("to"); t != "" {
+		if to, err = time.Parse(DateLayout, t); err != nil {
+			respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "invalid to, expected format YYYY-MM-DD")
+			return from, to, false
+		}
+		to = to.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return from, to, true
+}
+
+// measurementOfUser returns the measurement in path parameter measurementId
+// if it belongs to user u.
+//
+// Otherwise it responds with an error and returns false.
+func (s *Server) measurementOfUser(c *gin.Context, u *models.User) (*models.Measurement, bool) {
+	measurementID, err := strconv.Atoi(c.Param("measurementId"))
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "invalid measurement id: "+err.Error())
+		return nil, false
+	}
+	m, err := s.MeasurementsRepo.GetMeasurement(uint(measurementID))
+	if err == repository.ErrNotFound || (err == nil && m.UserID != u.ID) {
+		respondProblem(c, http.StatusNotFound, models.ErrorCodeMeasurementNotFound, "measurement with provided id not found")
+		return nil, false
+	}
+	if err != nil {
+		respondError(c, err)
+		return nil, false
+	}
+	return m, true
+}
+
+// GetMeasurements is the handler for GET requests to /users/:id/measurements
+// 	@ID GetMeasurements
+// 	@Summary Get measurements
+// 	@Description Get measurements of user ordered by time.
+// 	@Tags measurements
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Param id path int true "User ID"
+// 	@Param from query string false "First date, formatted as YYYY-MM-DD"
+// 	@Param to query string false "Last date, formatted as YYYY-MM-DD"
+// 	@Success 200 {array} MeasurementDTO
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 401 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Router /users/{id}/measurements [get]
+func (s *Server) GetMeasurements(c *gin.Context) {
+	u, ok := s.authenticatedOwner(c)
+	if !ok {
+		return
+	}
+	from, to, ok := dateRange(c)
+	if !ok {
+		return
+	}
+	measurements, err := s.MeasurementsRepo.GetMeasurements(u.ID, from, to)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	measurementDTOs := make([]MeasurementDTO, len(measurements))
+	for i := range measurements {
+		measurementDTOs[i] = measurementDTOFromMeasurement(&measurements[i])
+	}
+	c.JSON(http.StatusOK, measurementDTOs)
+}
+
+// GetMeasurement is the handler for GET requests to /users/:id/measurements/:measurementId
+// 	@ID GetMeasurement
+// 	@Summary Get measurement
+// 	@Description Get measurement of user with matching ID.
+// 	@Tags measurements
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Param id path int true "User ID"
+// 	@Param measurementId path int true "Measurement ID"
+// 	@Success 200 {object} MeasurementDTO
+// 	@Failure 401 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 404 {object} models.APIError
+// 	@Router /users/{id}/measurements/{measurementId} [get]
+func (s *Server) GetMeasurement(c *gin.Context) {
+	u, ok := s.authenticatedOwner(c)
+	if !ok {
+		return
+	}
+	m, ok := s.measurementOfUser(c, u)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, measurementDTOFromMeasurement(m))
+}
+
+// CreateMeasurement is the handler for POST requests to /users/:id/measurements
+// 	@ID CreateMeasurement
+// 	@Summary Create measurement
+// 	@Description Log body measurements of user.
+// 	@Tags measurements
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Param id path int true "User ID"
+// 	@Param measurement body CreateMeasurementDTO true "Measurement"
+// 	@Success 201 {object} MeasurementDTO
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 401 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Router /users/{id}/measurements [post]
+func (s *Server) CreateMeasurement(c *gin.Context) {
+	u, ok := s.authenticatedOwner(c)
+	if !ok {
+		return
+	}
+	var cm CreateMeasurementDTO
+	if err := c.ShouldBindJSON(&cm); err != nil {
+		respondError(c, validationProblem(&cm, "invalid measurement", err))
+		return
+	}
+	takenAt := time.Now()
+	if cm.TakenAt != nil {
+		takenAt = *cm.TakenAt
+	}
+	m, err := s.MeasurementsRepo.CreateMeasurement(&models.Measurement{
+		UserID:  u.ID,
+		TakenAt: takenAt,
+		Weight:  cm.Weight,
+		BodyFat: cm.BodyFat,
+		Waist:   cm.Waist,
+		Hips:    cm.Hips,
+		Chest:   cm.Chest,
+		Neck:    cm.Neck,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, measurementDTOFromMeasurement(m))
+}
+
+// UpdateMeasurement is the handler for PUT requests to /users/:id/measurements/:measurementId
+// 	@ID UpdateMeasurement
+// 	@Summary Update measurement
+// 	@Description Update matching measurement with provided data.
+// 	@Tags measurements
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Param id path int true "User ID"
+// 	@Param measurementId path int true "Measurement ID"
+// 	@Param measurement body UpdateMeasurementDTO true "Measurement"
+// 	@Success 200 {object} MeasurementDTO
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 401 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 404 {object} models.APIError
+// 	@Router /users/{id}/measurements/{measurementId} [put]
+func (s *Server) UpdateMeasurement(c *gin.Context) {
+	u, ok := s.authenticatedOwner(c)
+	if !ok {
+		return
+	}
+	var um UpdateMeasurementDTO
+	if err := c.ShouldBindJSON(&um); err != nil {
+		respondError(c, validationProblem(&um, "invalid measurement", err))
+		return
+	}
+	m, ok := s.measurementOfUser(c, u)
+	if !ok {
+		return
+	}
+
+	m.TakenAt = um.TakenAt
+	m.Weight = um.Weight
+	m.BodyFat = um.BodyFat
+	m.Waist = um.Waist
+	m.Hips = um.Hips
+	m.Chest = um.Chest
+	m.Neck = um.Neck
+
+	m, err := s.MeasurementsRepo.UpdateMeasurement(m)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, measurementDTOFromMeasurement(m))
+}
+
+// DeleteMeasurement is the handler for DELETE requests to /users/:id/measurements/:measurementId
+// 	@ID DeleteMeasurement
+// 	@Summary Delete measurement
+// 	@Description Delete matching measurement.
+// 	@Tags measurements
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Param id path int true "User ID"
+// 	@Param measurementId path int true "Measurement ID"
+// 	@Success 204
+// 	@Failure 401 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 404 {object} models.APIError
+// 	@Router /users/{id}/measurements/{measurementId} [delete]
+func (s *Server) DeleteMeasurement(c *gin.Context) {
+	u, ok := s.authenticatedOwner(c)
+	if !ok {
+		return
+	}
+	m, ok := s.measurementOfUser(c, u)
+	if !ok {
+		return
+	}
+	if err := s.MeasurementsRepo.DeleteMeasurement(m.ID); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetWeightTrend is the handler for GET requests to /users/:id/measurements/trend
+// 	@ID GetWeightTrend
+// 	@Summary Get weight trend
+// 	@Description Get weights of user smoothed with an exponential moving average,
+// 	@Description along with their weekly rate of change over the last two weeks
+// 	@Description and whether it agrees with user's weight goal.
+// 	@Tags measurements
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Param id path int true "User ID"
+// 	@Param from query string false "First date, formatted as YYYY-MM-DD"
+// 	@Param to query string false "Last date, formatted as YYYY-MM-DD"
+// 	@Param alpha query number false "Smoothing factor between 0 and 1" default(0.1)
+// 	@Success 200 {object} WeightTrendDTO
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 401 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Router /users/{id}/measurements/trend [get]
+func (s *Server) GetWeightTrend(c *gin.Context) {
+	u, ok := s.authenticatedOwner(c)
+	if !ok {
+		return
+	}
+	from, to, ok := dateRange(c)
+	if !ok {
+		return
+	}
+	alpha := DefaultTrendAlpha
+	if a := c.Query("alpha"); a != "" {
+		var err error
+		if alpha, err = strconv.ParseFloat(a, 64); err != nil || alpha <= 0 || alpha > 1 {
+			respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "invalid alpha, expected number between 0 and 1")
+			return
+		}
+	}
+	measurements, err := s.MeasurementsRepo.GetMeasurements(u.ID, from, to)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	points := weightTrend(measurements, alpha)
+	if points == nil {
+		points = []TrendPointDTO{}
+	}
+	rate := weeklyRate(points, TrendRateWindow)
+	c.JSON(http.StatusOK, WeightTrendDTO{
+		Alpha:      alpha,
+		Points:     points,
+		WeeklyRate: rate,
+		WeightGoal: u.WeightGoal,
+		OnTrack:    onTrack(rate, u.WeightGoal),
+	})
+}
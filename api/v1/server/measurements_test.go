@@ -0,0 +1,127 @@
+package server_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/server"
+)
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func TestGetMeasurementsFiltersByDate(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := createUser(s, "AccessToken", &models.User{})
+	measurementsURL := fmt.Sprintf("%s/v1/users/%d/measurements", ts.URL, u.ID)
+	for _, day := range []int{1, 2, 3} {
+		takenAt := time.Date(2022, 5, day, 7, 0, 0, 0, time.UTC)
+		res := doRequest(t, http.MethodPost, measurementsURL, "AccessToken",
+			server.CreateMeasurementDTO{TakenAt: &takenAt, Weight: floatPtr(80), Waist: floatPtr(90)})
+		if res.StatusCode != http.StatusCreated {
+			t.Fatalf("Expected status code %d, got %v", http.StatusCreated, res.StatusCode)
+		}
+	}
+
+	res := doRequest(t, http.MethodGet, measurementsURL+"?from=2022-05-02&to=2022-05-02", "AccessToken", nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+	var measurements []server.MeasurementDTO
+	decodeBody(t, res, &measurements)
+	if len(measurements) != 1 || measurements[0].TakenAt.Day() != 2 {
+		t.Fatalf("Expected only measurement of 2022-05-02, got %v", measurements)
+	}
+}
+
+func TestUpdateAndDeleteMeasurement(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := createUser(s, "AccessToken", &models.User{})
+	other := createUser(s, "OtherAccessToken", &models.User{})
+	m, _ := s.MeasurementsRepo.CreateMeasurement(&models.Measurement{UserID: u.ID, TakenAt: time.Now(), Weight: floatPtr(80)})
+	measurementURL := fmt.Sprintf("%s/v1/users/%d/measurements/%d", ts.URL, u.ID, m.ID)
+
+	res := doRequest(t, http.MethodPut, measurementURL, "AccessToken",
+		server.UpdateMeasurementDTO{TakenAt: m.TakenAt, Weight: floatPtr(79.5), BodyFat: floatPtr(18)})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+	var updated server.MeasurementDTO
+	decodeBody(t, res, &updated)
+	if updated.Weight == nil || *updated.Weight != 79.5 || updated.BodyFat == nil || *updated.BodyFat != 18 {
+		t.Fatalf("Expected weight 79.5 and body fat 18, got %v", updated)
+	}
+
+	// Measurement does not belong to other user
+	res = doRequest(t, http.MethodDelete, fmt.Sprintf("%s/v1/users/%d/measurements/%d", ts.URL, other.ID, m.ID), "OtherAccessToken", nil)
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected status code %d, got %v", http.StatusNotFound, res.StatusCode)
+	}
+	res = doRequest(t, http.MethodDelete, measurementURL, "AccessToken", nil)
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %v", http.StatusNoContent, res.StatusCode)
+	}
+}
+
+func TestGetWeightTrendSmoothsWeightsAndComputesWeeklyRate(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := createUser(s, "AccessToken", &models.User{WeightGoal: models.WeightGoalLose})
+	start := time.Date(2022, 5, 1, 7, 0, 0, 0, time.UTC)
+	// Losing 0.1 kg a day
+	for day := 0; day < 14; day++ {
+		weight := 80 - 0.1*float64(day)
+		s.MeasurementsRepo.CreateMeasurement(&models.Measurement{UserID: u.ID, TakenAt: start.AddDate(0, 0, day), Weight: floatPtr(weight)})
+	}
+	// Measurements without weight are not part of the trend
+	s.MeasurementsRepo.CreateMeasurement(&models.Measurement{UserID: u.ID, TakenAt: start, Waist: floatPtr(90)})
+
+	res := doRequest(t, http.MethodGet, fmt.Sprintf("%s/v1/users/%d/measurements/trend?alpha=1", ts.URL, u.ID), "AccessToken", nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+	var trend server.WeightTrendDTO
+	decodeBody(t, res, &trend)
+	if len(trend.Points) != 14 {
+		t.Fatalf("Expected 14 points, got %v", len(trend.Points))
+	}
+	// Alpha 1 does not smooth weights
+	if !almostEqual(trend.Points[13].Trend, trend.Points[13].Weight) {
+		t.Fatalf("Expected trend equal to weight, got %v", trend.Points[13])
+	}
+	if !almostEqual(trend.WeeklyRate, -0.7) || !trend.OnTrack {
+		t.Fatalf("Expected on track weekly rate of -0.7, got %v and %v", trend.WeeklyRate, trend.OnTrack)
+	}
+
+	res = doRequest(t, http.MethodGet, fmt.Sprintf("%s/v1/users/%d/measurements/trend", ts.URL, u.ID), "AccessToken", nil)
+	decodeBody(t, res, &trend)
+	if !(trend.Points[13].Trend > trend.Points[13].Weight) {
+		t.Fatalf("Expected trend to lag behind decreasing weight, got %v", trend.Points[13])
+	}
+}
+
+func TestGetWeightTrendWithInvalidAlphaReturnBadRequest(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := createUser(s, "AccessToken", &models.User{})
+
+	res := doRequest(t, http.MethodGet, fmt.Sprintf("%s/v1/users/%d/measurements/trend?alpha=2", ts.URL, u.ID), "AccessToken", nil)
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status code %d, got %v", http.StatusBadRequest, res.StatusCode)
+	}
+}
@@ -0,0 +1,25 @@
+package server
+
+// mergePatchContentType is the media type of JSON Merge Patch documents.
+const mergePatchContentType = "application/merge-patch+json"
+
+// mergePatch applies JSON Merge Patch (RFC 7396) patch to target,
+// both decoded from JSON, returning the patched document.
+func mergePatch(target interface{}, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = map[string]interface{}{}
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+			continue
+		}
+		t[k] = mergePatch(t[k], v)
+	}
+	return t
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/repository"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// authenticatedUserKey is the context key of the user resolved by authenticate.
+	authenticatedUserKey = "authenticatedUser"
+	// authenticatedSessionKey is the context key of the session resolved by authenticate.
+	authenticatedSessionKey = "authenticatedSession"
+)
+
+// accessToken returns the access token of the request, read from
+// the access token header or else from a Bearer authorization header.
+func accessToken(c *gin.Context) string {
+	if at := c.GetHeader(AccessTokenName); at != "" {
+		return at
+	}
+	authorization := c.GetHeader("Authorization")
+	if len(authorization) > len("Bearer ") && strings.EqualFold(authorization[:len("Bearer ")], "Bearer ") {
+		return strings.TrimSpace(authorization[len("Bearer "):])
+	}
+	return ""
+}
+
+// authenticate returns a middleware that resolves the session and user
+// of the request access token and stores them in the context
+// for authenticatedSession and authenticatedUser.
+//
+// Requests without a valid access token are rejected with 401 Unauthorized.
+// If roles are provided, the user must have one of them
+// or the request is rejected with 403 Forbidden.
+func (s *Server) authenticate(roles ...string) gin.HandlerFunc {
+	return s.authenticateRequest(true, roles...)
+}
+
+// identify returns a middleware that authenticates requests with an access token
+// like authenticate, letting requests without one through anonymously.
+// Handlers get the user with optionalUser.
+func (s *Server) identify() gin.HandlerFunc {
+	return s.authenticateRequest(false)
+}
+
+func (s *Server) authenticateRequest(required bool, roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		at := accessToken(c)
+		if at == "" && !required {
+			c.Next()
+			return
+		}
+		if at == "" {
+			c.Header("WWW-Authenticate", "Bearer")
+			respondProblem(c, http.StatusUnauthorized, models.ErrorCodeUnauthenticated, "access token required")
+			return
+		}
+		session, err := s.sessionByAccessToken(at)
+		if err == repository.ErrNotFound || err == errInvalidToken || err == errSessionExpired {
+			code, message := models.ErrorCodeUnauthenticated, "invalid access token"
+			if err == errSessionExpired {
+				code, message = models.ErrorCodeTokenExpired, err.Error()
+			}
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			respondProblem(c, http.StatusUnauthorized, code, message)
+			return
+		}
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		u, err := s.UsersRepo.GetUser(session.UserID)
+		if err == repository.ErrNotFound {
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			respondProblem(c, http.StatusUnauthorized, models.ErrorCodeUnauthenticated, "invalid access token")
+			return
+		}
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		if len(roles) > 0 && !hasRole(u, roles...) {
+			respondProblem(c, http.StatusForbidden, models.ErrorCodeForbidden, "role "+u.Role+" is not allowed")
+			return
+		}
+		c.Set(authenticatedSessionKey, session)
+		c.Set(authenticatedUserKey, u)
+		c.Next()
+	}
+}
+
+// authenticatedUser returns the user resolved by authenticate.
+func authenticatedUser(c *gin.Context) *models.User {
+	return c.MustGet(authenticatedUserKey).(*models.User)
+}
+
+// optionalUser returns the user resolved by identify,
+// or nil if the request is anonymous.
+func optionalUser(c *gin.Context) *models.User {
+	if u, ok := c.Get(authenticatedUserKey); ok {
+		return u.(*models.User)
+	}
+	return nil
+}
+
+// authenticatedSession returns the session resolved by authenticate.
+func authenticatedSession(c *gin.Context) *models.Session {
+	return c.MustGet(authenticatedSessionKey).(*models.Session)
+}
+
+// hasRole reports whether u has one of roles.
+func hasRole(u *models.User, roles ...string) bool {
+	for _, r := range roles {
+		if u.Role == r {
+			return true
+		}
+	}
+	return false
+}
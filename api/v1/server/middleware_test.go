@@ -0,0 +1,132 @@
+package server_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/server"
+)
+
+func TestRoutesRequireRole(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	createUser(s, "Administrator", &models.User{Role: models.RoleAdministrator})
+	createUser(s, "Writer", &models.User{Role: models.RoleWriter})
+	createUser(s, "Reader", &models.User{})
+	food := server.CreateFoodDTO{Name: "Apple", ServingSize: 182, Calories: 52}
+
+	tests := []struct {
+		method   string
+		path     string
+		at       string
+		body     interface{}
+		expected int
+	}{
+		{http.MethodGet, "/v1/users/", "", nil, http.StatusUnauthorized},
+		{http.MethodGet, "/v1/users/", "Unknown", nil, http.StatusUnauthorized},
+		{http.MethodGet, "/v1/users/", "Reader", nil, http.StatusForbidden},
+		{http.MethodGet, "/v1/users/", "Administrator", nil, http.StatusOK},
+		{http.MethodPost, "/v1/foods/", "Reader", food, http.StatusForbidden},
+		{http.MethodPost, "/v1/foods/", "Writer", food, http.StatusCreated},
+		{http.MethodDelete, "/v1/foods/1", "Writer", nil, http.StatusForbidden},
+		{http.MethodDelete, "/v1/foods/1", "Administrator", nil, http.StatusNoContent},
+	}
+	for _, test := range tests {
+		res := doRequest(t, test.method, ts.URL+test.path, test.at, test.body)
+		if res.StatusCode != test.expected {
+			t.Fatalf("Expected status code %d for %s %s as %q, got %v", test.expected, test.method, test.path, test.at, res.StatusCode)
+		}
+	}
+}
+
+func TestAuthenticateAcceptsBearerAuthorization(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := createUser(s, "AccessToken", &models.User{})
+
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/v1/auth/", nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Add("Authorization", "Bearer AccessToken")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+	var current server.UserDTO
+	decodeBody(t, res, &current)
+	if current.ID != u.ID {
+		t.Fatalf("Expected user %d, got %v", u.ID, current.ID)
+	}
+}
+
+func TestAuthenticateWithoutAccessTokenReturnUnauthorized(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := createUser(s, "AccessToken", &models.User{})
+
+	res := doRequest(t, http.MethodGet, fmt.Sprintf("%s/v1/users/%d/diary/2022-05-01", ts.URL, u.ID), "", nil)
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Expected status code %d, got %v", http.StatusUnauthorized, res.StatusCode)
+	}
+	if res.Header.Get("WWW-Authenticate") == "" {
+		t.Fatalf("Expected WWW-Authenticate header to be set")
+	}
+}
+
+func TestUpdateUserRole(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	admin := createUser(s, "Administrator", &models.User{Role: models.RoleAdministrator})
+	u := createUser(s, "AccessToken", &models.User{})
+	if u.Role != models.RoleReader {
+		t.Fatalf("Expected new users to have role %v, got %v", models.RoleReader, u.Role)
+	}
+
+	res := doRequest(t, http.MethodPut, fmt.Sprintf("%s/v1/users/%d/role", ts.URL, u.ID), "AccessToken", server.UpdateUserRoleDTO{Role: models.RoleAdministrator})
+	if res.StatusCode != http.StatusForbidden {
+		t.Fatalf("Expected status code %d, got %v", http.StatusForbidden, res.StatusCode)
+	}
+	res = doRequest(t, http.MethodPut, fmt.Sprintf("%s/v1/users/%d/role", ts.URL, admin.ID), "Administrator", server.UpdateUserRoleDTO{Role: models.RoleReader})
+	if res.StatusCode != http.StatusForbidden {
+		t.Fatalf("Expected status code %d, got %v", http.StatusForbidden, res.StatusCode)
+	}
+	res = doRequest(t, http.MethodPut, fmt.Sprintf("%s/v1/users/%d/role", ts.URL, u.ID), "Administrator", server.UpdateUserRoleDTO{Role: models.RoleWriter})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+	var updated server.UserDTO
+	decodeBody(t, res, &updated)
+	if updated.Role != models.RoleWriter {
+		t.Fatalf("Expected role %v, got %v", models.RoleWriter, updated.Role)
+	}
+}
+
+func TestDeleteRecipeOfDifferentUserAsAdministratorReturnNoContent(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	createUser(s, "Administrator", &models.User{Role: models.RoleAdministrator})
+	author := createUser(s, "Writer", &models.User{Role: models.RoleWriter})
+	r, _ := s.RecipesRepo.CreateRecipe(&models.Recipe{Name: "Pancakes", Servings: 4, AuthorID: author.ID})
+
+	res := doRequest(t, http.MethodDelete, fmt.Sprintf("%s/v1/recipes/%d", ts.URL, r.ID), "Administrator", nil)
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %v", http.StatusNoContent, res.StatusCode)
+	}
+}
@@ -0,0 +1,184 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/repository"
+	"github.com/gin-gonic/gin"
+)
+
+type CreateNutrientDTO struct {
+	Key        string  `json:"key" binding:"required"`
+	Name       string  `json:"name" binding:"required"`
+	Unit       string  `json:"unit" binding:"required"`
+	DailyValue float64 `json:"dailyValue" binding:"gt=0"`
+	Limit      bool    `json:"limit"`
+}
+
+type UpdateNutrientDTO struct {
+	Name       string  `json:"name" binding:"required"`
+	Unit       string  `json:"unit" binding:"required"`
+	DailyValue float64 `json:"dailyValue" binding:"gt=0"`
+	Limit      bool    `json:"limit"`
+}
+
+type NutrientDTO struct {
+	Key        string  `json:"key"`
+	Name       string  `json:"name"`
+	Unit       string  `json:"unit"`
+	DailyValue float64 `json:"dailyValue"` // Recommended daily intake, in unit
+	Limit      bool    `json:"limit"`      // Whether dailyValue is an upper limit instead of a target
+}
+
+type NutrientIntakeDTO struct {
+	Key               string  `json:"key"`
+	Name              string  `json:"name"`
+	Unit              string  `json:"unit"`
+	Amount            float64 `json:"amount"`
+	DailyValue        float64 `json:"dailyValue"`
+	PercentDailyValue float64 `json:"percentDailyValue"`
+	Limit             bool    `json:"limit"`
+}
+
+func nutrientDTOFromNutrient(n *models.Nutrient) NutrientDTO {
+	return NutrientDTO{
+		Key:        n.Key,
+		Name:       n.Name,
+		Unit:       n.Unit,
+		DailyValue: n.DailyValue,
+		Limit:      n.Limit,
+	}
+}
+
+// nutrientIntakes compares amounts, by nutrient key, against
+// the daily value of every tracked nutrient.
+func (s *Server) nutrientIntakes(amounts map[string]float64) ([]NutrientIntakeDTO, error) {
+	nutrients, err := s.NutrientsRepo.GetNutrients()
+	if err != nil {
+		return nil, err
+	}
+	intakes := make([]NutrientIntakeDTO, len(nutrients))
+	for i, n := range nutrients {
+		intakes[i] = NutrientIntakeDTO{
+			Key:               n.Key,
+			Name:              n.Name,
+			Unit:              n.Unit,
+			Amount:            amounts[n.Key],
+			DailyValue:        n.DailyValue,
+			PercentDailyValue: amounts[n.Key] / n.DailyValue * 100,
+			Limit:             n.Limit,
+		}
+	}
+	return intakes, nil
+}
+
+// GetNutrients is the handler for GET requests to /nutrients
+// 	@ID GetNutrients
+// 	@Summary Get nutrients
+// 	@Description Get nutrients tracked besides calories and macronutrients,
+// 	@Description with their recommended daily values.
+// 	@Tags nutrients
+// 	@Success 200 {array} NutrientDTO
+// 	@Failure 500 {object} models.APIError
+// 	@Router /nutrients [get]
+func (s *Server) GetNutrients(c *gin.Context) {
+	nutrients, err := s.NutrientsRepo.GetNutrients()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	nutrientDTOs := make([]NutrientDTO, len(nutrients))
+	for i := range nutrients {
+		nutrientDTOs[i] = nutrientDTOFromNutrient(&nutrients[i])
+	}
+	c.JSON(http.StatusOK, nutrientDTOs)
+}
+
+// CreateNutrient is the handler for POST requests to /nutrients
+// 	@ID CreateNutrient
+// 	@Summary Create nutrient
+// 	@Description Start tracking a new nutrient.
+// 	@Description Requires Administrator role.
+// 	@Tags nutrients
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Param nutrient body CreateNutrientDTO true "Nutrient"
+// 	@Success 201 {object} NutrientDTO
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 401 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 409 {object} models.APIError
+// 	@Router /nutrients [post]
+func (s *Server) CreateNutrient(c *gin.Context) {
+	var cn CreateNutrientDTO
+	if err := c.ShouldBindJSON(&cn); err != nil {
+		respondError(c, validationProblem(&cn, "invalid nutrient", err))
+		return
+	}
+	if _, err := s.NutrientsRepo.GetNutrient(cn.Key); err != repository.ErrNotFound {
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		respondProblem(c, http.StatusConflict, models.ErrorCodeNutrientExists, "nutrient with key "+cn.Key+" already exists")
+		return
+	}
+	n, err := s.NutrientsRepo.CreateNutrient(&models.Nutrient{
+		Key:        cn.Key,
+		Name:       cn.Name,
+		Unit:       cn.Unit,
+		DailyValue: cn.DailyValue,
+		Limit:      cn.Limit,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, nutrientDTOFromNutrient(n))
+}
+
+// UpdateNutrient is the handler for PUT requests to /nutrients/:key
+// 	@ID UpdateNutrient
+// 	@Summary Update nutrient
+// 	@Description Update matching nutrient with provided data.
+// 	@Description Requires Administrator role.
+// 	@Tags nutrients
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Param key path string true "Nutrient key"
+// 	@Param nutrient body UpdateNutrientDTO true "Nutrient"
+// 	@Success 200 {object} NutrientDTO
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 401 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 404 {object} models.APIError
+// 	@Router /nutrients/{key} [put]
+func (s *Server) UpdateNutrient(c *gin.Context) {
+	var un UpdateNutrientDTO
+	if err := c.ShouldBindJSON(&un); err != nil {
+		respondError(c, validationProblem(&un, "invalid nutrient", err))
+		return
+	}
+	n, err := s.NutrientsRepo.GetNutrient(c.Param("key"))
+	if err == repository.ErrNotFound {
+		respondProblem(c, http.StatusNotFound, models.ErrorCodeNutrientNotFound, "nutrient with provided key not found")
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	n.Name = un.Name
+	n.Unit = un.Unit
+	n.DailyValue = un.DailyValue
+	n.Limit = un.Limit
+
+	n, err = s.NutrientsRepo.UpdateNutrient(n)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, nutrientDTOFromNutrient(n))
+}
@@ -0,0 +1,97 @@
+package server_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/repository"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/server"
+)
+
+func TestGetNutrientsReturnsDefaultNutrients(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	res := doRequest(t, http.MethodGet, ts.URL+"/v1/nutrients/", "", nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+	var nutrients []server.NutrientDTO
+	decodeBody(t, res, &nutrients)
+	if len(nutrients) != len(repository.DefaultNutrients) {
+		t.Fatalf("Expected %d nutrients, got %v", len(repository.DefaultNutrients), len(nutrients))
+	}
+}
+
+func TestCreateFoodWithUnknownNutrientReturnBadRequest(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	createUser(s, "AccessToken", &models.User{Role: models.RoleWriter})
+
+	res := doRequest(t, http.MethodPost, ts.URL+"/v1/foods/", "AccessToken",
+		server.CreateFoodDTO{Name: "Orange", Nutrients: map[string]float64{"unobtainium": 1}})
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status code %d, got %v", http.StatusBadRequest, res.StatusCode)
+	}
+}
+
+func TestGetDiaryWeekReportsDeficienciesAndExcesses(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := createUser(s, "AccessToken", &models.User{Role: models.RoleWriter})
+	res := doRequest(t, http.MethodPost, ts.URL+"/v1/foods/", "AccessToken", server.CreateFoodDTO{
+		Name:        "Orange",
+		ServingSize: 100,
+		Calories:    47,
+		Nutrients:   map[string]float64{"vitamin_c": 90, "sodium": 3000},
+	})
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %v", http.StatusCreated, res.StatusCode)
+	}
+	var orange server.FoodDTO
+	decodeBody(t, res, &orange)
+	if orange.Nutrients["vitamin_c"] != 90 {
+		t.Fatalf("Expected 90 mg of vitamin C, got %v", orange.Nutrients)
+	}
+
+	// One orange a day in two days of the week, none in the other days
+	for _, date := range []string{"2022-05-01", "2022-05-03"} {
+		doRequest(t, http.MethodPost, fmt.Sprintf("%s/v1/users/%d/diary/%s", ts.URL, u.ID, date), "AccessToken",
+			server.CreateDiaryEntryDTO{Meal: models.MealSnack, FoodID: uintPtr(orange.ID), Quantity: 1, Unit: models.UnitServings})
+	}
+
+	res = doRequest(t, http.MethodGet, fmt.Sprintf("%s/v1/users/%d/diary/2022-05-07/week", ts.URL, u.ID), "AccessToken", nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+	var week server.DiaryWeekDTO
+	decodeBody(t, res, &week)
+	if week.From != "2022-05-01" || len(week.Days) != 7 || week.LoggedDays != 2 {
+		t.Fatalf("Expected 7 days from 2022-05-01 with 2 logged, got %v, %v and %v", week.From, len(week.Days), week.LoggedDays)
+	}
+	if !almostEqual(week.Average.Calories, 47) {
+		t.Fatalf("Expected 47 average calories, got %v", week.Average.Calories)
+	}
+	contains := func(keys []string, key string) bool {
+		for _, k := range keys {
+			if k == key {
+				return true
+			}
+		}
+		return false
+	}
+	if contains(week.Deficiencies, "vitamin_c") || !contains(week.Deficiencies, "iron") {
+		t.Fatalf("Expected iron but not vitamin C deficiency, got %v", week.Deficiencies)
+	}
+	if !contains(week.Excesses, "sodium") {
+		t.Fatalf("Expected sodium excess, got %v", week.Excesses)
+	}
+}
@@ -2,13 +2,30 @@ package server_test
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
 	"net/url"
 	"time"
 
 	"golang.org/x/oauth2"
 )
 
-type OAuth2ConfigMock struct{}
+// OAuth2ConfigMock authorizes every request,
+// checking the PKCE verifier of exchanges if a code challenge was sent.
+// Tokens carry the ID token returned by IDToken if it's set.
+type OAuth2ConfigMock struct {
+	IDToken       func(nonce string) string
+	codeChallenge string // Code challenge of last authorization request
+	nonce         string // Nonce of last authorization request
+}
+
+// authParams returns the parameters that opts add to OAuth2 requests.
+func authParams(opts ...oauth2.AuthCodeOption) url.Values {
+	c := &oauth2.Config{Endpoint: oauth2.Endpoint{AuthURL: "http://localhost"}}
+	u, _ := url.Parse(c.AuthCodeURL("", opts...))
+	return u.Query()
+}
 
 func (o *OAuth2ConfigMock) AuthCodeURL(state string, opts ...oauth2.AuthCodeOption) string {
 	u := url.URL{
@@ -17,16 +34,31 @@ func (o *OAuth2ConfigMock) AuthCodeURL(state string, opts ...oauth2.AuthCodeOpti
 		Path:   "v1/auth/authorize",
 	}
 
+	params := authParams(opts...)
+	o.codeChallenge = params.Get("code_challenge")
+	o.nonce = params.Get("nonce")
 	v := url.Values{}
 	v.Set("state", state)
+	v.Set("code_challenge", params.Get("code_challenge"))
+	v.Set("code_challenge_method", params.Get("code_challenge_method"))
 
 	u.RawQuery = v.Encode()
 	return u.String()
 }
 
 func (o *OAuth2ConfigMock) Exchange(ctx context.Context, code string, opts ...oauth2.AuthCodeOption) (*oauth2.Token, error) {
-	return &oauth2.Token{
+	if o.codeChallenge != "" {
+		sum := sha256.Sum256([]byte(authParams(opts...).Get("code_verifier")))
+		if base64.RawURLEncoding.EncodeToString(sum[:]) != o.codeChallenge {
+			return nil, errors.New("code verifier does not match code challenge")
+		}
+	}
+	token := &oauth2.Token{
 		AccessToken: "AccessToken",
 		Expiry:      time.Now().Add(1 * time.Hour),
-	}, nil
+	}
+	if o.IDToken != nil {
+		token = token.WithExtra(map[string]interface{}{"id_token": o.IDToken(o.nonce)})
+	}
+	return token, nil
 }
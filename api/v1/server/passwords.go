@@ -0,0 +1,480 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/repository"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/argon2"
+)
+
+var (
+	MinPasswordLength = 8
+	MaxPasswordLength = 128
+	// Argon2id parameters of new password hashes,
+	// existing hashes keep the parameters they were made with.
+	Argon2Time      uint32 = 1
+	Argon2Memory    uint32 = 64 * 1024 // In KiB
+	Argon2Threads   uint8  = 4
+	Argon2KeyLength uint32 = 32
+	// MaxLoginAttempts is the maximum of failed logins
+	// of each email and each client within LoginAttemptsWindow.
+	MaxLoginAttempts    = 5
+	LoginAttemptsWindow = 15 * time.Minute
+	// MaxPasswordResetRequests is the maximum of password reset requests
+	// of each email within PasswordResetRequestsWindow.
+	MaxPasswordResetRequests    = 3
+	PasswordResetRequestsWindow = time.Hour
+	// PasswordResetLifetime is the time password reset tokens last since they are sent.
+	PasswordResetLifetime = time.Hour
+	// EmailVerificationLifetime is the time email verification tokens last since they are sent.
+	EmailVerificationLifetime = 24 * time.Hour
+)
+
+var errInvalidPasswordHash = errors.New("invalid password hash")
+
+type RegisterDTO struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+	Username string `json:"username"` // Derived from the email if empty
+}
+
+type PasswordLoginDTO struct {
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type PasswordResetRequestDTO struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type PasswordResetDTO struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type EmailVerificationDTO struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// hashPassword returns the argon2id hash of password
+// in PHC string format, with a random salt.
+func hashPassword(password string) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, Argon2Time, Argon2Memory, Argon2Threads, Argon2KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, Argon2Memory, Argon2Time, Argon2Threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// verifyPassword reports whether password matches argon2id hash.
+func verifyPassword(password string, hash string) (bool, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return false, errInvalidPasswordHash
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, errInvalidPasswordHash
+	}
+	var memory, iterations uint32
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &threads); err != nil {
+		return false, errInvalidPasswordHash
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, errInvalidPasswordHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false, errInvalidPasswordHash
+	}
+	other := argon2.IDKey([]byte(password), salt, iterations, memory, threads, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     string
+)
+
+// dummyPasswordHash returns a hash that logins of unknown emails are verified against,
+// so they take as long as logins of existing users.
+func dummyPasswordHash() string {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = hashPassword(generateSecureToken(TokenLength))
+	})
+	return dummyHash
+}
+
+// validatePassword returns an error if password is too short or too long.
+func validatePassword(password string) error {
+	length := utf8.RuneCountInString(password)
+	if length < MinPasswordLength {
+		return fmt.Errorf("password must have at least %d characters", MinPasswordLength)
+	}
+	if length > MaxPasswordLength {
+		return fmt.Errorf("password must have at most %d characters", MaxPasswordLength)
+	}
+	return nil
+}
+
+// tooManyRequests responds 429 Too Many Requests
+// if any of keys can't attempt again at now in limiter.
+func tooManyRequests(c *gin.Context, limiter *rateLimiter, now time.Time, keys ...string) bool {
+	var wait time.Duration
+	for _, key := range keys {
+		if d := limiter.retryAfter(key, now); d > wait {
+			wait = d
+		}
+	}
+	if wait == 0 {
+		return false
+	}
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	respondProblem(c, http.StatusTooManyRequests, models.ErrorCodeRateLimited, "too many attempts, try again later")
+	return true
+}
+
+// Register is the handler for POST requests to /auth/register
+// 	@ID Register
+// 	@Summary Register local account
+// 	@Description Register a user that logs in with email and password at /auth/login.
+// 	@Description Users are Readers until they verify their email with the token emailed to it.
+// 	@Description Emails already registered get the same response, their owner is emailed instead,
+// 	@Description so emails can't be discovered.
+// 	@Tags auth
+// 	@Accept json
+// 	@Param account body RegisterDTO true "Account"
+// 	@Success 202
+// 	@Failure 400 {object} models.APIError
+// 	@Router /auth/register [post]
+func (s *Server) Register(c *gin.Context) {
+	var r RegisterDTO
+	if err := c.ShouldBindJSON(&r); err != nil {
+		respondError(c, validationProblem(&r, "invalid account", err))
+		return
+	}
+	if err := validatePassword(r.Password); err != nil {
+		respondError(c, fieldProblem(http.StatusBadRequest, models.ErrorCodeValidationFailed, err.Error(), "password", err.Error()))
+		return
+	}
+	r.Email = strings.TrimSpace(r.Email)
+	r.Username = strings.TrimSpace(r.Username)
+	if r.Username == "" {
+		r.Username = r.Email[:strings.Index(r.Email, "@")]
+	} else if message := usernameProblem(r.Username); message != "" {
+		respondError(c, fieldProblem(http.StatusBadRequest, models.ErrorCodeValidationFailed, "invalid account", "username", message))
+		return
+	}
+	// Hashed for existing emails too, so they take as long to respond
+	hash, err := hashPassword(r.Password)
+	if err != nil {
+		respondError(c, fmt.Errorf("could not hash password: %w", err))
+		return
+	}
+	existing, err := s.UsersRepo.GetUserByEmail(r.Email)
+	if err == nil {
+		if s.mailer != nil {
+			body := "Someone tried to register a Nutrity account with this email, which already has one.\n" +
+				"\nIf it was you, log in or reset your password instead. Otherwise, you can ignore this email.\n"
+			if err := s.mailer.SendMail(existing.Email, "Your Nutrity account", body); err != nil {
+				log.Printf("could not send registration attempt email to user %d: %v", existing.ID, err)
+			}
+		}
+		c.Status(http.StatusAccepted)
+		return
+	}
+	if err != repository.ErrNotFound {
+		respondError(c, err)
+		return
+	}
+	// Roles bootstrapped for emails are only given once users verify them
+	u, err := s.UsersRepo.CreateUser(&models.User{
+		Role:         models.RoleReader,
+		Username:     r.Username,
+		Email:        r.Email,
+		PasswordHash: hash,
+	})
+	if err != nil {
+		respondError(c, fmt.Errorf("could not register user: %w", err))
+		return
+	}
+	if s.mailer != nil {
+		if err := s.sendEmailVerification(u); err != nil {
+			log.Printf("could not send email verification to user %d: %v", u.ID, err)
+		}
+	}
+	c.Status(http.StatusAccepted)
+}
+
+// PasswordLogin is the handler for POST requests to /auth/login
+// 	@ID PasswordLogin
+// 	@Summary Log in with password
+// 	@Description Log in to local account, returning the access and refresh tokens of a new session.
+// 	@Description Restores the account if it was deleted and not purged yet.
+// 	@Description Failed attempts are limited for each email and each client.
+// 	@Tags auth
+// 	@Accept json
+// 	@Produce json
+// 	@Param credentials body PasswordLoginDTO true "Credentials"
+// 	@Success 200 {object} tokenResponse
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 401 {object} models.APIError
+// 	@Failure 429 {object} models.APIError
+// 	@Router /auth/login [post]
+func (s *Server) PasswordLogin(c *gin.Context) {
+	var l PasswordLoginDTO
+	if err := c.ShouldBindJSON(&l); err != nil {
+		respondError(c, validationProblem(&l, "invalid credentials", err))
+		return
+	}
+	now := time.Now()
+	emailKey := "email:" + strings.ToLower(strings.TrimSpace(l.Email))
+	clientKey := "client:" + c.ClientIP()
+	if tooManyRequests(c, s.loginLimiter, now, emailKey, clientKey) {
+		return
+	}
+	u, err := s.UsersRepo.GetUserByEmail(strings.TrimSpace(l.Email))
+	if err != nil && err != repository.ErrNotFound {
+		respondError(c, err)
+		return
+	}
+	hash := dummyPasswordHash()
+	if u != nil && u.PasswordHash != "" {
+		hash = u.PasswordHash
+	}
+	match, err := verifyPassword(l.Password, hash)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if !match || u == nil || u.PasswordHash == "" {
+		s.loginLimiter.add(emailKey, now)
+		s.loginLimiter.add(clientKey, now)
+		respondProblem(c, http.StatusUnauthorized, models.ErrorCodeInvalidCredentials, "invalid email or password")
+		return
+	}
+	s.loginLimiter.reset(emailKey)
+	if err := s.restoreUser(u); err != nil {
+		respondError(c, fmt.Errorf("could not restore user: %w", err))
+		return
+	}
+
+	tokens, _, err := s.createSession(u, deviceLabel(c))
+	if err != nil {
+		respondError(c, fmt.Errorf("could not create session: %w", err))
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, tokens)
+}
+
+// RequestPasswordReset is the handler for POST requests to /auth/password-reset
+// 	@ID RequestPasswordReset
+// 	@Summary Request password reset
+// 	@Description Email a password reset token to the local account with email, if there is one.
+// 	@Description Requests are limited for each email.
+// 	@Description Not available on servers that can't send emails.
+// 	@Tags auth
+// 	@Accept json
+// 	@Param request body PasswordResetRequestDTO true "Password reset request"
+// 	@Success 202
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 429 {object} models.APIError
+// 	@Router /auth/password-reset [post]
+func (s *Server) RequestPasswordReset(c *gin.Context) {
+	var r PasswordResetRequestDTO
+	if err := c.ShouldBindJSON(&r); err != nil {
+		respondError(c, validationProblem(&r, "invalid password reset request", err))
+		return
+	}
+	now := time.Now()
+	key := strings.ToLower(strings.TrimSpace(r.Email))
+	if tooManyRequests(c, s.passwordResetLimiter, now, key) {
+		return
+	}
+	s.passwordResetLimiter.add(key, now)
+
+	u, err := s.UsersRepo.GetUserByEmail(strings.TrimSpace(r.Email))
+	if err == repository.ErrNotFound || (err == nil && u.PasswordHash == "") {
+		// Same response as existing accounts, so emails can't be discovered
+		c.Status(http.StatusAccepted)
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if err := s.PasswordResetsRepo.DeleteExpiredPasswordResetTokens(now); err != nil {
+		respondError(c, err)
+		return
+	}
+	token := generateSecureToken(TokenLength)
+	_, err = s.PasswordResetsRepo.CreatePasswordResetToken(&models.PasswordResetToken{
+		UserID:    u.ID,
+		TokenHash: repository.HashToken(token),
+		CreatedAt: now,
+		ExpiresAt: now.Add(PasswordResetLifetime),
+	})
+	if err != nil {
+		respondError(c, fmt.Errorf("could not create password reset token: %w", err))
+		return
+	}
+	body := "Use this token within " + PasswordResetLifetime.String() + " to reset your Nutrity password:\n\n" + token + "\n"
+	if s.passwordResetURL != "" {
+		body += "\nOr follow this link: " + s.passwordResetURL + "?token=" + url.QueryEscape(token) + "\n"
+	}
+	body += "\nIf you did not request it, you can ignore this email.\n"
+	if err := s.mailer.SendMail(u.Email, "Reset your Nutrity password", body); err != nil {
+		log.Printf("could not send password reset email to user %d: %v", u.ID, err)
+	}
+	c.Status(http.StatusAccepted)
+}
+
+// ResetPassword is the handler for POST requests to /auth/password-reset/confirm
+// 	@ID ResetPassword
+// 	@Summary Reset password
+// 	@Description Set a new password with a password reset token,
+// 	@Description ending every session of the user.
+// 	@Tags auth
+// 	@Accept json
+// 	@Param reset body PasswordResetDTO true "Password reset"
+// 	@Success 204
+// 	@Failure 400 {object} models.APIError
+// 	@Router /auth/password-reset/confirm [post]
+func (s *Server) ResetPassword(c *gin.Context) {
+	var r PasswordResetDTO
+	if err := c.ShouldBindJSON(&r); err != nil {
+		respondError(c, validationProblem(&r, "invalid password reset", err))
+		return
+	}
+	if err := validatePassword(r.Password); err != nil {
+		respondError(c, fieldProblem(http.StatusBadRequest, models.ErrorCodeValidationFailed, err.Error(), "password", err.Error()))
+		return
+	}
+	prt, err := s.PasswordResetsRepo.GetPasswordResetTokenByHash(repository.HashToken(r.Token))
+	if err == repository.ErrNotFound || (err == nil && !time.Now().Before(prt.ExpiresAt)) {
+		respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidToken, "invalid or expired password reset token")
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	u, err := s.UsersRepo.GetUser(prt.UserID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	// Reset tokens are only sent to the email of the user
+	u.EmailVerified = true
+	u.PasswordHash, err = hashPassword(r.Password)
+	if err != nil {
+		respondError(c, fmt.Errorf("could not hash password: %w", err))
+		return
+	}
+	if _, err := s.UsersRepo.UpdateUser(u); err != nil {
+		respondError(c, err)
+		return
+	}
+	if err := s.PasswordResetsRepo.DeletePasswordResetTokens(u.ID); err != nil {
+		respondError(c, err)
+		return
+	}
+	if err := s.SessionsRepo.DeleteUserSessions(u.ID); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// sendEmailVerification emails u a token that verifies its email.
+func (s *Server) sendEmailVerification(u *models.User) error {
+	now := time.Now()
+	if err := s.EmailVerificationsRepo.DeleteExpiredEmailVerificationTokens(now); err != nil {
+		return err
+	}
+	token := generateSecureToken(TokenLength)
+	_, err := s.EmailVerificationsRepo.CreateEmailVerificationToken(&models.EmailVerificationToken{
+		UserID:    u.ID,
+		Email:     u.Email,
+		TokenHash: repository.HashToken(token),
+		CreatedAt: now,
+		ExpiresAt: now.Add(EmailVerificationLifetime),
+	})
+	if err != nil {
+		return err
+	}
+	body := "Use this token within " + EmailVerificationLifetime.String() + " to verify the email of your Nutrity account:\n\n" + token + "\n" +
+		"\nIf you did not register, you can ignore this email.\n"
+	return s.mailer.SendMail(u.Email, "Verify your Nutrity email", body)
+}
+
+// VerifyEmail is the handler for POST requests to /auth/verify-email
+// 	@ID VerifyEmail
+// 	@Summary Verify email
+// 	@Description Verify the email of a user with the token emailed to it on registration.
+// 	@Description Users whose email is given Administrator role become administrators.
+// 	@Tags auth
+// 	@Accept json
+// 	@Param verification body EmailVerificationDTO true "Email verification"
+// 	@Success 204
+// 	@Failure 400 {object} models.APIError
+// 	@Router /auth/verify-email [post]
+func (s *Server) VerifyEmail(c *gin.Context) {
+	var v EmailVerificationDTO
+	if err := c.ShouldBindJSON(&v); err != nil {
+		respondError(c, validationProblem(&v, "invalid email verification", err))
+		return
+	}
+	evt, err := s.EmailVerificationsRepo.GetEmailVerificationTokenByHash(repository.HashToken(v.Token))
+	if err == repository.ErrNotFound || (err == nil && !time.Now().Before(evt.ExpiresAt)) {
+		respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidToken, "invalid or expired email verification token")
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	u, err := s.UsersRepo.GetUser(evt.UserID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	// Tokens only verify the email they were sent to, users could have changed it since
+	if !strings.EqualFold(evt.Email, u.Email) {
+		respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidToken, "invalid or expired email verification token")
+		return
+	}
+	u.EmailVerified = true
+	if u.Role != models.RoleAdministrator && s.roleForEmail(u.Email) == models.RoleAdministrator {
+		u.Role = models.RoleAdministrator
+	}
+	if _, err := s.UsersRepo.UpdateUser(u); err != nil {
+		respondError(c, err)
+		return
+	}
+	if err := s.EmailVerificationsRepo.DeleteEmailVerificationTokens(u.ID); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
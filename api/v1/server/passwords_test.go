@@ -0,0 +1,231 @@
+package server_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/server"
+)
+
+// MailerMock records the emails it's asked to send.
+type MailerMock struct {
+	To   []string
+	Body []string
+}
+
+func (m *MailerMock) SendMail(to string, subject string, body string) error {
+	m.To = append(m.To, to)
+	m.Body = append(m.Body, body)
+	return nil
+}
+
+// register registers a local account and logs in, returning the access token of its session.
+func register(t *testing.T, ts *httptest.Server, email string, password string) string {
+	t.Helper()
+	res := doRequest(t, http.MethodPost, ts.URL+"/v1/auth/register", "", server.RegisterDTO{Email: email, Password: password})
+	if res.StatusCode != http.StatusAccepted {
+		t.Fatalf("Expected status code %d, got %v", http.StatusAccepted, res.StatusCode)
+	}
+	res = doRequest(t, http.MethodPost, ts.URL+"/v1/auth/login", "", server.PasswordLoginDTO{Email: email, Password: password})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+	var tk tokens
+	decodeBody(t, res, &tk)
+	return tk.AccessToken
+}
+
+func TestRegisterAndLoginWithPassword(t *testing.T) {
+	m := &MailerMock{}
+	s := newTestServer(server.ServerConfig{Mailer: m})
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	register(t, ts, "local@nutrity.test", "correct horse")
+	u, _ := s.UsersRepo.GetUserByEmail("local@nutrity.test")
+	if u.PasswordHash == "" || u.PasswordHash == "correct horse" {
+		t.Fatalf("Expected password to be stored hashed, got %q", u.PasswordHash)
+	}
+
+	// Registered emails respond the same, emailing their owner instead
+	res := doRequest(t, http.MethodPost, ts.URL+"/v1/auth/register", "", server.RegisterDTO{Email: "LOCAL@nutrity.test", Password: "another password"})
+	if res.StatusCode != http.StatusAccepted {
+		t.Fatalf("Expected status code %d, got %v", http.StatusAccepted, res.StatusCode)
+	}
+	if len(m.To) != 2 || m.To[1] != "local@nutrity.test" {
+		t.Fatalf("Expected email to owner of local@nutrity.test, got %v", m.To)
+	}
+	res = doRequest(t, http.MethodPost, ts.URL+"/v1/auth/login", "", server.PasswordLoginDTO{Email: "local@nutrity.test", Password: "another password"})
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Expected status code %d for password of second registration, got %v", http.StatusUnauthorized, res.StatusCode)
+	}
+	res = doRequest(t, http.MethodPost, ts.URL+"/v1/auth/register", "", server.RegisterDTO{Email: "short@nutrity.test", Password: "short"})
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status code %d, got %v", http.StatusBadRequest, res.StatusCode)
+	}
+
+	res = doRequest(t, http.MethodPost, ts.URL+"/v1/auth/login", "", server.PasswordLoginDTO{Email: "local@nutrity.test", Password: "correct horse"})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+	var tk tokens
+	decodeBody(t, res, &tk)
+	res = doRequest(t, http.MethodGet, ts.URL+"/v1/auth/", tk.AccessToken, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+}
+
+func TestLoginWithPasswordIsRateLimited(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	register(t, ts, "local@nutrity.test", "correct horse")
+	for i := 0; i < server.MaxLoginAttempts; i++ {
+		res := doRequest(t, http.MethodPost, ts.URL+"/v1/auth/login", "", server.PasswordLoginDTO{Email: "local@nutrity.test", Password: "wrong password"})
+		if res.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("Expected status code %d, got %v", http.StatusUnauthorized, res.StatusCode)
+		}
+	}
+	res := doRequest(t, http.MethodPost, ts.URL+"/v1/auth/login", "", server.PasswordLoginDTO{Email: "local@nutrity.test", Password: "correct horse"})
+	if res.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("Expected status code %d, got %v", http.StatusTooManyRequests, res.StatusCode)
+	}
+	if res.Header.Get("Retry-After") == "" {
+		t.Fatalf("Expected Retry-After header to be set")
+	}
+}
+
+func TestLoginRateLimitOfClientIgnoresForwardedFor(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	for i := 0; i < server.MaxLoginAttempts; i++ {
+		res := doRequestWithHeader(t, http.MethodPost, ts.URL+"/v1/auth/login", "", "X-Forwarded-For", fmt.Sprintf("203.0.113.%d", i),
+			server.PasswordLoginDTO{Email: fmt.Sprintf("user%d@nutrity.test", i), Password: "wrong password"})
+		if res.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("Expected status code %d, got %v", http.StatusUnauthorized, res.StatusCode)
+		}
+	}
+	res := doRequestWithHeader(t, http.MethodPost, ts.URL+"/v1/auth/login", "", "X-Forwarded-For", "203.0.113.99",
+		server.PasswordLoginDTO{Email: "other@nutrity.test", Password: "wrong password"})
+	if res.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("Expected status code %d, got %v", http.StatusTooManyRequests, res.StatusCode)
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	m := &MailerMock{}
+	s := newTestServer(server.ServerConfig{Mailer: m})
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	at := register(t, ts, "local@nutrity.test", "correct horse")
+	// Discard the email verification sent on registration
+	*m = MailerMock{}
+
+	res := doRequest(t, http.MethodPost, ts.URL+"/v1/auth/password-reset", "", server.PasswordResetRequestDTO{Email: "unknown@nutrity.test"})
+	if res.StatusCode != http.StatusAccepted || len(m.Body) != 0 {
+		t.Fatalf("Expected status code %d without email, got %v and %d emails", http.StatusAccepted, res.StatusCode, len(m.Body))
+	}
+	res = doRequest(t, http.MethodPost, ts.URL+"/v1/auth/password-reset", "", server.PasswordResetRequestDTO{Email: "local@nutrity.test"})
+	if res.StatusCode != http.StatusAccepted || len(m.Body) != 1 {
+		t.Fatalf("Expected status code %d with email, got %v and %d emails", http.StatusAccepted, res.StatusCode, len(m.Body))
+	}
+	token := regexp.MustCompile(`[0-9a-f]{80}`).FindString(m.Body[0])
+
+	res = doRequest(t, http.MethodPost, ts.URL+"/v1/auth/password-reset/confirm", "", server.PasswordResetDTO{Token: token, Password: "battery staple"})
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %v", http.StatusNoContent, res.StatusCode)
+	}
+	res = doRequest(t, http.MethodPost, ts.URL+"/v1/auth/password-reset/confirm", "", server.PasswordResetDTO{Token: token, Password: "battery staple"})
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status code %d for used token, got %v", http.StatusBadRequest, res.StatusCode)
+	}
+
+	res = doRequest(t, http.MethodGet, ts.URL+"/v1/auth/", at, nil)
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Expected status code %d after reset, got %v", http.StatusUnauthorized, res.StatusCode)
+	}
+	res = doRequest(t, http.MethodPost, ts.URL+"/v1/auth/login", "", server.PasswordLoginDTO{Email: "local@nutrity.test", Password: "correct horse"})
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Expected status code %d for old password, got %v", http.StatusUnauthorized, res.StatusCode)
+	}
+	res = doRequest(t, http.MethodPost, ts.URL+"/v1/auth/login", "", server.PasswordLoginDTO{Email: "local@nutrity.test", Password: "battery staple"})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+}
+
+func TestRegisterWithAdministratorEmailPromotesOnlyOnceVerified(t *testing.T) {
+	m := &MailerMock{}
+	s := newTestServer(server.ServerConfig{Mailer: m, AdminEmails: []string{"admin@nutrity.test"}})
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	register(t, ts, "admin@nutrity.test", "correct horse")
+	u, _ := s.UsersRepo.GetUserByEmail("admin@nutrity.test")
+	if u.Role != models.RoleReader || u.EmailVerified {
+		t.Fatalf("Expected unverified %v, got %v verified %v", models.RoleReader, u.Role, u.EmailVerified)
+	}
+	if len(m.To) != 1 || m.To[0] != "admin@nutrity.test" {
+		t.Fatalf("Expected email verification sent to admin@nutrity.test, got %v", m.To)
+	}
+	token := regexp.MustCompile(`[0-9a-f]{80}`).FindString(m.Body[0])
+
+	res := doRequest(t, http.MethodPost, ts.URL+"/v1/auth/verify-email", "", server.EmailVerificationDTO{Token: "invalid"})
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status code %d, got %v", http.StatusBadRequest, res.StatusCode)
+	}
+	res = doRequest(t, http.MethodPost, ts.URL+"/v1/auth/verify-email", "", server.EmailVerificationDTO{Token: token})
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %v", http.StatusNoContent, res.StatusCode)
+	}
+	u, _ = s.UsersRepo.GetUser(u.ID)
+	if u.Role != models.RoleAdministrator || !u.EmailVerified {
+		t.Fatalf("Expected verified %v, got %v verified %v", models.RoleAdministrator, u.Role, u.EmailVerified)
+	}
+}
+
+func TestVerifyEmailChangedSinceTokenWasSentReturnBadRequest(t *testing.T) {
+	m := &MailerMock{}
+	s := newTestServer(server.ServerConfig{Mailer: m, AdminEmails: []string{"admin@nutrity.test"}})
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	at := register(t, ts, "local@nutrity.test", "correct horse")
+	token := regexp.MustCompile(`[0-9a-f]{80}`).FindString(m.Body[0])
+	u, _ := s.UsersRepo.GetUserByEmail("local@nutrity.test")
+	res := patchUser(t, ts, u.ID, at, `{"email": "admin@nutrity.test"}`)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+
+	res = doRequest(t, http.MethodPost, ts.URL+"/v1/auth/verify-email", "", server.EmailVerificationDTO{Token: token})
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status code %d, got %v", http.StatusBadRequest, res.StatusCode)
+	}
+	if u, _ = s.UsersRepo.GetUser(u.ID); u.Role == models.RoleAdministrator || u.EmailVerified {
+		t.Fatalf("Expected unverified user not promoted, got %v verified %v", u.Role, u.EmailVerified)
+	}
+}
+
+func TestPasswordResetWithoutMailerIsNotAvailable(t *testing.T) {
+	s, err := server.NewServer(server.ServerConfig{Hostname: "https://nutrity.test"})
+	if err != nil {
+		t.Fatalf("Could not create server: %v", err)
+	}
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	res := doRequest(t, http.MethodPost, ts.URL+"/v1/auth/password-reset", "", server.PasswordResetRequestDTO{Email: "local@nutrity.test"})
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected status code %d, got %v", http.StatusNotFound, res.StatusCode)
+	}
+}
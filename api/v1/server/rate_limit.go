@@ -0,0 +1,71 @@
+package server
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimiter limits the attempts of each key
+// to a maximum within a sliding time window.
+type rateLimiter struct {
+	max       int
+	window    time.Duration
+	mu        sync.Mutex
+	attempts  map[string][]time.Time
+	lastSweep time.Time
+}
+
+func newRateLimiter(max int, window time.Duration) *rateLimiter {
+	return &rateLimiter{
+		max:      max,
+		window:   window,
+		attempts: map[string][]time.Time{},
+	}
+}
+
+// retryAfter returns the time until key can attempt again since now,
+// or zero if it can attempt already.
+func (l *rateLimiter) retryAfter(key string, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	attempts := l.recent(key, now)
+	if len(attempts) < l.max {
+		return 0
+	}
+	return attempts[len(attempts)-l.max].Add(l.window).Sub(now)
+}
+
+// add records an attempt of key at now.
+func (l *rateLimiter) add(key string, now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.attempts[key] = append(l.recent(key, now), now)
+	if now.Sub(l.lastSweep) >= l.window {
+		for k := range l.attempts {
+			if len(l.recent(k, now)) == 0 {
+				delete(l.attempts, k)
+			}
+		}
+		l.lastSweep = now
+	}
+}
+
+// reset forgets the attempts of key.
+func (l *rateLimiter) reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.attempts, key)
+}
+
+// recent returns the attempts of key within the window ending at now,
+// forgetting older ones. Must be called holding mu.
+func (l *rateLimiter) recent(key string, now time.Time) []time.Time {
+	attempts := l.attempts[key]
+	i := 0
+	for i < len(attempts) && !attempts[i].After(now.Add(-l.window)) {
+		i++
+	}
+	attempts = attempts[i:]
+	l.attempts[key] = attempts
+	return attempts
+}
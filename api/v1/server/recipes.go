@@ -0,0 +1,259 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/repository"
+	"github.com/gin-gonic/gin"
+)
+
+type RecipeIngredientDTO struct {
+	Name     string `json:"name" binding:"required"`
+	Quantity string `json:"quantity"`
+}
+
+type CreateRecipeDTO struct {
+	Name        string                `json:"name" binding:"required"`
+	Servings    uint                  `json:"servings" binding:"required,min=1"`
+	Ingredients []RecipeIngredientDTO `json:"ingredients" binding:"dive"`
+	Calories    float64               `json:"calories" binding:"min=0"`
+	Carbs       float64               `json:"carbs" binding:"min=0"`
+	Fats        float64               `json:"fats" binding:"min=0"`
+	Proteins    float64               `json:"proteins" binding:"min=0"`
+}
+
+type UpdateRecipeDTO struct {
+	Name        string                `json:"name" binding:"required"`
+	Servings    uint                  `json:"servings" binding:"required,min=1"`
+	Ingredients []RecipeIngredientDTO `json:"ingredients" binding:"dive"`
+	Calories    float64               `json:"calories" binding:"min=0"`
+	Carbs       float64               `json:"carbs" binding:"min=0"`
+	Fats        float64               `json:"fats" binding:"min=0"`
+	Proteins    float64               `json:"proteins" binding:"min=0"`
+}
+
+type RecipeDTO struct {
+	ID          uint                  `json:"id,omitempty"`
+	Name        string                `json:"name"`
+	Servings    uint                  `json:"servings"`
+	Ingredients []RecipeIngredientDTO `json:"ingredients"`
+	Calories    float64               `json:"calories"` // Per serving
+	Carbs       float64               `json:"carbs"`    // Per serving
+	Fats        float64               `json:"fats"`     // Per serving
+	Proteins    float64               `json:"proteins"` // Per serving
+	AuthorID    uint                  `json:"authorId"`
+}
+
+func recipeDTOFromRecipe(r *models.Recipe) RecipeDTO {
+	ingredients := make([]RecipeIngredientDTO, len(r.Ingredients))
+	for i, ing := range r.Ingredients {
+		ingredients[i] = RecipeIngredientDTO{Name: ing.Name, Quantity: ing.Quantity}
+	}
+	return RecipeDTO{
+		ID:          r.ID,
+		Name:        r.Name,
+		Servings:    r.Servings,
+		Ingredients: ingredients,
+		Calories:    r.Calories,
+		Carbs:       r.Carbs,
+		Fats:        r.Fats,
+		Proteins:    r.Proteins,
+		AuthorID:    r.AuthorID,
+	}
+}
+
+func ingredientsFromDTOs(ingDTOs []RecipeIngredientDTO) []models.RecipeIngredient {
+	var ingredients []models.RecipeIngredient
+	for _, ing := range ingDTOs {
+		ingredients = append(ingredients, models.RecipeIngredient{Name: ing.Name, Quantity: ing.Quantity})
+	}
+	return ingredients
+}
+
+// GetAllRecipes is the handler for GET requests to /recipes
+// 	@ID GetAllRecipes
+// 	@Summary Get all recipes
+// 	@Description Get all registered recipes.
+// 	@Tags recipes
+// 	@Success 200 {array} RecipeDTO
+// 	@Failure 500 {object} models.APIError
+// 	@Router /recipes [get]
+func (s *Server) GetAllRecipes(c *gin.Context) {
+	recipes, err := s.RecipesRepo.GetAllRecipes()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	recipeDTOs := make([]RecipeDTO, len(recipes))
+	for i := range recipes {
+		recipeDTOs[i] = recipeDTOFromRecipe(&recipes[i])
+	}
+	c.JSON(http.StatusOK, recipeDTOs)
+}
+
+// GetRecipe is the handler for GET requests to /recipes/:id
+// 	@ID GetRecipe
+// 	@Summary Get recipe
+// 	@Description Get recipe with matching ID.
+// 	@Tags recipes
+// 	@Param id path int true "Recipe ID"
+// 	@Success 200 {object} RecipeDTO
+// 	@Failure 404 {object} models.APIError
+// 	@Router /recipes/{id} [get]
+func (s *Server) GetRecipe(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "invalid id: "+err.Error())
+		return
+	}
+	r, err := s.RecipesRepo.GetRecipe(uint(id))
+	if err == repository.ErrNotFound {
+		respondProblem(c, http.StatusNotFound, models.ErrorCodeRecipeNotFound, "recipe with provided id not found")
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, recipeDTOFromRecipe(r))
+}
+
+// CreateRecipe is the handler for POST requests to /recipes
+// 	@ID CreateRecipe
+// 	@Summary Create recipe
+// 	@Description Register a new recipe authored by authenticated user.
+// 	@Description Requires Writer or Administrator role.
+// 	@Tags recipes
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Param recipe body CreateRecipeDTO true "Recipe"
+// 	@Success 201 {object} RecipeDTO
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 401 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Router /recipes [post]
+func (s *Server) CreateRecipe(c *gin.Context) {
+	au := authenticatedUser(c)
+	var cr CreateRecipeDTO
+	if err := c.ShouldBindJSON(&cr); err != nil {
+		respondError(c, validationProblem(&cr, "invalid recipe", err))
+		return
+	}
+	r := &models.Recipe{
+		Name:        cr.Name,
+		Servings:    cr.Servings,
+		Ingredients: ingredientsFromDTOs(cr.Ingredients),
+		Calories:    cr.Calories,
+		Carbs:       cr.Carbs,
+		Fats:        cr.Fats,
+		Proteins:    cr.Proteins,
+		AuthorID:    au.ID,
+	}
+	r, err := s.RecipesRepo.CreateRecipe(r)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, recipeDTOFromRecipe(r))
+}
+
+// UpdateRecipe is the handler for PUT requests to /recipes/:id
+// 	@ID UpdateRecipe
+// 	@Summary Update recipe
+// 	@Description Update matching recipe with provided data.
+// 	@Description Only the author of a recipe or an administrator can update it.
+// 	@Tags recipes
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Param id path int true "Recipe ID"
+// 	@Param recipe body UpdateRecipeDTO true "Recipe"
+// 	@Success 200 {object} RecipeDTO
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 401 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 404 {object} models.APIError
+// 	@Router /recipes/{id} [put]
+func (s *Server) UpdateRecipe(c *gin.Context) {
+	au := authenticatedUser(c)
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "invalid id: "+err.Error())
+		return
+	}
+	var ur UpdateRecipeDTO
+	if err := c.ShouldBindJSON(&ur); err != nil {
+		respondError(c, validationProblem(&ur, "invalid recipe", err))
+		return
+	}
+	r, err := s.RecipesRepo.GetRecipe(uint(id))
+	if err == repository.ErrNotFound {
+		respondProblem(c, http.StatusNotFound, models.ErrorCodeRecipeNotFound, "recipe with provided id not found")
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if r.AuthorID != au.ID && au.Role != models.RoleAdministrator {
+		respondProblem(c, http.StatusForbidden, models.ErrorCodeForbidden, "recipe is not authored by authenticated user")
+		return
+	}
+
+	r.Name = ur.Name
+	r.Servings = ur.Servings
+	r.Ingredients = ingredientsFromDTOs(ur.Ingredients)
+	r.Calories = ur.Calories
+	r.Carbs = ur.Carbs
+	r.Fats = ur.Fats
+	r.Proteins = ur.Proteins
+
+	r, err = s.RecipesRepo.UpdateRecipe(r)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, recipeDTOFromRecipe(r))
+}
+
+// DeleteRecipe is the handler for DELETE requests to /recipes/:id
+// 	@ID DeleteRecipe
+// 	@Summary Delete recipe
+// 	@Description Delete matching recipe.
+// 	@Description Only the author of a recipe or an administrator can delete it.
+// 	@Tags recipes
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Param id path int true "Recipe ID"
+// 	@Success 204
+// 	@Failure 401 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 404 {object} models.APIError
+// 	@Router /recipes/{id} [delete]
+func (s *Server) DeleteRecipe(c *gin.Context) {
+	au := authenticatedUser(c)
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "invalid id: "+err.Error())
+		return
+	}
+	r, err := s.RecipesRepo.GetRecipe(uint(id))
+	if err == repository.ErrNotFound {
+		respondProblem(c, http.StatusNotFound, models.ErrorCodeRecipeNotFound, "recipe with provided id not found")
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if r.AuthorID != au.ID && au.Role != models.RoleAdministrator {
+		respondProblem(c, http.StatusForbidden, models.ErrorCodeForbidden, "recipe is not authored by authenticated user")
+		return
+	}
+	if err := s.RecipesRepo.DeleteRecipe(r.ID); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
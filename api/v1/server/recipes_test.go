@@ -0,0 +1,182 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/repository"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/repository/mocks"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/server"
+	"github.com/golang/mock/gomock"
+)
+
+var mockRecipes = []models.Recipe{
+	{ID: 12, Name: "Pancakes", Servings: 4, AuthorID: 1, Calories: 227, Carbs: 28, Fats: 9.7, Proteins: 6.4,
+		Ingredients: []models.RecipeIngredient{{Name: "Flour", Quantity: "1 1/2 cups"}, {Name: "Milk", Quantity: "1 1/4 cups"}}},
+	{ID: 34, Name: "Guacamole", Servings: 2, AuthorID: 2},
+}
+
+func TestGetRecipe(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	rToGet := mockRecipes[0]
+
+	mockRecipesRepo := mocks.NewMockRecipesRepository(gomock.NewController(t))
+	mockRecipesRepo.EXPECT().GetRecipe(rToGet.ID).Return(&rToGet, nil)
+	s.RecipesRepo = mockRecipesRepo
+
+	res, err := http.Get(fmt.Sprintf("%s/v1/recipes/%d", ts.URL, rToGet.ID))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+
+	var resRecipe server.RecipeDTO
+	err = json.NewDecoder(res.Body).Decode(&resRecipe)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resRecipe.Name != rToGet.Name || len(resRecipe.Ingredients) != len(rToGet.Ingredients) {
+		t.Fatalf("Expected %v, got %v", rToGet, resRecipe)
+	}
+}
+
+func TestGetRecipeNotFound(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	mockRecipesRepo := mocks.NewMockRecipesRepository(gomock.NewController(t))
+	mockRecipesRepo.EXPECT().GetRecipe(uint(99)).Return(nil, repository.ErrNotFound)
+	s.RecipesRepo = mockRecipesRepo
+
+	res, err := http.Get(fmt.Sprintf("%s/v1/recipes/%d", ts.URL, 99))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected status code %d, got %v", http.StatusNotFound, res.StatusCode)
+	}
+}
+
+func TestCreateRecipeAsWriterReturnCreated(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	au := mockUsers[0]
+	au.ID = 1
+	au.Role = models.RoleWriter
+
+	mockUsersRepo := mocks.NewMockUsersRepository(gomock.NewController(t))
+	authenticateAs(s, "AccessToken", au.ID)
+	mockUsersRepo.EXPECT().GetUser(au.ID).Return(&au, nil)
+	s.UsersRepo = mockUsersRepo
+
+	cr := server.CreateRecipeDTO{
+		Name:        "Pancakes",
+		Servings:    4,
+		Ingredients: []server.RecipeIngredientDTO{{Name: "Flour", Quantity: "1 1/2 cups"}},
+		Calories:    227,
+	}
+	crJSONBytes, err := json.Marshal(cr)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/v1/recipes/", ts.URL), bytes.NewBuffer(crJSONBytes))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Add(server.AccessTokenName, "AccessToken")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %v", http.StatusCreated, res.StatusCode)
+	}
+
+	var resRecipe server.RecipeDTO
+	err = json.NewDecoder(res.Body).Decode(&resRecipe)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resRecipe.ID == 0 || resRecipe.AuthorID != au.ID || len(resRecipe.Ingredients) != 1 {
+		t.Fatalf("Expected created recipe authored by %d, got %v", au.ID, resRecipe)
+	}
+}
+
+// TestUpdateRecipeAsDifferentUserReturnForbidden tests a request
+// in which a user tries to update a recipe authored by another user.
+func TestUpdateRecipeAsDifferentUserReturnForbidden(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	au := mockUsers[0]
+	au.ID = 1
+	rToUpdate := mockRecipes[1]
+
+	mockUsersRepo := mocks.NewMockUsersRepository(gomock.NewController(t))
+	authenticateAs(s, "AccessToken", au.ID)
+	mockUsersRepo.EXPECT().GetUser(au.ID).Return(&au, nil)
+	s.UsersRepo = mockUsersRepo
+	mockRecipesRepo := mocks.NewMockRecipesRepository(gomock.NewController(t))
+	mockRecipesRepo.EXPECT().GetRecipe(rToUpdate.ID).Return(&rToUpdate, nil)
+	s.RecipesRepo = mockRecipesRepo
+
+	ur := server.UpdateRecipeDTO{Name: "Updated name", Servings: 1}
+	urJSONBytes, err := json.Marshal(ur)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/v1/recipes/%d", ts.URL, rToUpdate.ID), bytes.NewBuffer(urJSONBytes))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Add(server.AccessTokenName, "AccessToken")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusForbidden {
+		t.Fatalf("Expected status code %d, got %v", http.StatusForbidden, res.StatusCode)
+	}
+}
+
+func TestDeleteRecipeChangesUsersThatAddedIt(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	author := createUser(s, "AuthorAccessToken", &models.User{Username: "Author", Role: models.RoleWriter})
+	r, _ := s.RecipesRepo.CreateRecipe(&models.Recipe{Name: "Pancakes", Servings: 4, AuthorID: author.ID})
+	u := createUser(s, "AccessToken", &models.User{Username: "User", RecipesAdded: []models.Recipe{*r}})
+	userURL := fmt.Sprintf("%s/v1/users/%d", ts.URL, u.ID)
+	etag := doRequest(t, http.MethodGet, userURL, "AccessToken", nil).Header.Get("ETag")
+
+	res := doRequest(t, http.MethodDelete, fmt.Sprintf("%s/v1/recipes/%d", ts.URL, r.ID), "AuthorAccessToken", nil)
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %v", http.StatusNoContent, res.StatusCode)
+	}
+	res = doRequestWithHeader(t, http.MethodGet, userURL, "AccessToken", "If-None-Match", etag, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+	var own server.UserDTO
+	decodeBody(t, res, &own)
+	if len(own.RecipesAdded) != 0 || fmt.Sprintf(`"%d"`, own.Version) != res.Header.Get("ETag") {
+		t.Fatalf("Expected user without recipe and version of its ETag %v, got %+v", res.Header.Get("ETag"), own)
+	}
+}
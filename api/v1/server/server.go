@@ -1,53 +1,194 @@
 package server
 
 import (
+	"fmt"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
 	"github.com/JonathanGzzBen/nutrity-api/api/v1/repository"
 	"github.com/gin-gonic/gin"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"github.com/swaggo/gin-swagger/swaggerFiles"
+	"net/http"
 )
 
 type Server struct {
-	googleClient IGoogleClient
-	googleConfig IOauthConfig
-	development  bool
-	Router       *gin.Engine
-	UsersRepo    repository.UsersRepository
+	identityProviders      map[string]IdentityProvider
+	development            bool
+	adminEmails            []string
+	hostname               string
+	redirectURIs           []string // Custom schemes and web origins clients are redirected to after logging in
+	mailer                 Mailer
+	passwordResetURL       string
+	loginLimiter           *rateLimiter
+	passwordResetLimiter   *rateLimiter
+	jwtKeys                *jwtKeySet // Keys that sign access tokens, opaque tokens are issued if nil
+	Router                 *gin.Engine
+	UsersRepo              repository.UsersRepository
+	RecipesRepo            repository.RecipesRepository
+	FoodsRepo              repository.FoodsRepository
+	DiaryRepo              repository.DiaryRepository
+	GoalsRepo              repository.GoalsRepository
+	MeasurementsRepo       repository.MeasurementsRepository
+	WaterRepo              repository.WaterRepository
+	NutrientsRepo          repository.NutrientsRepository
+	SessionsRepo           repository.SessionsRepository
+	LoginStatesRepo        repository.LoginStatesRepository
+	PasswordResetsRepo     repository.PasswordResetsRepository
+	EmailVerificationsRepo repository.EmailVerificationsRepository
 }
 
 type ServerConfig struct {
-	GoogleConfig IOauthConfig
-	Hostname     string
-	Development  bool
-	UsersRepo    repository.UsersRepository
+	IdentityProviders      map[string]IdentityProvider // Identity providers users log in with, by name
+	Hostname               string
+	Development            bool
+	AdminEmails            []string // Emails of users given Administrator role when they log in with an identity that verified them
+	JWTKeysDir             string   // Directory of keys that sign JWT access tokens, opaque tokens are issued if empty
+	Mailer                 Mailer   // Sender of password reset and email verification emails, logged during development if nil, otherwise password resets are disabled
+	PasswordResetURL       string   // Page where users reset their password, linked with the token in query parameter token
+	RedirectURIs           []string // Custom schemes, such as nutrity://, and web origins clients are redirected to after logging in
+	TrustedProxies         []string // Addresses or CIDRs of proxies trusted to forward client IPs in X-Forwarded-For, none if empty
+	UsersRepo              repository.UsersRepository
+	RecipesRepo            repository.RecipesRepository
+	FoodsRepo              repository.FoodsRepository
+	DiaryRepo              repository.DiaryRepository
+	GoalsRepo              repository.GoalsRepository
+	MeasurementsRepo       repository.MeasurementsRepository
+	WaterRepo              repository.WaterRepository
+	NutrientsRepo          repository.NutrientsRepository
+	SessionsRepo           repository.SessionsRepository
+	LoginStatesRepo        repository.LoginStatesRepository
+	PasswordResetsRepo     repository.PasswordResetsRepository
+	EmailVerificationsRepo repository.EmailVerificationsRepository
 }
 
-func NewServer(sc ServerConfig) *Server {
+// NewServer returns a server configured by sc,
+// or an error if its configuration is invalid.
+func NewServer(sc ServerConfig) (*Server, error) {
 	server := &Server{
-		googleConfig: sc.GoogleConfig,
-		development:  sc.Development,
-		UsersRepo:    sc.UsersRepo,
+		identityProviders:      sc.IdentityProviders,
+		development:            sc.Development,
+		adminEmails:            sc.AdminEmails,
+		hostname:               sc.Hostname,
+		redirectURIs:           sc.RedirectURIs,
+		mailer:                 sc.Mailer,
+		passwordResetURL:       sc.PasswordResetURL,
+		loginLimiter:           newRateLimiter(MaxLoginAttempts, LoginAttemptsWindow),
+		passwordResetLimiter:   newRateLimiter(MaxPasswordResetRequests, PasswordResetRequestsWindow),
+		UsersRepo:              sc.UsersRepo,
+		RecipesRepo:            sc.RecipesRepo,
+		FoodsRepo:              sc.FoodsRepo,
+		DiaryRepo:              sc.DiaryRepo,
+		GoalsRepo:              sc.GoalsRepo,
+		MeasurementsRepo:       sc.MeasurementsRepo,
+		WaterRepo:              sc.WaterRepo,
+		NutrientsRepo:          sc.NutrientsRepo,
+		SessionsRepo:           sc.SessionsRepo,
+		LoginStatesRepo:        sc.LoginStatesRepo,
+		PasswordResetsRepo:     sc.PasswordResetsRepo,
+		EmailVerificationsRepo: sc.EmailVerificationsRepo,
 	}
-	if sc.Development {
-		server.googleClient = &GoogleClientMock{}
-	} else {
-		server.googleClient = &GoogleClient{}
+	// Emails carry tokens that take over accounts, only logged during development
+	if server.mailer == nil && sc.Development {
+		server.mailer = &LogMailer{}
+	}
+	if sc.JWTKeysDir != "" {
+		keys, err := newJWTKeySet(sc.JWTKeysDir)
+		if err != nil {
+			return nil, fmt.Errorf("could not load JWT keys: %w", err)
+		}
+		server.jwtKeys = keys
 	}
 
 	router := gin.Default()
+	// Client IPs limit attempts, so they're only taken from headers of trusted proxies
+	if err := router.SetTrustedProxies(sc.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+	router.Use(requestID())
+	router.NoRoute(func(c *gin.Context) {
+		respondProblem(c, http.StatusNotFound, models.ErrorCodeNotFound, "route not found")
+	})
+	authenticated := server.authenticate()
+	writers := server.authenticate(models.RoleAdministrator, models.RoleWriter)
+	administrators := server.authenticate(models.RoleAdministrator)
+	identified := server.identify()
 	v1 := router.Group("/v1")
 	{
+		if server.jwtKeys != nil {
+			v1.GET("/.well-known/jwks.json", server.GetJWKS)
+		}
 		ur := v1.Group("/users")
 		{
-			ur.GET("/", server.GetAllUsers)
-			ur.GET("/:id", server.GetUser)
-			ur.PUT("/:id", server.UpdateUser)
+			ur.GET("/", administrators, server.GetAllUsers)
+			ur.GET("/:id", identified, server.GetUser)
+			ur.PUT("/:id", authenticated, server.UpdateUser)
+			ur.PATCH("/:id", authenticated, server.PatchUser)
+			ur.DELETE("/:id", authenticated, server.DeleteUser)
+			ur.GET("/:id/export", authenticated, server.ExportUser)
+			ur.PUT("/:id/role", administrators, server.UpdateUserRole)
+			ur.POST("/:id/merge", authenticated, server.MergeUser)
+			ur.GET("/:id/diary/:date", authenticated, server.GetDiaryDay)
+			ur.POST("/:id/diary/:date", authenticated, server.CreateDiaryEntry)
+			ur.GET("/:id/diary/:date/week", authenticated, server.GetDiaryWeek)
+			ur.PUT("/:id/diary/:date/:entryId", authenticated, server.UpdateDiaryEntry)
+			ur.DELETE("/:id/diary/:date/:entryId", authenticated, server.DeleteDiaryEntry)
+			ur.GET("/:id/goals", authenticated, server.GetGoal)
+			ur.GET("/:id/goals/history", authenticated, server.GetGoalsHistory)
+			ur.POST("/:id/goals", authenticated, server.CreateGoal)
+			ur.POST("/:id/goals/calculate", authenticated, server.CalculateGoals)
+			ur.GET("/:id/measurements", authenticated, server.GetMeasurements)
+			ur.GET("/:id/measurements/trend", authenticated, server.GetWeightTrend)
+			ur.GET("/:id/measurements/:measurementId", authenticated, server.GetMeasurement)
+			ur.POST("/:id/measurements", authenticated, server.CreateMeasurement)
+			ur.PUT("/:id/measurements/:measurementId", authenticated, server.UpdateMeasurement)
+			ur.DELETE("/:id/measurements/:measurementId", authenticated, server.DeleteMeasurement)
+			ur.GET("/:id/water/:date", authenticated, server.GetWaterDay)
+			ur.POST("/:id/water/:date", authenticated, server.CreateWaterEntry)
+			ur.DELETE("/:id/water/:date/:entryId", authenticated, server.DeleteWaterEntry)
+		}
+		rr := v1.Group("/recipes")
+		{
+			rr.GET("/", server.GetAllRecipes)
+			rr.GET("/:id", server.GetRecipe)
+			rr.POST("/", writers, server.CreateRecipe)
+			rr.PUT("/:id", authenticated, server.UpdateRecipe)
+			rr.DELETE("/:id", authenticated, server.DeleteRecipe)
+		}
+		fr := v1.Group("/foods")
+		{
+			fr.GET("/", server.SearchFoods)
+			fr.GET("/:id", server.GetFood)
+			fr.POST("/", writers, server.CreateFood)
+			fr.PUT("/:id", administrators, server.UpdateFood)
+			fr.DELETE("/:id", administrators, server.DeleteFood)
+		}
+		nr := v1.Group("/nutrients")
+		{
+			nr.GET("/", server.GetNutrients)
+			nr.POST("/", administrators, server.CreateNutrient)
+			nr.PUT("/:key", administrators, server.UpdateNutrient)
 		}
 		ar := v1.Group("/auth")
 		{
-			ar.GET("/", server.GetCurrentUser)
+			ar.GET("/", authenticated, server.GetCurrentUser)
+			ar.POST("/register", server.Register)
+			ar.POST("/login", server.PasswordLogin)
+			if server.mailer != nil {
+				ar.POST("/password-reset", server.RequestPasswordReset)
+				ar.POST("/password-reset/confirm", server.ResetPassword)
+				ar.POST("/verify-email", server.VerifyEmail)
+			}
 			ar.GET("/google-login", server.LoginGoogle)
 			ar.GET("/google-callback", server.GoogleCallback)
+			ar.GET("/:provider/login", server.Login)
+			ar.GET("/:provider/callback", server.Callback)
+			ar.POST("/:provider/link", authenticated, server.LinkIdentity)
+			ar.GET("/identities", authenticated, server.GetIdentities)
+			ar.POST("/identities", authenticated, server.LinkIdentityWithCode)
+			ar.DELETE("/identities/:id", authenticated, server.DeleteIdentity)
+			ar.POST("/token", server.Token)
+			ar.POST("/logout", authenticated, server.Logout)
+			ar.GET("/sessions", authenticated, server.GetSessions)
+			ar.DELETE("/sessions/:id", authenticated, server.DeleteSession)
 			if sc.Development {
 				ar.GET("/dev-authorize", server.devOAuthAuthorize)
 			}
@@ -57,9 +198,10 @@ func NewServer(sc ServerConfig) *Server {
 	swaggerUrl := ginSwagger.URL(sc.Hostname + "/v1/swagger/doc.json")
 	v1.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler, swaggerUrl))
 	server.Router = router
-	return server
+	return server, nil
 }
 
 func (s *Server) Run(port ...string) {
+	go s.purgeDeletedUsersPeriodically()
 	s.Router.Run(port[0])
 }
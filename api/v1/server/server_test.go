@@ -1,8 +1,14 @@
 package server_test
 
 import (
+	"bytes"
+	"encoding/json"
+	"net/http"
 	"os"
+	"testing"
+	"time"
 
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
 	"github.com/JonathanGzzBen/nutrity-api/api/v1/repository"
 	"github.com/JonathanGzzBen/nutrity-api/api/v1/server"
 	"gorm.io/driver/sqlite"
@@ -18,19 +24,43 @@ func (e *TestEnvironment) Close() {
 }
 
 func NewTestServer() *server.Server {
+	return newTestServer(server.ServerConfig{})
+}
+
+// newTestServer returns a test server with the repositories
+// and development settings set in sc, and mock identity providers
+// unless sc has some.
+func newTestServer(sc server.ServerConfig) *server.Server {
 	os.Remove("test.db")
 	db, err := gorm.Open(sqlite.Open("test.db"), &gorm.Config{})
 	if err != nil {
 		panic("Could not connect to database")
 	}
-	return server.NewServer(
-		server.ServerConfig{
-			GoogleConfig: &OAuth2ConfigMock{},
-			Hostname:     "http://localhost:8080",
-			Development:  true,
-			UsersRepo:    repository.NewUsersGormRepository(db),
-		},
-	)
+	if sc.IdentityProviders == nil {
+		sc.IdentityProviders = map[string]server.IdentityProvider{
+			"google": &server.OAuth2Provider{Config: &OAuth2ConfigMock{}, UserInfo: server.MockUserInfo},
+			"github": &server.OAuth2Provider{Config: &OAuth2ConfigMock{}, UserInfo: server.MockUserInfo},
+		}
+	}
+	sc.Hostname = "http://localhost:8080"
+	sc.Development = true
+	sc.UsersRepo = repository.NewUsersGormRepository(db)
+	sc.RecipesRepo = repository.NewRecipesGormRepository(db)
+	sc.FoodsRepo = repository.NewFoodsGormRepository(db)
+	sc.DiaryRepo = repository.NewDiaryGormRepository(db)
+	sc.GoalsRepo = repository.NewGoalsGormRepository(db)
+	sc.MeasurementsRepo = repository.NewMeasurementsGormRepository(db)
+	sc.WaterRepo = repository.NewWaterGormRepository(db)
+	sc.NutrientsRepo = repository.NewNutrientsGormRepository(db)
+	sc.SessionsRepo = repository.NewSessionsGormRepository(db)
+	sc.LoginStatesRepo = repository.NewLoginStatesGormRepository(db)
+	sc.PasswordResetsRepo = repository.NewPasswordResetsGormRepository(db)
+	sc.EmailVerificationsRepo = repository.NewEmailVerificationsGormRepository(db)
+	s, err := server.NewServer(sc)
+	if err != nil {
+		panic("Could not create server: " + err.Error())
+	}
+	return s
 }
 
 func NewTestEnvironment() *TestEnvironment {
@@ -39,16 +69,93 @@ func NewTestEnvironment() *TestEnvironment {
 	if err != nil {
 		panic("Could not connect to database")
 	}
-	server := server.NewServer(
+	server, err := server.NewServer(
 		server.ServerConfig{
-			GoogleConfig: &OAuth2ConfigMock{},
-			Hostname:     "http://localhost:8080",
-			Development:  true,
-			UsersRepo:    repository.NewUsersGormRepository(db),
+			IdentityProviders: map[string]server.IdentityProvider{
+				"google": &server.OAuth2Provider{Config: &OAuth2ConfigMock{}, UserInfo: server.MockUserInfo},
+			},
+			Hostname:               "http://localhost:8080",
+			Development:            true,
+			UsersRepo:              repository.NewUsersGormRepository(db),
+			RecipesRepo:            repository.NewRecipesGormRepository(db),
+			FoodsRepo:              repository.NewFoodsGormRepository(db),
+			DiaryRepo:              repository.NewDiaryGormRepository(db),
+			GoalsRepo:              repository.NewGoalsGormRepository(db),
+			MeasurementsRepo:       repository.NewMeasurementsGormRepository(db),
+			WaterRepo:              repository.NewWaterGormRepository(db),
+			NutrientsRepo:          repository.NewNutrientsGormRepository(db),
+			SessionsRepo:           repository.NewSessionsGormRepository(db),
+			LoginStatesRepo:        repository.NewLoginStatesGormRepository(db),
+			PasswordResetsRepo:     repository.NewPasswordResetsGormRepository(db),
+			EmailVerificationsRepo: repository.NewEmailVerificationsGormRepository(db),
 		},
 	)
+	if err != nil {
+		panic("Could not create server: " + err.Error())
+	}
 	ts := &TestEnvironment{
 		Server: server,
 	}
 	return ts
 }
+
+// doRequest sends a request with body encoded as JSON,
+// authenticated with access token at if it's not empty.
+func doRequest(t *testing.T, method string, url string, at string, body interface{}) *http.Response {
+	t.Helper()
+	return doRequestWithHeader(t, method, url, at, "", "", body)
+}
+
+// doRequestWithHeader sends a request like doRequest,
+// along with header set to value if header is not empty.
+func doRequestWithHeader(t *testing.T, method string, url string, at string, header string, value string, body interface{}) *http.Response {
+	t.Helper()
+	var reqBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reqBody).Encode(body); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	req, err := http.NewRequest(method, url, &reqBody)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if at != "" {
+		req.Header.Add(server.AccessTokenName, at)
+	}
+	if header != "" {
+		req.Header.Set(header, value)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return res
+}
+
+// createUser registers u along with a session authenticated by access token at.
+func createUser(s *server.Server, at string, u *models.User) *models.User {
+	u, _ = s.UsersRepo.CreateUser(u)
+	authenticateAs(s, at, u.ID)
+	return u
+}
+
+// authenticateAs makes access token at authenticate user with userID.
+func authenticateAs(s *server.Server, at string, userID uint) {
+	s.SessionsRepo.CreateSession(&models.Session{
+		UserID:          userID,
+		TokenHash:       repository.HashToken(at),
+		ExpiresAt:       time.Now().Add(time.Hour),
+		AccessExpiresAt: time.Now().Add(time.Hour),
+		LastUsedAt:      time.Now(),
+	})
+}
+
+// decodeBody decodes JSON body of res into v.
+func decodeBody(t *testing.T, res *http.Response, v interface{}) {
+	t.Helper()
+	defer res.Body.Close()
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
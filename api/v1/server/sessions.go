@@ -0,0 +1,343 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/repository"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	// AccessTokenLifetime is the time access tokens last since they are issued.
+	AccessTokenLifetime = time.Hour
+	// SessionLifetime is the time sessions last since they were last refreshed.
+	SessionLifetime = 30 * 24 * time.Hour
+	// SessionTouchInterval is the minimum time between
+	// updates of the last time a session was used.
+	SessionTouchInterval = time.Minute
+	// MaxDeviceLength is the maximum length of session device labels.
+	MaxDeviceLength = 100
+)
+
+var (
+	errSessionExpired      = errors.New("access token expired")
+	errInvalidRefreshToken = errors.New("invalid refresh token")
+	errRefreshTokenReused  = errors.New("refresh token already used, session revoked")
+)
+
+type SessionDTO struct {
+	ID         uint      `json:"id"`
+	Device     string    `json:"device"`
+	CreatedAt  time.Time `json:"createdAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+	Current    bool      `json:"current"` // Whether request was authenticated by this session
+}
+
+func sessionDTOFromSession(s *models.Session, current *models.Session) SessionDTO {
+	return SessionDTO{
+		ID:         s.ID,
+		Device:     s.Device,
+		CreatedAt:  s.CreatedAt,
+		ExpiresAt:  s.ExpiresAt,
+		LastUsedAt: s.LastUsedAt,
+		Current:    s.ID == current.ID,
+	}
+}
+
+// deviceLabel returns the label of the device that sent the request,
+// taken from query parameter device or else from its user agent.
+func deviceLabel(c *gin.Context) string {
+	device := c.Query("device")
+	if device == "" {
+		device = c.Request.UserAgent()
+	}
+	if len(device) > MaxDeviceLength {
+		device = device[:MaxDeviceLength]
+	}
+	return device
+}
+
+// issueTokens gives session of u a new access token and extends its lifetime from now,
+// returning the token response along with a new refresh token of session.
+//
+// Access tokens are signed JWTs if the server has JWT keys.
+func (s *Server) issueTokens(session *models.Session, u *models.User, now time.Time) (tokenResponse, *models.RefreshToken, error) {
+	session.AccessExpiresAt = now.Add(AccessTokenLifetime)
+	session.ExpiresAt = now.Add(SessionLifetime)
+	session.LastUsedAt = now
+	at := generateSecureToken(TokenLength)
+	if s.jwtKeys != nil {
+		var err error
+		at, err = s.signAccessToken(session, u, now, session.AccessExpiresAt)
+		if err != nil {
+			return tokenResponse{}, nil, err
+		}
+	}
+	session.TokenHash = repository.HashToken(at)
+	rt := generateSecureToken(TokenLength)
+	return tokenResponse{
+		AccessToken:  at,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(AccessTokenLifetime.Seconds()),
+		RefreshToken: rt,
+	}, &models.RefreshToken{
+		SessionID: session.ID,
+		TokenHash: repository.HashToken(rt),
+		CreatedAt: now,
+	}, nil
+}
+
+// createSession starts a session of u on device,
+// returning the tokens that authenticate and refresh it.
+func (s *Server) createSession(u *models.User, device string) (tokenResponse, *models.Session, error) {
+	now := time.Now()
+	if err := s.SessionsRepo.DeleteExpiredSessions(now); err != nil {
+		return tokenResponse{}, nil, err
+	}
+	// Session is created before issuing its tokens,
+	// since JWT access tokens include its ID.
+	session, err := s.SessionsRepo.CreateSession(&models.Session{
+		UserID:    u.ID,
+		TokenHash: repository.HashToken(generateSecureToken(TokenLength)),
+		Device:    device,
+		CreatedAt: now,
+		ExpiresAt: now,
+	})
+	if err != nil {
+		return tokenResponse{}, nil, err
+	}
+	tokens, rt, err := s.issueTokens(session, u, now)
+	if err == nil {
+		_, err = s.SessionsRepo.UpdateSession(session)
+	}
+	if err == nil {
+		_, err = s.SessionsRepo.CreateRefreshToken(rt)
+	}
+	if err != nil {
+		s.SessionsRepo.DeleteSession(session.ID)
+		return tokenResponse{}, nil, err
+	}
+	return tokens, session, nil
+}
+
+// refreshSession rotates the tokens of the session of refresh token,
+// which can only be used once.
+//
+// Reusing a refresh token revokes its session, ending every token
+// issued from it, since either the client or an attacker holds a stolen token.
+func (s *Server) refreshSession(refreshToken string) (tokenResponse, error) {
+	used, err := s.SessionsRepo.GetRefreshTokenByHash(repository.HashToken(refreshToken))
+	if err == repository.ErrNotFound {
+		return tokenResponse{}, errInvalidRefreshToken
+	}
+	if err != nil {
+		return tokenResponse{}, err
+	}
+	session, err := s.SessionsRepo.GetSession(used.SessionID)
+	if err == repository.ErrNotFound {
+		return tokenResponse{}, errInvalidRefreshToken
+	}
+	if err != nil {
+		return tokenResponse{}, err
+	}
+	if used.UsedAt != nil {
+		return tokenResponse{}, s.revokeSession(session)
+	}
+	now := time.Now()
+	if !now.Before(session.ExpiresAt) {
+		return tokenResponse{}, errInvalidRefreshToken
+	}
+	u, err := s.UsersRepo.GetUser(session.UserID)
+	if err == repository.ErrNotFound {
+		return tokenResponse{}, errInvalidRefreshToken
+	}
+	if err != nil {
+		return tokenResponse{}, err
+	}
+	tokens, next, err := s.issueTokens(session, u, now)
+	if err != nil {
+		return tokenResponse{}, err
+	}
+	err = s.SessionsRepo.RotateRefreshToken(used, next, session)
+	if err == repository.ErrAlreadyUsed {
+		return tokenResponse{}, s.revokeSession(session)
+	}
+	if err != nil {
+		return tokenResponse{}, err
+	}
+	return tokens, nil
+}
+
+// revokeSession deletes session after reuse of one of its refresh tokens,
+// returning errRefreshTokenReused if it succeeds.
+func (s *Server) revokeSession(session *models.Session) error {
+	if err := s.SessionsRepo.DeleteSession(session.ID); err != nil && err != repository.ErrNotFound {
+		return err
+	}
+	return errRefreshTokenReused
+}
+
+// sessionByAccessToken returns the unexpired session authenticated by at,
+// recording that it was used.
+//
+// JWT access tokens are verified and then looked up like opaque ones,
+// so they're revoked along with their session.
+func (s *Server) sessionByAccessToken(at string) (*models.Session, error) {
+	if s.jwtKeys != nil && isJWT(at) {
+		if err := s.verifyJWT(at); err != nil {
+			return nil, err
+		}
+	}
+	session, err := s.SessionsRepo.GetSessionByTokenHash(repository.HashToken(at))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !now.Before(session.AccessExpiresAt) || !now.Before(session.ExpiresAt) {
+		return nil, errSessionExpired
+	}
+	if now.Sub(session.LastUsedAt) >= SessionTouchInterval {
+		session.LastUsedAt = now
+		if _, err := s.SessionsRepo.UpdateSession(session); err != nil {
+			return nil, err
+		}
+	}
+	return session, nil
+}
+
+// Token is the handler for POST requests to /auth/token
+// 	@ID Token
+// 	@Summary Issue tokens
+// 	@Description Exchange a refresh token for a new access token and refresh token
+// 	@Description of the same session. Each refresh token can only be used once,
+// 	@Description reusing one revokes its whole session.
+// 	@Description Clients redirected after logging in exchange their one-time code
+// 	@Description for the tokens of a new session, with the redirect URI and PKCE verifier they logged in with.
+// 	@Tags auth
+// 	@Accept x-www-form-urlencoded
+// 	@Param grant_type formData string true "Grant type" Enums(refresh_token, authorization_code)
+// 	@Param refresh_token formData string false "Refresh token"
+// 	@Param code formData string false "Authorization code"
+// 	@Param redirect_uri formData string false "Redirect URI the code was issued to"
+// 	@Param code_verifier formData string false "PKCE code verifier"
+// 	@Success 200 {object} tokenResponse
+// 	@Failure 400 {object} models.APIError
+// 	@Router /auth/token [post]
+func (s *Server) Token(c *gin.Context) {
+	var tokens tokenResponse
+	var err error
+	switch grantType := c.PostForm("grant_type"); grantType {
+	case "refresh_token":
+		refreshToken := c.PostForm("refresh_token")
+		if refreshToken == "" {
+			respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "refresh_token required")
+			return
+		}
+		tokens, err = s.refreshSession(refreshToken)
+	case "authorization_code":
+		code := c.PostForm("code")
+		if code == "" {
+			respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "code required")
+			return
+		}
+		tokens, err = s.exchangeAuthorizationCode(code, c.PostForm("redirect_uri"), c.PostForm("code_verifier"))
+	default:
+		respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "unsupported grant_type: "+grantType)
+		return
+	}
+	if err == errInvalidRefreshToken || err == errRefreshTokenReused || err == errInvalidAuthorizationCode {
+		respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidGrant, err.Error())
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Logout is the handler for POST requests to /auth/logout
+// 	@ID Logout
+// 	@Summary Log out
+// 	@Description End the session that authenticated the request.
+// 	@Tags auth
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Success 204
+// 	@Failure 401 {object} models.APIError
+// 	@Router /auth/logout [post]
+func (s *Server) Logout(c *gin.Context) {
+	if err := s.SessionsRepo.DeleteSession(authenticatedSession(c).ID); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetSessions is the handler for GET requests to /auth/sessions
+// 	@ID GetSessions
+// 	@Summary Get sessions
+// 	@Description Get sessions of authenticated user, most recent first.
+// 	@Tags auth
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Success 200 {array} SessionDTO
+// 	@Failure 401 {object} models.APIError
+// 	@Router /auth/sessions [get]
+func (s *Server) GetSessions(c *gin.Context) {
+	current := authenticatedSession(c)
+	sessions, err := s.SessionsRepo.GetSessions(current.UserID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	sessionDTOs := make([]SessionDTO, 0, len(sessions))
+	now := time.Now()
+	for i := range sessions {
+		if now.Before(sessions[i].ExpiresAt) {
+			sessionDTOs = append(sessionDTOs, sessionDTOFromSession(&sessions[i], current))
+		}
+	}
+	c.JSON(http.StatusOK, sessionDTOs)
+}
+
+// DeleteSession is the handler for DELETE requests to /auth/sessions/:id
+// 	@ID DeleteSession
+// 	@Summary Delete session
+// 	@Description End matching session of authenticated user,
+// 	@Description revoking its access and refresh tokens.
+// 	@Tags auth
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Param id path int true "Session ID"
+// 	@Success 204
+// 	@Failure 401 {object} models.APIError
+// 	@Failure 404 {object} models.APIError
+// 	@Router /auth/sessions/{id} [delete]
+func (s *Server) DeleteSession(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "invalid id: "+err.Error())
+		return
+	}
+	session, err := s.SessionsRepo.GetSession(uint(id))
+	if err == repository.ErrNotFound || (err == nil && session.UserID != authenticatedUser(c).ID) {
+		respondProblem(c, http.StatusNotFound, models.ErrorCodeSessionNotFound, "session with provided id not found")
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if err := s.SessionsRepo.DeleteSession(session.ID); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
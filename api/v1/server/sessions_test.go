@@ -0,0 +1,304 @@
+package server_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/repository"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/server"
+)
+
+type tokens struct {
+	AccessToken  string `json:"access_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// startLogin starts a Google login,
+// returning the state of the URL it redirects to.
+func startLogin(t *testing.T, ts *httptest.Server) string {
+	t.Helper()
+	return startProviderLogin(t, ts, "/v1/auth/google-login")
+}
+
+// startProviderLogin starts a login at path,
+// returning the state of the URL it redirects to.
+func startProviderLogin(t *testing.T, ts *httptest.Server, path string) string {
+	t.Helper()
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	res, err := client.Get(ts.URL + path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusTemporaryRedirect {
+		t.Fatalf("Expected status code %d, got %v", http.StatusTemporaryRedirect, res.StatusCode)
+	}
+	location, err := url.Parse(res.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	state := location.Query().Get("state")
+	expectLoginStateCookie(t, res, state)
+	return state
+}
+
+// expectLoginStateCookie fails the test unless res sets the login state cookie
+// binding the login with state to the browser.
+func expectLoginStateCookie(t *testing.T, res *http.Response, state string) {
+	t.Helper()
+	for _, cookie := range res.Cookies() {
+		if cookie.Name == server.LoginStateCookie {
+			if cookie.Value != state || !cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode {
+				t.Fatalf("Expected HttpOnly and SameSite cookie with state %q, got %v", state, cookie)
+			}
+			return
+		}
+	}
+	t.Fatalf("Expected login state cookie to be set")
+}
+
+// loginStateCookie returns the Cookie header the browser that started the login with state sends.
+func loginStateCookie(state string) string {
+	return server.LoginStateCookie + "=" + state
+}
+
+// callback completes the Google login with state from device.
+func callback(t *testing.T, ts *httptest.Server, state string, device string) *http.Response {
+	t.Helper()
+	return doRequestWithHeader(t, http.MethodGet, ts.URL+"/v1/auth/google-callback?code=code&state="+url.QueryEscape(state)+"&device="+device, "",
+		"Cookie", loginStateCookie(state), nil)
+}
+
+// loginTokens logs in through Google from device,
+// returning the tokens of the new session.
+func loginTokens(t *testing.T, ts *httptest.Server, device string) tokens {
+	t.Helper()
+	res := callback(t, ts, startLogin(t, ts), device)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+	var tk tokens
+	decodeBody(t, res, &tk)
+	return tk
+}
+
+// login logs in through Google from device,
+// returning the access token of the new session.
+func login(t *testing.T, ts *httptest.Server, device string) string {
+	t.Helper()
+	return loginTokens(t, ts, device).AccessToken
+}
+
+// refresh exchanges refresh token rt for new tokens.
+func refresh(t *testing.T, ts *httptest.Server, rt string) *http.Response {
+	t.Helper()
+	res, err := http.PostForm(ts.URL+"/v1/auth/token", url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {rt},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return res
+}
+
+func TestLoginCreatesSessionPerDevice(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	phoneToken := login(t, ts, "Phone")
+	laptopToken := login(t, ts, "Laptop")
+	if phoneToken == laptopToken {
+		t.Fatalf("Expected different access tokens per login")
+	}
+	if _, err := s.SessionsRepo.GetSessionByTokenHash(phoneToken); err != repository.ErrNotFound {
+		t.Fatalf("Expected access tokens not to be stored in plaintext")
+	}
+
+	res := doRequest(t, http.MethodGet, ts.URL+"/v1/auth/sessions", laptopToken, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+	var sessions []server.SessionDTO
+	decodeBody(t, res, &sessions)
+	if len(sessions) != 2 {
+		t.Fatalf("Expected 2 sessions, got %v", len(sessions))
+	}
+	for _, session := range sessions {
+		if session.Current != (session.Device == "Laptop") {
+			t.Fatalf("Expected only Laptop session to be current, got %v", sessions)
+		}
+	}
+}
+
+func TestTokensHaveDeprecatedAliases(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	res := callback(t, ts, startLogin(t, ts), "Phone")
+	var body map[string]interface{}
+	decodeBody(t, res, &body)
+	if body["access_token"] == "" || body["AccessToken"] != body["access_token"] {
+		t.Fatalf("Expected AccessToken alias of access_token, got %v", body)
+	}
+	if body["token_type"] != "Bearer" || body["TokenType"] != body["token_type"] {
+		t.Fatalf("Expected TokenType alias of token_type, got %v", body)
+	}
+}
+
+func TestLogoutRevokesAccessToken(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	at := login(t, ts, "Phone")
+	res := doRequest(t, http.MethodPost, ts.URL+"/v1/auth/logout", at, nil)
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %v", http.StatusNoContent, res.StatusCode)
+	}
+	res = doRequest(t, http.MethodGet, ts.URL+"/v1/auth/", at, nil)
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Expected status code %d, got %v", http.StatusUnauthorized, res.StatusCode)
+	}
+}
+
+func TestDeleteSession(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	phoneToken := login(t, ts, "Phone")
+	laptopToken := login(t, ts, "Laptop")
+	createUser(s, "OtherAccessToken", &models.User{})
+	phone, _ := s.SessionsRepo.GetSessionByTokenHash(repository.HashToken(phoneToken))
+
+	res := doRequest(t, http.MethodDelete, fmt.Sprintf("%s/v1/auth/sessions/%d", ts.URL, phone.ID), "OtherAccessToken", nil)
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected status code %d, got %v", http.StatusNotFound, res.StatusCode)
+	}
+	res = doRequest(t, http.MethodDelete, fmt.Sprintf("%s/v1/auth/sessions/%d", ts.URL, phone.ID), laptopToken, nil)
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %v", http.StatusNoContent, res.StatusCode)
+	}
+	res = doRequest(t, http.MethodGet, ts.URL+"/v1/auth/", phoneToken, nil)
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Expected status code %d, got %v", http.StatusUnauthorized, res.StatusCode)
+	}
+}
+
+func TestExpiredSessionReturnUnauthorized(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u, _ := s.UsersRepo.CreateUser(&models.User{})
+	s.SessionsRepo.CreateSession(&models.Session{
+		UserID:    u.ID,
+		TokenHash: repository.HashToken("AccessToken"),
+		ExpiresAt: time.Now().Add(-time.Minute),
+	})
+
+	res := doRequest(t, http.MethodGet, ts.URL+"/v1/auth/", "AccessToken", nil)
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Expected status code %d, got %v", http.StatusUnauthorized, res.StatusCode)
+	}
+}
+
+func TestRefreshTokenRotatesTokens(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	first := loginTokens(t, ts, "Phone")
+	if first.RefreshToken == "" || first.ExpiresIn != int(server.AccessTokenLifetime.Seconds()) {
+		t.Fatalf("Expected refresh token and access token lifetime, got %v", first)
+	}
+
+	res := refresh(t, ts, first.RefreshToken)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+	var second tokens
+	decodeBody(t, res, &second)
+	if second.AccessToken == first.AccessToken || second.RefreshToken == first.RefreshToken {
+		t.Fatalf("Expected new access and refresh tokens")
+	}
+
+	res = doRequest(t, http.MethodGet, ts.URL+"/v1/auth/", first.AccessToken, nil)
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Expected status code %d for replaced access token, got %v", http.StatusUnauthorized, res.StatusCode)
+	}
+	res = doRequest(t, http.MethodGet, ts.URL+"/v1/auth/", second.AccessToken, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+}
+
+func TestRefreshTokenReuseRevokesSession(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	first := loginTokens(t, ts, "Phone")
+	res := refresh(t, ts, first.RefreshToken)
+	var second tokens
+	decodeBody(t, res, &second)
+
+	res = refresh(t, ts, first.RefreshToken)
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status code %d, got %v", http.StatusBadRequest, res.StatusCode)
+	}
+	res = doRequest(t, http.MethodGet, ts.URL+"/v1/auth/", second.AccessToken, nil)
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Expected status code %d after reuse, got %v", http.StatusUnauthorized, res.StatusCode)
+	}
+	res = refresh(t, ts, second.RefreshToken)
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status code %d after reuse, got %v", http.StatusBadRequest, res.StatusCode)
+	}
+}
+
+func TestRefreshExpiredAccessToken(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	first := loginTokens(t, ts, "Phone")
+	session, _ := s.SessionsRepo.GetSessionByTokenHash(repository.HashToken(first.AccessToken))
+	session.AccessExpiresAt = time.Now().Add(-time.Minute)
+	s.SessionsRepo.UpdateSession(session)
+
+	res := doRequest(t, http.MethodGet, ts.URL+"/v1/auth/", first.AccessToken, nil)
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Expected status code %d, got %v", http.StatusUnauthorized, res.StatusCode)
+	}
+	res = refresh(t, ts, first.RefreshToken)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+}
+
+func TestTokenWithUnsupportedGrantTypeReturnBadRequest(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	res, err := http.PostForm(ts.URL+"/v1/auth/token", url.Values{"grant_type": {"password"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status code %d, got %v", http.StatusBadRequest, res.StatusCode)
+	}
+}
@@ -1,128 +1,412 @@
 package server
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
 	"github.com/JonathanGzzBen/nutrity-api/api/v1/repository"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// Usernames are only validated when they change,
+// those given at registration may not follow the rules of updates.
+const (
+	MinUsernameLength = 3
+	MaxUsernameLength = 50
 )
 
 type UpdateUserDTO struct {
-	Username          string   `json:"username"`
-	Email             string   `json:"email"`
-	FirstName         string   `json:"firstname"`
-	LastName          string   `json:"lastname"`
-	UserProfileEdited bool     `json:"userProfileEdited"`
-	Calories          uint     `json:"calories"`
-	Carbs             uint     `json:"carbs"`
-	Day               uint     `json:"day"`
-	Fats              uint     `json:"fats"`
-	Proteins          uint     `json:"proteins"`
-	RecipesAdded      []string `json:"recipesAdded"`
+	Username          string  `json:"username"` // Between 3 and 50 characters when changed
+	Email             string  `json:"email" binding:"omitempty,email,max=254"`
+	FirstName         string  `json:"firstname" binding:"max=100"`
+	LastName          string  `json:"lastname" binding:"max=100"`
+	UserProfileEdited bool    `json:"userProfileEdited"`
+	ProfileVisibility string  `json:"profileVisibility" binding:"omitempty,oneof=public private"` // Kept if empty
+	HideGoals         *bool   `json:"hideGoals"`                                                  // Kept if null
+	BirthDate         string  `json:"birthDate" binding:"omitempty,datetime=2006-01-02"`
+	Sex               string  `json:"sex" binding:"omitempty,oneof=male female"`
+	Height            float64 `json:"height" binding:"min=0,max=300"` // In centimeters
+	Weight            float64 `json:"weight" binding:"min=0,max=700"` // In kilograms
+	ActivityLevel     string  `json:"activityLevel" binding:"omitempty,oneof=sedentary light moderate active very_active"`
+	WeightGoal        string  `json:"weightGoal" binding:"omitempty,oneof=lose maintain gain"`
+	NutritionGoalsDTO
+	RecipesAdded []uint `json:"recipesAdded"` // IDs of added recipes
+}
+
+type UpdateUserRoleDTO struct {
+	Role string `json:"role" binding:"required,oneof=Administrator Writer Reader"`
+}
+
+type UsersPageDTO struct {
+	Users      []UserDTO `json:"users"`
+	NextCursor string    `json:"nextCursor,omitempty"` // Cursor of next page, empty on last page
+}
+
+type MergeUserDTO struct {
+	AccessToken string `json:"accessToken" binding:"required"` // Access token of the user merged
 }
 
 type UserDTO struct {
-	ID                uint     `json:"id,omitempty"`
-	Username          string   `json:"username"`
-	Email             string   `json:"email"`
-	FirstName         string   `json:"firstname"`
-	LastName          string   `json:"lastname"`
-	UserProfileEdited bool     `json:"userProfileEdited"`
-	Calories          uint     `json:"calories"`
-	Carbs             uint     `json:"carbs"`
-	Day               uint     `json:"day"`
-	Fats              uint     `json:"fats"`
-	Proteins          uint     `json:"proteins"`
-	RecipesAdded      []string `json:"recipesAdded"`
+	ID                uint      `json:"id,omitempty"`
+	Role              string    `json:"role"`
+	Username          string    `json:"username"`
+	Email             string    `json:"email"`
+	EmailVerified     bool      `json:"emailVerified"`
+	FirstName         string    `json:"firstname"`
+	LastName          string    `json:"lastname"`
+	UserProfileEdited bool      `json:"userProfileEdited"`
+	ProfileVisibility string    `json:"profileVisibility"` // public or private
+	HideGoals         bool      `json:"hideGoals"`
+	BirthDate         string    `json:"birthDate"`
+	Sex               string    `json:"sex"`
+	Height            float64   `json:"height"`
+	Weight            float64   `json:"weight"`
+	ActivityLevel     string    `json:"activityLevel"`
+	WeightGoal        string    `json:"weightGoal"`
+	Calories          uint      `json:"calories"`
+	Carbs             uint      `json:"carbs"`
+	Fats              uint      `json:"fats"`
+	Proteins          uint      `json:"proteins"`
+	Water             uint      `json:"water"`        // In milliliters
+	RecipesAdded      []uint    `json:"recipesAdded"` // IDs of added recipes
+	CreatedAt         time.Time `json:"createdAt"`
+	Version           uint      `json:"version"` // Sent quoted in If-Match to update the user only if it's unchanged
+}
+
+// PublicUserDTO is the profile of a user seen by other users,
+// without the fields its privacy settings hide.
+type PublicUserDTO struct {
+	ID           uint   `json:"id"`
+	Username     string `json:"username"`
+	FirstName    string `json:"firstname,omitempty"`
+	LastName     string `json:"lastname,omitempty"`
+	Calories     *uint  `json:"calories,omitempty"`
+	Carbs        *uint  `json:"carbs,omitempty"`
+	Fats         *uint  `json:"fats,omitempty"`
+	Proteins     *uint  `json:"proteins,omitempty"`
+	Water        *uint  `json:"water,omitempty"`        // In milliliters
+	RecipesAdded []uint `json:"recipesAdded,omitempty"` // IDs of added recipes
+}
+
+// publicUserDTOFromUser returns the profile of u seen by other users.
+//
+// Private profiles only show their username,
+// public ones show their name, added recipes and goals unless they are hidden.
+func publicUserDTOFromUser(u *models.User) PublicUserDTO {
+	dto := PublicUserDTO{
+		ID:       u.ID,
+		Username: u.Username,
+	}
+	if u.ProfileVisibility == models.ProfileVisibilityPrivate {
+		return dto
+	}
+	dto.FirstName = u.FirstName
+	dto.LastName = u.LastName
+	dto.RecipesAdded = make([]uint, len(u.RecipesAdded))
+	for i, r := range u.RecipesAdded {
+		dto.RecipesAdded[i] = r.ID
+	}
+	if !u.HideGoals {
+		dto.Calories = &u.Calories
+		dto.Carbs = &u.Carbs
+		dto.Fats = &u.Fats
+		dto.Proteins = &u.Proteins
+		dto.Water = &u.Water
+	}
+	return dto
+}
+
+// canViewPrivate reports whether viewer, nil if anonymous,
+// can see the private fields of u.
+func canViewPrivate(viewer *models.User, u *models.User) bool {
+	return viewer != nil && (viewer.ID == u.ID || viewer.Role == models.RoleAdministrator)
 }
 
 func userDTOFromUser(u *models.User) UserDTO {
+	recipesAdded := make([]uint, len(u.RecipesAdded))
+	for i, r := range u.RecipesAdded {
+		recipesAdded[i] = r.ID
+	}
 	return UserDTO{
 		ID:                u.ID,
+		Role:              u.Role,
 		Username:          u.Username,
 		Email:             u.Email,
+		EmailVerified:     u.EmailVerified,
 		FirstName:         u.FirstName,
 		LastName:          u.LastName,
 		UserProfileEdited: u.UserProfileEdited,
+		ProfileVisibility: u.ProfileVisibility,
+		HideGoals:         u.HideGoals,
+		BirthDate:         u.BirthDate,
+		Sex:               u.Sex,
+		Height:            u.Height,
+		Weight:            u.Weight,
+		ActivityLevel:     u.ActivityLevel,
+		WeightGoal:        u.WeightGoal,
 		Calories:          u.Calories,
 		Carbs:             u.Carbs,
-		Day:               u.Day,
 		Fats:              u.Fats,
 		Proteins:          u.Proteins,
-		RecipesAdded:      strings.Split(u.RecipesAdded, "^"),
+		Water:             u.Water,
+		RecipesAdded:      recipesAdded,
+		CreatedAt:         u.CreatedAt,
+		Version:           u.Version,
+	}
+}
+
+func updateUserDTOFromUser(u *models.User) UpdateUserDTO {
+	recipesAdded := make([]uint, len(u.RecipesAdded))
+	for i, r := range u.RecipesAdded {
+		recipesAdded[i] = r.ID
+	}
+	hideGoals := u.HideGoals
+	return UpdateUserDTO{
+		Username:          u.Username,
+		Email:             u.Email,
+		FirstName:         u.FirstName,
+		LastName:          u.LastName,
+		UserProfileEdited: u.UserProfileEdited,
+		ProfileVisibility: u.ProfileVisibility,
+		HideGoals:         &hideGoals,
+		BirthDate:         u.BirthDate,
+		Sex:               u.Sex,
+		Height:            u.Height,
+		Weight:            u.Weight,
+		ActivityLevel:     u.ActivityLevel,
+		WeightGoal:        u.WeightGoal,
+		NutritionGoalsDTO: NutritionGoalsDTO{
+			Calories: u.Calories,
+			Carbs:    u.Carbs,
+			Fats:     u.Fats,
+			Proteins: u.Proteins,
+			Water:    u.Water,
+		},
+		RecipesAdded: recipesAdded,
 	}
 }
 
 func userFromUserDTO(uDTO *UserDTO) models.User {
-	// Convert array to single string separating elements using character '^'
-	recipesAddedString := ""
-	for _, r := range uDTO.RecipesAdded {
-		recipesAddedString += r + "^"
+	var recipesAdded []models.Recipe
+	for _, id := range uDTO.RecipesAdded {
+		recipesAdded = append(recipesAdded, models.Recipe{ID: id})
 	}
-	recipesAddedString = recipesAddedString[:len(recipesAddedString)-1] // Remove trailing '^'
 
 	return models.User{
 		ID:                uDTO.ID,
+		Role:              uDTO.Role,
 		Username:          uDTO.Username,
 		Email:             uDTO.Email,
+		EmailVerified:     uDTO.EmailVerified,
 		FirstName:         uDTO.FirstName,
 		LastName:          uDTO.LastName,
 		UserProfileEdited: uDTO.UserProfileEdited,
+		ProfileVisibility: uDTO.ProfileVisibility,
+		HideGoals:         uDTO.HideGoals,
+		BirthDate:         uDTO.BirthDate,
+		Sex:               uDTO.Sex,
+		Height:            uDTO.Height,
+		Weight:            uDTO.Weight,
+		ActivityLevel:     uDTO.ActivityLevel,
+		WeightGoal:        uDTO.WeightGoal,
 		Calories:          uDTO.Calories,
 		Carbs:             uDTO.Carbs,
-		Day:               uDTO.Day,
 		Fats:              uDTO.Fats,
 		Proteins:          uDTO.Proteins,
-		RecipesAdded:      recipesAddedString,
+		Water:             uDTO.Water,
+		RecipesAdded:      recipesAdded,
+	}
+}
+
+// usersCursor is the position of the last user of a page,
+// encoded as the opaque cursor of the next page.
+type usersCursor struct {
+	Sort  string `json:"s"` // Sort of the page, cursors only continue pages sorted the same way
+	ID    uint   `json:"id"`
+	Value string `json:"v,omitempty"` // Value of sort key of the user
+}
+
+// sortValue returns the value of sort key of u, as stored in cursors.
+func sortValue(u *models.User, key string) string {
+	switch key {
+	case repository.UsersSortUsername:
+		return u.Username
+	case repository.UsersSortEmail:
+		return u.Email
+	case repository.UsersSortCreatedAt:
+		return u.CreatedAt.Format(time.RFC3339Nano)
+	}
+	return ""
+}
+
+// encodeUsersCursor returns the cursor of the page after u in pages sorted by sort.
+func encodeUsersCursor(sort string, u *models.User) string {
+	data, _ := json.Marshal(usersCursor{
+		Sort:  sort,
+		ID:    u.ID,
+		Value: sortValue(u, strings.TrimPrefix(sort, "-")),
+	})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// usersQuery reads the filters, sort and page of users listings
+// from query parameters, returning the query along with the sort.
+func usersQuery(c *gin.Context) (repository.UsersQuery, string, error) {
+	limit, err := pageLimit(c)
+	if err != nil {
+		return repository.UsersQuery{}, "", err
+	}
+	q := repository.UsersQuery{
+		UsernamePrefix: c.Query("username"),
+		EmailPrefix:    c.Query("email"),
+		Role:           c.Query("role"),
+		Limit:          limit,
+	}
+	if q.Role != "" && q.Role != models.RoleAdministrator && q.Role != models.RoleWriter && q.Role != models.RoleReader {
+		return q, "", errors.New("invalid role query parameter")
 	}
+	if after := c.Query("created_after"); after != "" {
+		if q.CreatedAfter, err = time.Parse(time.RFC3339, after); err != nil {
+			if q.CreatedAfter, err = time.Parse("2006-01-02", after); err != nil {
+				return q, "", errors.New("invalid created_after query parameter, must be a date or RFC 3339 time")
+			}
+		}
+	}
+
+	sort := c.DefaultQuery("sort", repository.UsersSortID)
+	q.SortBy = strings.TrimPrefix(sort, "-")
+	q.Descending = strings.HasPrefix(sort, "-")
+	switch q.SortBy {
+	case repository.UsersSortID, repository.UsersSortUsername, repository.UsersSortEmail, repository.UsersSortCreatedAt:
+	default:
+		return q, "", errors.New("invalid sort query parameter")
+	}
+
+	if cursor := c.Query("cursor"); cursor != "" {
+		var uc usersCursor
+		data, err := base64.RawURLEncoding.DecodeString(cursor)
+		if err == nil {
+			err = json.Unmarshal(data, &uc)
+		}
+		if err != nil || uc.Sort != sort || uc.ID == 0 {
+			return q, "", errors.New("invalid cursor query parameter")
+		}
+		q.AfterID = uc.ID
+		q.AfterValue = uc.Value
+		if q.SortBy == repository.UsersSortCreatedAt {
+			if q.AfterValue, err = time.Parse(time.RFC3339Nano, uc.Value); err != nil {
+				return q, "", errors.New("invalid cursor query parameter")
+			}
+		}
+	}
+	return q, sort, nil
 }
 
 // GetAllUsers is the handler for GET requests to /users
 // 	@ID GetAllUsers
 // 	@Summary Get all users
-// 	@Description Get all registered users.
+// 	@Description Get a page of registered users with their private fields.
+// 	@Description Pages continue from the cursor of the previous one, also linked in the Link header.
+// 	@Description Requires Administrator role.
 // 	@Tags users
-// 	@Success 200 {array} UserDTO
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Param username query string false "Prefix of username, ignoring case"
+// 	@Param email query string false "Prefix of email, ignoring case"
+// 	@Param role query string false "Role" Enums(Administrator, Writer, Reader)
+// 	@Param created_after query string false "Date or RFC 3339 time users were registered after"
+// 	@Param sort query string false "Sort key, prefixed with - for descending order" Enums(id, -id, username, -username, email, -email, created_at, -created_at) default(id)
+// 	@Param limit query int false "Maximum number of users to return" default(20)
+// 	@Param cursor query string false "Cursor of page"
+// 	@Success 200 {object} UsersPageDTO
+// 	@Header 200 {string} Link "URL of next page"
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 401 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
 // 	@Failure 500 {object} models.APIError
 // 	@Router /users [get]
 func (s *Server) GetAllUsers(c *gin.Context) {
-	users, err := s.UsersRepo.GetAllUsers()
-	userDTOs := make([]UserDTO, len(users))
-	for _, u := range users {
-		userDTOs = append(userDTOs, userDTOFromUser(&u))
+	q, sort, err := usersQuery(c)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, err.Error())
+		return
 	}
+	limit := q.Limit
+	// Getting one more user than the page tells if there's a next page
+	q.Limit++
+	users, err := s.UsersRepo.FindUsers(q)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not connect to database"})
+		respondError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, users)
+	page := UsersPageDTO{Users: make([]UserDTO, 0, limit)}
+	if len(users) > limit {
+		users = users[:limit]
+		page.NextCursor = encodeUsersCursor(sort, &users[limit-1])
+		next := *c.Request.URL
+		query := next.Query()
+		query.Set("cursor", page.NextCursor)
+		next.RawQuery = query.Encode()
+		c.Header("Link", "<"+s.hostname+next.RequestURI()+`>; rel="next"`)
+	}
+	for i := range users {
+		page.Users = append(page.Users, userDTOFromUser(&users[i]))
+	}
+	c.JSON(http.StatusOK, page)
 }
 
 // GetUser is the handler for GET requests to /users/:id
 // 	@ID GetUser
 // 	@Summary Get user
 // 	@Description Get user with matching ID.
+// 	@Description The user itself and administrators get every field as UserDTO,
+// 	@Description other users get the fields its privacy settings show as PublicUserDTO.
 // 	@Tags users
+// 	@Security AccessToken
+// 	@Security Bearer
 // 	@Param id path int true "User ID"
+// 	@Param If-None-Match header string false "ETag of user retrieved before, responds 304 if it's still current"
 // 	@Success 200 {object} UserDTO
+// 	@Header 200 {string} ETag "Tag of current version of user"
+// 	@Success 304 "Not modified"
+// 	@Failure 401 {object} models.APIError
 // 	@Failure 404 {object} models.APIError
 // 	@Router /users/{id} [get]
 func (s *Server) GetUser(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid id: " + err.Error()})
+		respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "invalid id: "+err.Error())
 		return
 	}
 	user, err := s.UsersRepo.GetUser(uint(id))
 	if err == repository.ErrNotFound {
-		c.JSON(http.StatusNotFound, models.APIError{Code: http.StatusNotFound, Message: "user with provided id not found"})
+		respondProblem(c, http.StatusNotFound, models.ErrorCodeUserNotFound, "user with provided id not found")
 		return
 	}
 	if err != nil {
-		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: err.Error()})
+		respondError(c, err)
+		return
+	}
+	if !canViewPrivate(optionalUser(c), user) {
+		// Public profiles are tagged by their content, which not every update changes
+		dto := publicUserDTOFromUser(user)
+		if notModified(c, contentETag(dto)) {
+			return
+		}
+		c.JSON(http.StatusOK, dto)
+		return
+	}
+	if notModified(c, versionETag(user.Version)) {
 		return
 	}
 	c.JSON(http.StatusOK, userDTOFromUser(user))
@@ -131,65 +415,338 @@ func (s *Server) GetUser(c *gin.Context) {
 // UpdateUser is the handler for PUT requests to /users/:id
 // 	@ID UpdateUser
 // 	@Summary Update user
-// 	@Description Update matching user with provided data.
+// 	@Description Replace the data of matching user with provided data.
+// 	@Description Changed goals are recorded as goals effective since today.
 // 	@Tags users
 // 	@Security AccessToken
+// 	@Security Bearer
 // 	@Param id path int true "User ID"
+// 	@Param If-Match header string false "ETag of user retrieved, responds 412 if it changed since"
 // 	@Param user body UpdateUserDTO true "User"
 // 	@Success 200 {object} UserDTO
+// 	@Header 200 {string} ETag "Tag of updated version of user"
 // 	@Failure 400 {object} models.APIError
+// 	@Failure 401 {object} models.APIError
+// 	@Failure 409 {object} models.APIError
+// 	@Failure 412 {object} models.APIError
 // 	@Router /users/{id} [put]
 func (s *Server) UpdateUser(c *gin.Context) {
-	at := c.GetHeader(AccessTokenName)
-	au, err := s.userByAccessToken(at)
-	if err != nil {
-		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "not authenticated: " + err.Error()})
+	au, ok := s.authenticatedOwner(c)
+	if !ok {
 		return
 	}
-	id, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "id is not a valid"})
+	if preconditionFailed(c, versionETag(au.Version)) {
 		return
 	}
-	if au.ID != uint(id) {
-		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "id does not match authenticated user"})
+	var uu UpdateUserDTO
+	if err := c.ShouldBindJSON(&uu); err != nil {
+		respondError(c, validationProblem(&uu, "invalid update user", err))
 		return
 	}
+	s.updateUser(c, au, &uu)
+}
+
+// PatchUser is the handler for PATCH requests to /users/:id
+// 	@ID PatchUser
+// 	@Summary Patch user
+// 	@Description Update the fields of matching user present in provided JSON Merge Patch (RFC 7396),
+// 	@Description fields set to null are reset to their default value.
+// 	@Description Changed goals are recorded as goals effective since today.
+// 	@Tags users
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Accept application/merge-patch+json
+// 	@Param id path int true "User ID"
+// 	@Param If-Match header string false "ETag of user retrieved, responds 412 if it changed since"
+// 	@Param user body UpdateUserDTO true "Fields of user to update"
+// 	@Success 200 {object} UserDTO
+// 	@Header 200 {string} ETag "Tag of updated version of user"
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 401 {object} models.APIError
+// 	@Failure 409 {object} models.APIError
+// 	@Failure 412 {object} models.APIError
+// 	@Failure 415 {object} models.APIError
+// 	@Router /users/{id} [patch]
+func (s *Server) PatchUser(c *gin.Context) {
+	au, ok := s.authenticatedOwner(c)
+	if !ok {
+		return
+	}
+	if preconditionFailed(c, versionETag(au.Version)) {
+		return
+	}
+	if ct := c.ContentType(); ct != mergePatchContentType && ct != gin.MIMEJSON {
+		respondProblem(c, http.StatusUnsupportedMediaType, models.ErrorCodeUnsupportedMediaType, "content type must be "+mergePatchContentType)
+		return
+	}
+	var patch map[string]interface{}
+	if err := json.NewDecoder(c.Request.Body).Decode(&patch); err != nil || patch == nil {
+		respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "invalid patch: must be a JSON object")
+		return
+	}
+
+	current := updateUserDTOFromUser(au)
+	var doc map[string]interface{}
+	data, _ := json.Marshal(current)
+	json.Unmarshal(data, &doc)
+	var unknown []models.FieldError
+	for field := range patch {
+		if _, ok := doc[field]; !ok {
+			unknown = append(unknown, models.FieldError{Field: field, Message: "is not a field of users"})
+		}
+	}
+	if unknown != nil {
+		sort.Slice(unknown, func(i, j int) bool { return unknown[i].Field < unknown[j].Field })
+		respondError(c, &problem{status: http.StatusBadRequest, code: models.ErrorCodeValidationFailed, detail: "invalid patch", fields: unknown})
+		return
+	}
+
 	var uu UpdateUserDTO
-	if err := c.ShouldBindJSON(&uu); err != nil {
-		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusNotFound, Message: "invalid update user: " + err.Error()})
+	data, _ = json.Marshal(mergePatch(doc, patch))
+	if err := json.Unmarshal(data, &uu); err != nil {
+		respondError(c, validationProblem(&uu, "invalid patch", err))
+		return
+	}
+	// Unlike PUT, null resets these fields instead of keeping them
+	if uu.ProfileVisibility == "" {
+		uu.ProfileVisibility = models.ProfileVisibilityPublic
+	}
+	if uu.HideGoals == nil {
+		uu.HideGoals = new(bool)
+	}
+	if err := binding.Validator.ValidateStruct(&uu); err != nil {
+		respondError(c, validationProblem(&uu, "invalid patch", err))
 		return
 	}
+	s.updateUser(c, au, &uu)
+}
 
+// usernameProblem returns why username can't be set,
+// or an empty string if it's valid.
+func usernameProblem(username string) string {
+	switch n := utf8.RuneCountInString(username); {
+	case n == 0:
+		return "is required"
+	case n < MinUsernameLength:
+		return "must have at least " + strconv.Itoa(MinUsernameLength) + " characters"
+	case n > MaxUsernameLength:
+		return "must have at most " + strconv.Itoa(MaxUsernameLength) + " characters"
+	}
+	return ""
+}
+
+// updateUser replaces the data of au with valid update uu.
+func (s *Server) updateUser(c *gin.Context, au *models.User, uu *UpdateUserDTO) {
 	// User is updating his own information
-	u, err := s.UsersRepo.GetUser(uint(id))
+	u, err := s.UsersRepo.GetUser(au.ID)
+	if err == repository.ErrNotFound {
+		respondProblem(c, http.StatusNotFound, models.ErrorCodeUserNotFound, "not registered user")
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusNotFound, Message: "not registered user"})
+		respondError(c, err)
 		return
 	}
+	// Updates are based on au, whose version preconditions were checked against
+	if u.Version != au.Version {
+		respondVersionConflict(c, versionETag(u.Version))
+		return
+	}
+
+	uu.Username = strings.TrimSpace(uu.Username)
+	if !strings.EqualFold(uu.Username, u.Username) {
+		if message := usernameProblem(uu.Username); message != "" {
+			respondError(c, fieldProblem(http.StatusBadRequest, models.ErrorCodeValidationFailed, "invalid update user", "username", message))
+			return
+		}
+		other, err := s.UsersRepo.GetUserByUsername(uu.Username)
+		if err == nil && other.ID != u.ID {
+			respondError(c, fieldProblem(http.StatusConflict, models.ErrorCodeUsernameRegistered, "username already registered", "username", "is already registered"))
+			return
+		}
+		if err != nil && err != repository.ErrNotFound {
+			respondError(c, err)
+			return
+		}
+	}
+	// Emails identify local accounts when they log in
+	if !strings.EqualFold(uu.Email, u.Email) && uu.Email != "" {
+		other, err := s.UsersRepo.GetUserByEmail(uu.Email)
+		if err == nil && other.ID != u.ID {
+			respondError(c, fieldProblem(http.StatusConflict, models.ErrorCodeEmailRegistered, "email already registered", "email", "is already registered"))
+			return
+		}
+		if err != nil && err != repository.ErrNotFound {
+			respondError(c, err)
+			return
+		}
+	}
 
-	// Convert array to single string separating elements using character '^'
-	recipesAddedString := ""
-	for _, r := range uu.RecipesAdded {
-		recipesAddedString += r + "^"
+	var recipesAdded []models.Recipe
+	for _, rID := range uu.RecipesAdded {
+		r, err := s.RecipesRepo.GetRecipe(rID)
+		if err == repository.ErrNotFound {
+			message := "recipe with id " + strconv.Itoa(int(rID)) + " not found"
+			respondError(c, fieldProblem(http.StatusBadRequest, models.ErrorCodeValidationFailed, message, "recipesAdded", message))
+			return
+		}
+		if err != nil {
+			respondError(c, fmt.Errorf("could not retrieve recipes: %w", err))
+			return
+		}
+		recipesAdded = append(recipesAdded, *r)
 	}
-	recipesAddedString = recipesAddedString[:len(recipesAddedString)-1] // Remove trailing '^'
 
+	history, inEffect, err := s.goalsHistoryStart(u)
+	if err != nil {
+		respondError(c, fmt.Errorf("could not get goals: %w", err))
+		return
+	}
+
+	if !strings.EqualFold(uu.Email, u.Email) {
+		u.EmailVerified = false
+	}
 	u.Username = uu.Username
 	u.Email = uu.Email
 	u.FirstName = uu.FirstName
 	u.LastName = uu.LastName
 	u.UserProfileEdited = uu.UserProfileEdited
+	if uu.ProfileVisibility != "" {
+		u.ProfileVisibility = uu.ProfileVisibility
+	}
+	if uu.HideGoals != nil {
+		u.HideGoals = *uu.HideGoals
+	}
+	u.BirthDate = uu.BirthDate
+	u.Sex = uu.Sex
+	u.Height = uu.Height
+	u.Weight = uu.Weight
+	u.ActivityLevel = uu.ActivityLevel
+	u.WeightGoal = uu.WeightGoal
 	u.Calories = uu.Calories
 	u.Carbs = uu.Carbs
-	u.Day = uu.Day
 	u.Fats = uu.Fats
 	u.Proteins = uu.Proteins
-	u.RecipesAdded = recipesAddedString
+	u.Water = uu.Water
+	u.RecipesAdded = recipesAdded
+
+	// Changed goals take effect today
+	var goals []models.NutritionGoal
+	if current := goalOfUser(u, today()); !sameGoals(&current, inEffect) {
+		goals = append(history, current)
+	}
+	u, err = s.UsersRepo.UpdateUserGoals(u, goals, "")
+	if err == repository.ErrVersionConflict {
+		respondVersionConflict(c, "")
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Header("ETag", versionETag(u.Version))
+	c.JSON(http.StatusOK, userDTOFromUser(u))
+}
 
+// UpdateUserRole is the handler for PUT requests to /users/:id/role
+// 	@ID UpdateUserRole
+// 	@Summary Update user role
+// 	@Description Change role of matching user to Administrator, Writer or Reader.
+// 	@Description Requires Administrator role, administrators cannot change their own role.
+// 	@Tags users
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Param id path int true "User ID"
+// 	@Param role body UpdateUserRoleDTO true "Role"
+// 	@Success 200 {object} UserDTO
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 401 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 404 {object} models.APIError
+// 	@Router /users/{id}/role [put]
+func (s *Server) UpdateUserRole(c *gin.Context) {
+	au := authenticatedUser(c)
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "invalid id: "+err.Error())
+		return
+	}
+	if au.ID == uint(id) {
+		respondProblem(c, http.StatusForbidden, models.ErrorCodeForbidden, "administrators cannot change their own role")
+		return
+	}
+	var ur UpdateUserRoleDTO
+	if err := c.ShouldBindJSON(&ur); err != nil {
+		respondError(c, validationProblem(&ur, "invalid role", err))
+		return
+	}
+	u, err := s.UsersRepo.GetUser(uint(id))
+	if err == repository.ErrNotFound {
+		respondProblem(c, http.StatusNotFound, models.ErrorCodeUserNotFound, "user with provided id not found")
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	u.Role = ur.Role
 	u, err = s.UsersRepo.UpdateUser(u)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusNotFound, Message: err.Error()})
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, userDTOFromUser(u))
+}
+
+// MergeUser is the handler for POST requests to /users/:id/merge
+// 	@ID MergeUser
+// 	@Summary Merge user
+// 	@Description Merge the user authenticated by provided access token into matching user,
+// 	@Description moving its diary entries, measurements, water entries, goals, recipes and identities.
+// 	@Description Goals of dates both users have keep those of matching user.
+// 	@Description The merged user is deleted along with its sessions.
+// 	@Tags users
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Param id path int true "User ID"
+// 	@Param merge body MergeUserDTO true "Access token of the user merged"
+// 	@Success 200 {object} UserDTO
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 401 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Router /users/{id}/merge [post]
+func (s *Server) MergeUser(c *gin.Context) {
+	au, ok := s.authenticatedOwner(c)
+	if !ok {
+		return
+	}
+	var m MergeUserDTO
+	if err := c.ShouldBindJSON(&m); err != nil {
+		respondError(c, validationProblem(&m, "invalid merge", err))
+		return
+	}
+	// Users prove owning the merged user by logging in as it
+	session, err := s.sessionByAccessToken(m.AccessToken)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidToken, "invalid access token of merged user")
+		return
+	}
+	if session.UserID == au.ID {
+		respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "cannot merge user into itself")
+		return
+	}
+	err = s.UsersRepo.MergeUsers(au.ID, session.UserID)
+	if err == repository.ErrNotFound {
+		respondProblem(c, http.StatusBadRequest, models.ErrorCodeUserNotFound, "merged user not found")
+		return
+	}
+	if err != nil {
+		respondError(c, fmt.Errorf("could not merge users: %w", err))
+		return
+	}
+	u, err := s.UsersRepo.GetUser(au.ID)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, userDTOFromUser(u))
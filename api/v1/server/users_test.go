@@ -6,11 +6,16 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/repository"
 	"github.com/JonathanGzzBen/nutrity-api/api/v1/repository/mocks"
 	"github.com/JonathanGzzBen/nutrity-api/api/v1/server"
+	"github.com/golang/mock/gomock"
 )
 
 var mockUsers = []models.User{
@@ -28,11 +33,19 @@ func TestGetAllUsers(t *testing.T) {
 		s.UsersRepo.CreateUser(&u)
 	}
 
-	mockUsersRepo := &mocks.MockUsersRepository{}
-	mockUsersRepo.EXPECT().GetAllUsers().Return(mockUsers, nil)
+	admin := models.User{ID: 1, Role: models.RoleAdministrator}
+	mockUsersRepo := mocks.NewMockUsersRepository(gomock.NewController(t))
+	authenticateAs(s, "AccessToken", admin.ID)
+	mockUsersRepo.EXPECT().GetUser(admin.ID).Return(&admin, nil)
+	mockUsersRepo.EXPECT().FindUsers(repository.UsersQuery{SortBy: repository.UsersSortID, Limit: server.DefaultPageLimit + 1}).Return(mockUsers, nil)
 	s.UsersRepo = mockUsersRepo
 
-	res, err := http.Get(fmt.Sprintf("%s/v1/users", ts.URL))
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v1/users", ts.URL), nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Add(server.AccessTokenName, "AccessToken")
+	res, err := ts.Client().Do(req)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -48,13 +61,136 @@ func TestGetAllUsers(t *testing.T) {
 		t.Fatalf("Expected \"application/json; charset=utf-8\", got %s", val[0])
 	}
 
-	var resUsers []models.User
-	err = json.NewDecoder(res.Body).Decode(&resUsers)
+	var page server.UsersPageDTO
+	err = json.NewDecoder(res.Body).Decode(&page)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
-	if len(mockUsers) != len(resUsers) {
-		t.Fatalf("Expected %v, got %v", len(mockUsers), len(resUsers))
+	if len(mockUsers) != len(page.Users) {
+		t.Fatalf("Expected %v, got %v", len(mockUsers), len(page.Users))
+	}
+	if page.NextCursor != "" || res.Header.Get("Link") != "" {
+		t.Fatalf("Expected no next page, got cursor %q", page.NextCursor)
+	}
+}
+
+// getUsersPage gets page of users at url as administrator authenticated by at.
+func getUsersPage(t *testing.T, url string, at string) (server.UsersPageDTO, *http.Response) {
+	t.Helper()
+	res := doRequest(t, http.MethodGet, url, at, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+	var page server.UsersPageDTO
+	decodeBody(t, res, &page)
+	return page, res
+}
+
+func TestGetAllUsersPaginatesWithCursors(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	createUser(s, "AdminToken", &models.User{Username: "admin", Email: "admin@example.com", Role: models.RoleAdministrator})
+	for _, name := range []string{"erin", "bob", "dave", "alice", "carol"} {
+		s.UsersRepo.CreateUser(&models.User{Username: name, Email: name + "@example.com", Role: models.RoleReader})
+	}
+
+	for _, tt := range []struct {
+		sort string
+		want []string
+	}{
+		{"username", []string{"admin", "alice", "bob", "carol", "dave", "erin"}},
+		{"-username", []string{"erin", "dave", "carol", "bob", "alice", "admin"}},
+		{"-id", []string{"carol", "alice", "dave", "bob", "erin", "admin"}},
+		{"created_at", []string{"admin", "erin", "bob", "dave", "alice", "carol"}},
+	} {
+		t.Run(tt.sort, func(t *testing.T) {
+			var got []string
+			next := ts.URL + "/v1/users?limit=4&sort=" + tt.sort
+			for next != "" {
+				page, res := getUsersPage(t, next, "AdminToken")
+				for _, u := range page.Users {
+					got = append(got, u.Username)
+				}
+				next = ""
+				if page.NextCursor != "" {
+					link := res.Header.Get("Link")
+					if !strings.HasSuffix(link, `>; rel="next"`) || !strings.Contains(link, "cursor="+page.NextCursor) {
+						t.Fatalf("Expected Link header to next page, got %q", link)
+					}
+					next = ts.URL + "/v1/users?limit=4&sort=" + tt.sort + "&cursor=" + page.NextCursor
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("Expected users %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestGetAllUsersFilters(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	createUser(s, "AdminToken", &models.User{Username: "admin", Email: "admin@example.com", Role: models.RoleAdministrator})
+	s.UsersRepo.CreateUser(&models.User{Username: "Anna_Writer", Email: "anna@writers.com", Role: models.RoleWriter})
+	s.UsersRepo.CreateUser(&models.User{Username: "Annabel", Email: "annabel@example.com", Role: models.RoleReader})
+	s.UsersRepo.CreateUser(&models.User{Username: "Ann%", Email: "ann@example.com", Role: models.RoleReader})
+
+	for _, tt := range []struct {
+		query string
+		want  []string
+	}{
+		{"username=ann&sort=username", []string{"Ann%", "Anna_Writer", "Annabel"}},
+		{"username=ann%25", []string{"Ann%"}},
+		{"username=anna_", []string{"Anna_Writer"}},
+		{"email=ANNA", []string{"anna@writers.com", "annabel@example.com"}},
+		{"role=Writer", []string{"Anna_Writer"}},
+		{"username=ann&role=Reader&sort=-username", []string{"Annabel", "Ann%"}},
+		{"created_after=2000-01-01&role=Administrator", []string{"admin"}},
+		{"created_after=" + url.QueryEscape(time.Now().Add(time.Hour).Format(time.RFC3339)), nil},
+	} {
+		t.Run(tt.query, func(t *testing.T) {
+			page, _ := getUsersPage(t, ts.URL+"/v1/users?"+tt.query, "AdminToken")
+			var got []string
+			for _, u := range page.Users {
+				if strings.HasPrefix(tt.query, "email") {
+					got = append(got, u.Email)
+				} else {
+					got = append(got, u.Username)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("Expected users %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestGetAllUsersRejectsInvalidQueries(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	createUser(s, "AdminToken", &models.User{Username: "admin", Role: models.RoleAdministrator})
+	s.UsersRepo.CreateUser(&models.User{Username: "reader", Role: models.RoleReader})
+	page, _ := getUsersPage(t, ts.URL+"/v1/users?limit=1&sort=username", "AdminToken")
+
+	for _, query := range []string{
+		"sort=password",
+		"role=Owner",
+		"limit=0",
+		"created_after=yesterday",
+		"cursor=not-a-cursor",
+		// Cursors only continue pages sorted the same way
+		"sort=-username&cursor=" + page.NextCursor,
+	} {
+		res := doRequest(t, http.MethodGet, ts.URL+"/v1/users?"+query, "AdminToken", nil)
+		if res.StatusCode != http.StatusBadRequest {
+			t.Fatalf("Expected status code %d for %v, got %v", http.StatusBadRequest, query, res.StatusCode)
+		}
 	}
 }
 
@@ -65,7 +201,7 @@ func TestGetUser(t *testing.T) {
 
 	uToGet := mockUsers[1]
 
-	mockUsersRepo := &mocks.MockUsersRepository{}
+	mockUsersRepo := mocks.NewMockUsersRepository(gomock.NewController(t))
 	mockUsersRepo.EXPECT().GetUser(uToGet.ID).Return(&uToGet, nil)
 	s.UsersRepo = mockUsersRepo
 
@@ -85,12 +221,12 @@ func TestGetUser(t *testing.T) {
 		t.Fatalf("Expected \"application/json; charset=utf-8\", got %s", val[0])
 	}
 
-	var resUser models.User
+	var resUser server.UserDTO
 	err = json.NewDecoder(res.Body).Decode(&resUser)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
-	if uToGet != resUser {
+	if uToGet.ID != resUser.ID || uToGet.Username != resUser.Username {
 		t.Fatalf("Expected %v, got %v", uToGet, resUser)
 	}
 }
@@ -109,8 +245,13 @@ func TestUpdateUserChangeNameAsDifferentUserReturnForbidden(t *testing.T) {
 	uToUpdate := mockUsers[1]
 	uUpdated := uToUpdate
 	uUpdated.Username = "Updated name"
+	authenticatedUser := mockUsers[0]
+	authenticatedUser.ID = 1
 
-	s.UsersRepo = &mocks.MockUsersRepository{}
+	mockUsersRepo := mocks.NewMockUsersRepository(gomock.NewController(t))
+	authenticateAs(s, "AccessToken", authenticatedUser.ID)
+	mockUsersRepo.EXPECT().GetUser(authenticatedUser.ID).Return(&authenticatedUser, nil)
+	s.UsersRepo = mockUsersRepo
 
 	muJSONBytes, err := json.Marshal(uUpdated)
 	if err != nil {
@@ -121,6 +262,7 @@ func TestUpdateUserChangeNameAsDifferentUserReturnForbidden(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
+	req.Header.Add(server.AccessTokenName, "AccessToken")
 	res, err := ts.Client().Do(req)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
@@ -133,8 +275,8 @@ func TestUpdateUserChangeNameAsDifferentUserReturnForbidden(t *testing.T) {
 	if !ok {
 		t.Fatalf("Expected Content-Type header to be set")
 	}
-	if val[0] != "application/json; charset=utf-8" {
-		t.Fatalf("Expected \"application/json; charset=utf-8\", got %s", val[0])
+	if val[0] != server.ProblemContentType {
+		t.Fatalf("Expected %q, got %s", server.ProblemContentType, val[0])
 	}
 }
 
@@ -153,9 +295,12 @@ func TestUpdateUserChangeNameAsSameUserReturnOk(t *testing.T) {
 	uUpdated := uToUpdate
 	uUpdated.Username = "Updated username"
 
-	mockUsersRepo := &mocks.MockUsersRepository{}
+	mockUsersRepo := mocks.NewMockUsersRepository(gomock.NewController(t))
+	authenticateAs(s, "AccessToken", uToUpdate.ID)
+	mockUsersRepo.EXPECT().GetUser(uToUpdate.ID).Return(&uToUpdate, nil)
 	mockUsersRepo.EXPECT().GetUser(uToUpdate.ID).Return(&uToUpdate, nil)
-	mockUsersRepo.EXPECT().UpdateUser(&uUpdated).Return(&uUpdated, nil)
+	mockUsersRepo.EXPECT().GetUserByUsername(uUpdated.Username).Return(nil, repository.ErrNotFound)
+	mockUsersRepo.EXPECT().UpdateUserGoals(&uUpdated, gomock.Any(), "").Return(&uUpdated, nil)
 	s.UsersRepo = mockUsersRepo
 
 	muJSONBytes, err := json.Marshal(uUpdated)
@@ -193,3 +338,369 @@ func TestUpdateUserChangeNameAsSameUserReturnOk(t *testing.T) {
 		t.Fatalf("Expected %v, got %v", uToUpdate.Username, resUser.Username)
 	}
 }
+
+func TestUpdateUserNotRetrievedReturnInternalServerError(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	uToUpdate := mockUsers[1]
+	uToUpdate.ID = 1
+
+	mockUsersRepo := mocks.NewMockUsersRepository(gomock.NewController(t))
+	authenticateAs(s, "AccessToken", uToUpdate.ID)
+	mockUsersRepo.EXPECT().GetUser(uToUpdate.ID).Return(&uToUpdate, nil)
+	mockUsersRepo.EXPECT().GetUser(uToUpdate.ID).Return(nil, repository.ErrCouldNotRetrieve)
+	s.UsersRepo = mockUsersRepo
+
+	res := patchUser(t, ts, uToUpdate.ID, "AccessToken", `{"firstname": "First"}`)
+	decodeProblem(t, res, http.StatusInternalServerError)
+}
+
+func TestMergeUser(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	into := createUser(s, "IntoAccessToken", &models.User{Username: "Into"})
+	from := createUser(s, "FromAccessToken", &models.User{Username: "From"})
+	s.DiaryRepo.CreateDiaryEntry(&models.DiaryEntry{UserID: from.ID, Date: "2022-05-01", Meal: models.MealLunch, Quantity: 100, Unit: models.UnitGrams})
+	s.GoalsRepo.SaveGoal(&models.NutritionGoal{UserID: into.ID, EffectiveDate: "2022-05-01", Calories: 2000})
+	s.GoalsRepo.SaveGoal(&models.NutritionGoal{UserID: from.ID, EffectiveDate: "2022-05-01", Calories: 1500})
+	s.GoalsRepo.SaveGoal(&models.NutritionGoal{UserID: from.ID, EffectiveDate: "2022-04-01", Calories: 1800})
+
+	res := doRequest(t, http.MethodPost, fmt.Sprintf("%s/v1/users/%d/merge", ts.URL, into.ID), "IntoAccessToken",
+		server.MergeUserDTO{AccessToken: "FromAccessToken"})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+
+	entries, _ := s.DiaryRepo.GetDiaryEntries(into.ID, "2022-05-01")
+	if len(entries) != 1 {
+		t.Fatalf("Expected diary entry of merged user, got %v", entries)
+	}
+	goals, _ := s.GoalsRepo.GetGoals(into.ID)
+	if len(goals) != 2 {
+		t.Fatalf("Expected 2 goals, got %v", goals)
+	}
+	goal, _ := s.GoalsRepo.GetGoalAt(into.ID, "2022-05-01")
+	if goal.Calories != 2000 {
+		t.Fatalf("Expected goal of user merged into kept, got %v", goal.Calories)
+	}
+	if _, err := s.UsersRepo.GetUser(from.ID); err == nil {
+		t.Fatalf("Expected merged user deleted")
+	}
+	if merged, _ := s.UsersRepo.GetUser(into.ID); merged.Version != into.Version+1 {
+		t.Fatalf("Expected version of user merged into incremented, got %d", merged.Version)
+	}
+	res = doRequest(t, http.MethodGet, ts.URL+"/v1/auth", "FromAccessToken", nil)
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Expected status code %d, got %v", http.StatusUnauthorized, res.StatusCode)
+	}
+}
+
+func TestMergeUserWithInvalidAccessTokenReturnBadRequest(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	into := createUser(s, "IntoAccessToken", &models.User{Username: "Into"})
+	for _, at := range []string{"UnknownAccessToken", "IntoAccessToken"} {
+		res := doRequest(t, http.MethodPost, fmt.Sprintf("%s/v1/users/%d/merge", ts.URL, into.ID), "IntoAccessToken",
+			server.MergeUserDTO{AccessToken: at})
+		if res.StatusCode != http.StatusBadRequest {
+			t.Fatalf("Expected status code %d for %v, got %v", http.StatusBadRequest, at, res.StatusCode)
+		}
+	}
+}
+
+func TestGetUserShowsPrivateFieldsToOwnerAndAdministrators(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := createUser(s, "UserAccessToken", &models.User{Username: "User", Email: "user@nutrity.test", FirstName: "First", Calories: 2000})
+	createUser(s, "OtherAccessToken", &models.User{Username: "Other"})
+	createUser(s, "AdminAccessToken", &models.User{Username: "Admin", Role: models.RoleAdministrator})
+
+	tests := []struct {
+		at      string
+		private bool
+	}{
+		{"", false},
+		{"OtherAccessToken", false},
+		{"UserAccessToken", true},
+		{"AdminAccessToken", true},
+	}
+	for _, tt := range tests {
+		res := doRequest(t, http.MethodGet, fmt.Sprintf("%s/v1/users/%d", ts.URL, u.ID), tt.at, nil)
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+		}
+		var fields map[string]interface{}
+		decodeBody(t, res, &fields)
+		if _, ok := fields["email"]; ok != tt.private {
+			t.Fatalf("Expected email shown to %q to be %v, got %v", tt.at, tt.private, fields)
+		}
+		if fields["username"] != "User" || fields["firstname"] != "First" || fields["calories"] != float64(2000) {
+			t.Fatalf("Expected public fields, got %v", fields)
+		}
+	}
+}
+
+func TestGetUserHidesFieldsOfPrivacySettings(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := createUser(s, "UserAccessToken", &models.User{Username: "User", FirstName: "First", Calories: 2000})
+	hideGoals := true
+	res := doRequest(t, http.MethodPut, fmt.Sprintf("%s/v1/users/%d", ts.URL, u.ID), "UserAccessToken",
+		server.UpdateUserDTO{Username: "User", FirstName: "First", NutritionGoalsDTO: server.NutritionGoalsDTO{Calories: 2000}, HideGoals: &hideGoals})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+	var fields map[string]interface{}
+	decodeBody(t, doRequest(t, http.MethodGet, fmt.Sprintf("%s/v1/users/%d", ts.URL, u.ID), "", nil), &fields)
+	if _, ok := fields["calories"]; ok || fields["firstname"] != "First" {
+		t.Fatalf("Expected goals hidden, got %v", fields)
+	}
+
+	res = doRequest(t, http.MethodPut, fmt.Sprintf("%s/v1/users/%d", ts.URL, u.ID), "UserAccessToken",
+		server.UpdateUserDTO{Username: "User", FirstName: "First", ProfileVisibility: models.ProfileVisibilityPrivate})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+	fields = nil
+	decodeBody(t, doRequest(t, http.MethodGet, fmt.Sprintf("%s/v1/users/%d", ts.URL, u.ID), "", nil), &fields)
+	if len(fields) != 2 || fields["username"] != "User" {
+		t.Fatalf("Expected only id and username of private profile, got %v", fields)
+	}
+
+	var own server.UserDTO
+	decodeBody(t, doRequest(t, http.MethodGet, fmt.Sprintf("%s/v1/users/%d", ts.URL, u.ID), "UserAccessToken", nil), &own)
+	if own.ProfileVisibility != models.ProfileVisibilityPrivate || !own.HideGoals {
+		t.Fatalf("Expected privacy settings kept, got %+v", own)
+	}
+}
+
+// patchUser sends JSON Merge Patch patch to user with userID, authenticated by at.
+func patchUser(t *testing.T, ts *httptest.Server, userID uint, at string, patch string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("%s/v1/users/%d", ts.URL, userID), strings.NewReader(patch))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set(server.AccessTokenName, at)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return res
+}
+
+func TestPatchUserUpdatesOnlyPresentFields(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := createUser(s, "AccessToken", &models.User{Username: "User", FirstName: "First", LastName: "Last", Calories: 2000, Carbs: 250, HideGoals: true})
+	res := patchUser(t, ts, u.ID, "AccessToken", `{"calories": 2500, "lastname": null, "recipesAdded": []}`)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+	var updated server.UserDTO
+	decodeBody(t, res, &updated)
+	if updated.Calories != 2500 || updated.LastName != "" {
+		t.Fatalf("Expected patched fields updated, got %+v", updated)
+	}
+	if updated.Username != "User" || updated.FirstName != "First" || updated.Carbs != 250 || !updated.HideGoals {
+		t.Fatalf("Expected fields not in patch kept, got %+v", updated)
+	}
+}
+
+func TestPatchUserReturnsFieldErrors(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := createUser(s, "AccessToken", &models.User{Username: "User", Email: "user@example.com"})
+	tests := []struct {
+		patch  string
+		fields []string
+	}{
+		{`{"email": "not-an-email", "calories": 50000}`, []string{"email", "calories"}},
+		{`{"username": "ab"}`, []string{"username"}},
+		{`{"username": null}`, []string{"username"}},
+		{`{"proteins": "plenty"}`, []string{"proteins"}},
+		{`{"sex": "other", "birthDate": "01/02/2000"}`, []string{"birthDate", "sex"}},
+		{`{"nickname": "nick", "username": "Other"}`, []string{"nickname"}},
+		{`{"recipesAdded": [404]}`, []string{"recipesAdded"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.patch, func(t *testing.T) {
+			res := patchUser(t, ts, u.ID, "AccessToken", tt.patch)
+			if res.StatusCode != http.StatusBadRequest {
+				t.Fatalf("Expected status code %d, got %v", http.StatusBadRequest, res.StatusCode)
+			}
+			var apiErr models.APIError
+			decodeBody(t, res, &apiErr)
+			var fields []string
+			for _, fe := range apiErr.Errors {
+				if fe.Message == "" {
+					t.Fatalf("Expected message of field error, got %+v", fe)
+				}
+				fields = append(fields, fe.Field)
+			}
+			if strings.Join(fields, ",") != strings.Join(tt.fields, ",") {
+				t.Fatalf("Expected errors of fields %v, got %+v", tt.fields, apiErr.Errors)
+			}
+		})
+	}
+
+	var own server.UserDTO
+	decodeBody(t, doRequest(t, http.MethodGet, fmt.Sprintf("%s/v1/users/%d", ts.URL, u.ID), "AccessToken", nil), &own)
+	if own.Username != "User" || own.Email != "user@example.com" || own.Calories != 0 {
+		t.Fatalf("Expected user unchanged by invalid patches, got %+v", own)
+	}
+}
+
+func TestPatchUserKeepsUsernameGivenAtRegistration(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	at := register(t, ts, "jo@nutrity.test", "correct horse")
+	var u server.UserDTO
+	decodeBody(t, doRequest(t, http.MethodGet, ts.URL+"/v1/auth/", at, nil), &u)
+	res := patchUser(t, ts, u.ID, at, `{"firstname": "Jo"}`)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+	res = patchUser(t, ts, u.ID, at, `{"username": "Al"}`)
+	decodeProblem(t, res, http.StatusBadRequest)
+}
+
+func TestPatchUserRejectsTakenUsername(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := createUser(s, "AccessToken", &models.User{Username: "User"})
+	s.UsersRepo.CreateUser(&models.User{Username: "Taken"})
+	res := patchUser(t, ts, u.ID, "AccessToken", `{"username": "taken"}`)
+	if res.StatusCode != http.StatusConflict {
+		t.Fatalf("Expected status code %d, got %v", http.StatusConflict, res.StatusCode)
+	}
+	var apiErr models.APIError
+	decodeBody(t, res, &apiErr)
+	if len(apiErr.Errors) != 1 || apiErr.Errors[0].Field != "username" {
+		t.Fatalf("Expected error of username, got %+v", apiErr)
+	}
+
+	// Changing case of own username isn't taken by itself
+	res = patchUser(t, ts, u.ID, "AccessToken", `{"username": "USER"}`)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+}
+
+func TestPatchUserRejectsInvalidPatches(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := createUser(s, "AccessToken", &models.User{Username: "User"})
+	for _, patch := range []string{`[]`, `null`, `"username"`, `{"username": `} {
+		res := patchUser(t, ts, u.ID, "AccessToken", patch)
+		if res.StatusCode != http.StatusBadRequest {
+			t.Fatalf("Expected status code %d for %v, got %v", http.StatusBadRequest, patch, res.StatusCode)
+		}
+	}
+
+	req, _ := http.NewRequest(http.MethodPatch, fmt.Sprintf("%s/v1/users/%d", ts.URL, u.ID), strings.NewReader(`{"calories": 2000}`))
+	req.Header.Set("Content-Type", "text/plain")
+	req.Header.Set(server.AccessTokenName, "AccessToken")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusUnsupportedMediaType {
+		t.Fatalf("Expected status code %d, got %v", http.StatusUnsupportedMediaType, res.StatusCode)
+	}
+}
+
+func TestUserETags(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := createUser(s, "AccessToken", &models.User{Username: "User"})
+	userURL := fmt.Sprintf("%s/v1/users/%d", ts.URL, u.ID)
+	res := doRequest(t, http.MethodGet, userURL, "AccessToken", nil)
+	etag := res.Header.Get("ETag")
+	if res.StatusCode != http.StatusOK || etag == "" {
+		t.Fatalf("Expected user with ETag, got status code %v and ETag %q", res.StatusCode, etag)
+	}
+	res = doRequestWithHeader(t, http.MethodGet, userURL, "AccessToken", "If-None-Match", etag, nil)
+	if res.StatusCode != http.StatusNotModified {
+		t.Fatalf("Expected status code %d, got %v", http.StatusNotModified, res.StatusCode)
+	}
+
+	res = doRequestWithHeader(t, http.MethodPut, userURL, "AccessToken", "If-Match", etag, server.UpdateUserDTO{Username: "Updated"})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+	updatedETag := res.Header.Get("ETag")
+	if updatedETag == "" || updatedETag == etag {
+		t.Fatalf("Expected ETag of updated version, got %q", updatedETag)
+	}
+
+	// Writes based on the previous version would lose the update
+	res = doRequestWithHeader(t, http.MethodPut, userURL, "AccessToken", "If-Match", etag, server.UpdateUserDTO{Username: "Stale"})
+	if res.StatusCode != http.StatusPreconditionFailed {
+		t.Fatalf("Expected status code %d, got %v", http.StatusPreconditionFailed, res.StatusCode)
+	}
+	if res.Header.Get("ETag") != updatedETag {
+		t.Fatalf("Expected ETag of current version %q, got %q", updatedETag, res.Header.Get("ETag"))
+	}
+	req, _ := http.NewRequest(http.MethodPatch, userURL, strings.NewReader(`{"username": "Stale"}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set(server.AccessTokenName, "AccessToken")
+	req.Header.Set("If-Match", etag)
+	if res, _ = http.DefaultClient.Do(req); res.StatusCode != http.StatusPreconditionFailed {
+		t.Fatalf("Expected status code %d, got %v", http.StatusPreconditionFailed, res.StatusCode)
+	}
+	res = doRequestWithHeader(t, http.MethodDelete, userURL, "AccessToken", "If-Match", etag, nil)
+	if res.StatusCode != http.StatusPreconditionFailed {
+		t.Fatalf("Expected status code %d, got %v", http.StatusPreconditionFailed, res.StatusCode)
+	}
+
+	res = doRequestWithHeader(t, http.MethodGet, userURL, "AccessToken", "If-None-Match", etag, nil)
+	var own server.UserDTO
+	decodeBody(t, res, &own)
+	if res.StatusCode != http.StatusOK || own.Username != "Updated" {
+		t.Fatalf("Expected updated user, got status code %v and %+v", res.StatusCode, own)
+	}
+}
+
+func TestUpdateUserOfOutdatedVersionReturnsConflict(t *testing.T) {
+	s := NewTestServer()
+	u, _ := s.UsersRepo.CreateUser(&models.User{Username: "User"})
+	first, _ := s.UsersRepo.GetUser(u.ID)
+	second, _ := s.UsersRepo.GetUser(u.ID)
+
+	first.Calories = 2000
+	if _, err := s.UsersRepo.UpdateUser(first); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	second.Calories = 2500
+	if _, err := s.UsersRepo.UpdateUser(second); err != repository.ErrVersionConflict {
+		t.Fatalf("Expected %v, got %v", repository.ErrVersionConflict, err)
+	}
+	stored, _ := s.UsersRepo.GetUser(u.ID)
+	if stored.Calories != 2000 {
+		t.Fatalf("Expected first update kept, got %v calories", stored.Calories)
+	}
+}
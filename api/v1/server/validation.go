@@ -0,0 +1,102 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"github.com/go-playground/validator/v10"
+)
+
+// jsonFieldName returns the name of field of struct type t in JSON.
+func jsonFieldName(t reflect.Type, field string) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if f, ok := t.FieldByName(field); ok {
+		if name := strings.Split(f.Tag.Get("json"), ",")[0]; name != "" && name != "-" {
+			return name
+		}
+	}
+	return field
+}
+
+// fieldErrorMessage describes the rule fe failed.
+func fieldErrorMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email"
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "datetime":
+		return "must be formatted as " + fe.Param()
+	case "min", "max":
+		bound := "at least "
+		if fe.Tag() == "max" {
+			bound = "at most "
+		}
+		if fe.Kind() == reflect.String {
+			return "must have " + bound + fe.Param() + " characters"
+		}
+		if fe.Kind() == reflect.Slice {
+			return "must have " + bound + fe.Param() + " items"
+		}
+		return "must be " + bound + fe.Param()
+	}
+	return "is invalid"
+}
+
+// fieldErrors returns the errors of the fields of v that err reports,
+// which can be a validation or JSON decoding error, named as in JSON.
+// It returns nil for other errors.
+func fieldErrors(v interface{}, err error) []models.FieldError {
+	var ves validator.ValidationErrors
+	if errors.As(err, &ves) {
+		fes := make([]models.FieldError, 0, len(ves))
+		for _, fe := range ves {
+			fes = append(fes, models.FieldError{
+				Field:   jsonFieldName(reflect.TypeOf(v), fe.StructField()),
+				Message: fieldErrorMessage(fe),
+			})
+		}
+		return fes
+	}
+	var te *json.UnmarshalTypeError
+	if errors.As(err, &te) && te.Field != "" {
+		return []models.FieldError{{Field: te.Field, Message: "must be a " + jsonTypeName(te.Type)}}
+	}
+	return nil
+}
+
+// jsonTypeName returns the name of the JSON type values of t are decoded from.
+func jsonTypeName(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Struct, reflect.Map:
+		return "object"
+	}
+	return "number"
+}
+
+// validationProblem returns the problem of invalid request body v, with detail
+// and the errors of its fields if err reports them, or else err itself.
+func validationProblem(v interface{}, detail string, err error) *problem {
+	fes := fieldErrors(v, err)
+	if fes == nil {
+		detail += ": " + err.Error()
+	}
+	return &problem{status: http.StatusBadRequest, code: models.ErrorCodeValidationFailed, detail: detail, fields: fes}
+}
@@ -0,0 +1,179 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/repository"
+	"github.com/gin-gonic/gin"
+)
+
+type CreateWaterEntryDTO struct {
+	Amount   uint       `json:"amount" binding:"required,gt=0"` // In milliliters
+	LoggedAt *time.Time `json:"loggedAt"`                       // Defaults to now
+}
+
+type WaterEntryDTO struct {
+	ID       uint      `json:"id"`
+	Date     string    `json:"date"`
+	LoggedAt time.Time `json:"loggedAt"`
+	Amount   uint      `json:"amount"` // In milliliters
+}
+
+type WaterDayDTO struct {
+	Date      string          `json:"date"`
+	Entries   []WaterEntryDTO `json:"entries"`
+	Total     uint            `json:"total"`     // In milliliters
+	Goal      uint            `json:"goal"`      // In milliliters
+	Remaining int             `json:"remaining"` // In milliliters, negative if goal was exceeded
+}
+
+func waterEntryDTOFromWaterEntry(e *models.WaterEntry) WaterEntryDTO {
+	return WaterEntryDTO{
+		ID:       e.ID,
+		Date:     e.Date,
+		LoggedAt: e.LoggedAt,
+		Amount:   e.Amount,
+	}
+}
+
+// GetWaterDay is the handler for GET requests to /users/:id/water/:date
+// 	@ID GetWaterDay
+// 	@Summary Get water day
+// 	@Description Get water logged by user in date, along with
+// 	@Description its total compared against user's water goal in effect that date.
+// 	@Tags water
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Param id path int true "User ID"
+// 	@Param date path string true "Date formatted as YYYY-MM-DD"
+// 	@Success 200 {object} WaterDayDTO
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 401 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Router /users/{id}/water/{date} [get]
+func (s *Server) GetWaterDay(c *gin.Context) {
+	u, ok := s.authenticatedOwner(c)
+	if !ok {
+		return
+	}
+	date, ok := diaryDate(c)
+	if !ok {
+		return
+	}
+	entries, err := s.WaterRepo.GetWaterEntries(u.ID, date)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	g, err := s.goalAt(u, date)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	day := WaterDayDTO{
+		Date:    date,
+		Entries: make([]WaterEntryDTO, len(entries)),
+		Goal:    g.Water,
+	}
+	for i := range entries {
+		day.Entries[i] = waterEntryDTOFromWaterEntry(&entries[i])
+		day.Total += entries[i].Amount
+	}
+	day.Remaining = int(day.Goal) - int(day.Total)
+	c.JSON(http.StatusOK, day)
+}
+
+// CreateWaterEntry is the handler for POST requests to /users/:id/water/:date
+// 	@ID CreateWaterEntry
+// 	@Summary Create water entry
+// 	@Description Log water drunk by user in date.
+// 	@Tags water
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Param id path int true "User ID"
+// 	@Param date path string true "Date formatted as YYYY-MM-DD"
+// 	@Param entry body CreateWaterEntryDTO true "Water entry"
+// 	@Success 201 {object} WaterEntryDTO
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 401 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Router /users/{id}/water/{date} [post]
+func (s *Server) CreateWaterEntry(c *gin.Context) {
+	u, ok := s.authenticatedOwner(c)
+	if !ok {
+		return
+	}
+	date, ok := diaryDate(c)
+	if !ok {
+		return
+	}
+	var cw CreateWaterEntryDTO
+	if err := c.ShouldBindJSON(&cw); err != nil {
+		respondError(c, validationProblem(&cw, "invalid water entry", err))
+		return
+	}
+	loggedAt := time.Now()
+	if cw.LoggedAt != nil {
+		loggedAt = *cw.LoggedAt
+	}
+	e, err := s.WaterRepo.CreateWaterEntry(&models.WaterEntry{
+		UserID:   u.ID,
+		Date:     date,
+		LoggedAt: loggedAt,
+		Amount:   cw.Amount,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, waterEntryDTOFromWaterEntry(e))
+}
+
+// DeleteWaterEntry is the handler for DELETE requests to /users/:id/water/:date/:entryId
+// 	@ID DeleteWaterEntry
+// 	@Summary Delete water entry
+// 	@Description Delete matching water entry of user in date.
+// 	@Tags water
+// 	@Security AccessToken
+// 	@Security Bearer
+// 	@Param id path int true "User ID"
+// 	@Param date path string true "Date formatted as YYYY-MM-DD"
+// 	@Param entryId path int true "Water entry ID"
+// 	@Success 204
+// 	@Failure 401 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 404 {object} models.APIError
+// 	@Router /users/{id}/water/{date}/{entryId} [delete]
+func (s *Server) DeleteWaterEntry(c *gin.Context) {
+	u, ok := s.authenticatedOwner(c)
+	if !ok {
+		return
+	}
+	date, ok := diaryDate(c)
+	if !ok {
+		return
+	}
+	entryID, err := strconv.Atoi(c.Param("entryId"))
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "invalid entry id: "+err.Error())
+		return
+	}
+	e, err := s.WaterRepo.GetWaterEntry(uint(entryID))
+	if err == repository.ErrNotFound || (err == nil && (e.UserID != u.ID || e.Date != date)) {
+		respondProblem(c, http.StatusNotFound, models.ErrorCodeWaterEntryNotFound, "water entry with provided id not found")
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if err := s.WaterRepo.DeleteWaterEntry(e.ID); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
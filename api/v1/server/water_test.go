@@ -0,0 +1,72 @@
+package server_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/models"
+	"github.com/JonathanGzzBen/nutrity-api/api/v1/server"
+)
+
+func TestWaterDayComputesTotalAgainstGoal(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := createUser(s, "AccessToken", &models.User{Water: 2000})
+	dayURL := fmt.Sprintf("%s/v1/users/%d/water/2022-05-01", ts.URL, u.ID)
+	for _, amount := range []uint{250, 500} {
+		res := doRequest(t, http.MethodPost, dayURL, "AccessToken", server.CreateWaterEntryDTO{Amount: amount})
+		if res.StatusCode != http.StatusCreated {
+			t.Fatalf("Expected status code %d, got %v", http.StatusCreated, res.StatusCode)
+		}
+	}
+	// Entries of other days are not part of the total
+	doRequest(t, http.MethodPost, fmt.Sprintf("%s/v1/users/%d/water/2022-05-02", ts.URL, u.ID), "AccessToken", server.CreateWaterEntryDTO{Amount: 1000})
+
+	res := doRequest(t, http.MethodGet, dayURL, "AccessToken", nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %v", http.StatusOK, res.StatusCode)
+	}
+	var day server.WaterDayDTO
+	decodeBody(t, res, &day)
+	if len(day.Entries) != 2 || day.Total != 750 || day.Goal != 2000 || day.Remaining != 1250 {
+		t.Fatalf("Expected 2 entries with total 750 of goal 2000 and 1250 remaining, got %v", day)
+	}
+}
+
+func TestCreateWaterEntryWithoutAmountReturnBadRequest(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := createUser(s, "AccessToken", &models.User{})
+
+	res := doRequest(t, http.MethodPost, fmt.Sprintf("%s/v1/users/%d/water/2022-05-01", ts.URL, u.ID), "AccessToken", server.CreateWaterEntryDTO{})
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status code %d, got %v", http.StatusBadRequest, res.StatusCode)
+	}
+}
+
+func TestDeleteWaterEntry(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := createUser(s, "AccessToken", &models.User{})
+	dayURL := fmt.Sprintf("%s/v1/users/%d/water/2022-05-01", ts.URL, u.ID)
+	res := doRequest(t, http.MethodPost, dayURL, "AccessToken", server.CreateWaterEntryDTO{Amount: 250})
+	var created server.WaterEntryDTO
+	decodeBody(t, res, &created)
+
+	res = doRequest(t, http.MethodDelete, fmt.Sprintf("%s/v1/users/%d/water/2022-05-02/%d", ts.URL, u.ID, created.ID), "AccessToken", nil)
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected status code %d, got %v", http.StatusNotFound, res.StatusCode)
+	}
+	res = doRequest(t, http.MethodDelete, fmt.Sprintf("%s/%d", dayURL, created.ID), "AccessToken", nil)
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %v", http.StatusNoContent, res.StatusCode)
+	}
+}
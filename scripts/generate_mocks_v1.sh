@@ -1,3 +1,13 @@
 #!/usr/bin/env bash
 cd ./api/v1
-mockgen -source repository/users.go -destination repository/mocks/UsersRepository.go -package mocks
\ No newline at end of file
+mockgen -source repository/users.go -destination repository/mocks/UsersRepository.go -package mocks
+mockgen -source repository/recipes.go -destination repository/mocks/RecipesRepository.go -package mocks
+mockgen -source repository/foods.go -destination repository/mocks/FoodsRepository.go -package mocks
+mockgen -source repository/diary.go -destination repository/mocks/DiaryRepository.go -package mocks
+mockgen -source repository/goals.go -destination repository/mocks/GoalsRepository.go -package mocks
+mockgen -source repository/measurements.go -destination repository/mocks/MeasurementsRepository.go -package mocks
+mockgen -source repository/water.go -destination repository/mocks/WaterRepository.go -package mocks
+mockgen -source repository/nutrients.go -destination repository/mocks/NutrientsRepository.go -package mocks
+mockgen -source repository/sessions.go -destination repository/mocks/SessionsRepository.go -package mocks
+mockgen -source repository/login_states.go -destination repository/mocks/LoginStatesRepository.go -package mocks
+mockgen -source repository/password_resets.go -destination repository/mocks/PasswordResetsRepository.go -package mocks
\ No newline at end of file